@@ -1,19 +1,20 @@
 package provable
 
 import (
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"io"
+	"context"
 	"net/http"
+	"net/url"
 )
 
 // ProveSingleHash calls the Kayros API to prove a single hash
-// dataType is optional and defaults to "provable_sdk" padded to 32 bytes
-func ProveSingleHash(dataHash string, dataType ...string) (*ProveSingleHashResponse, error) {
-	url := GetKayrosURL(ProveSingleHashRoute)
+// dataType is optional and defaults to the client's data type
+func (c *Client) ProveSingleHash(dataHash string, dataType ...string) (*ProveSingleHashResponse, error) {
+	return c.ProveSingleHashContext(context.Background(), dataHash, dataType...)
+}
 
-	dt := DataType
+// ProveSingleHashContext is like ProveSingleHash but honors ctx cancellation and deadlines
+func (c *Client) ProveSingleHashContext(ctx context.Context, dataHash string, dataType ...string) (*ProveSingleHashResponse, error) {
+	dt := c.dataType
 	if len(dataType) > 0 && dataType[0] != "" {
 		dt = dataType[0]
 		if err := ValidateDataType(dt); err != nil {
@@ -26,49 +27,51 @@ func ProveSingleHash(dataHash string, dataType ...string) (*ProveSingleHashRespo
 		"data_type": dt,
 	}
 
-	jsonData, err := json.Marshal(requestBody)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	resp, err := http.Post(url, "application/json", bytes.NewBuffer(jsonData))
-	if err != nil {
-		return nil, fmt.Errorf("failed to make request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("kayros API error: %d %s - %s", resp.StatusCode, resp.Status, string(body))
-	}
-
 	var result ProveSingleHashResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	if err := c.submit(ctx, ProveSingleHashRoute, requestBody, &result); err != nil {
+		return nil, err
 	}
 
 	return &result, nil
 }
 
 // GetRecordByHash gets a Kayros record by hash
-func GetRecordByHash(recordHash string) (*GetRecordResponse, error) {
-	url := fmt.Sprintf("%s?hash_item=%s", GetKayrosURL(GetRecordByHashRoute), recordHash)
-
-	resp, err := http.Get(url)
-	if err != nil {
-		return nil, fmt.Errorf("failed to make request: %w", err)
-	}
-	defer resp.Body.Close()
+func (c *Client) GetRecordByHash(recordHash string) (*GetRecordResponse, error) {
+	return c.GetRecordByHashContext(context.Background(), recordHash)
+}
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("kayros API error: %d %s - %s", resp.StatusCode, resp.Status, string(body))
-	}
+// GetRecordByHashContext is like GetRecordByHash but honors ctx cancellation and deadlines
+func (c *Client) GetRecordByHashContext(ctx context.Context, recordHash string) (*GetRecordResponse, error) {
+	return c.getRecordByHash(ctx, recordHash, c.retryPolicy)
+}
 
+func (c *Client) getRecordByHash(ctx context.Context, recordHash string, policy RetryPolicy) (*GetRecordResponse, error) {
 	var result GetRecordResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	route := GetRecordByHashRoute + "?hash_item=" + url.QueryEscape(recordHash)
+	if err := c.do(ctx, http.MethodGet, route, nil, &result, true, policy); err != nil {
+		return nil, err
 	}
 
 	return &result, nil
 }
+
+// ProveSingleHash calls the Kayros API to prove a single hash
+// dataType is optional and defaults to "provable_sdk" padded to 32 bytes
+func ProveSingleHash(dataHash string, dataType ...string) (*ProveSingleHashResponse, error) {
+	return DefaultClient.ProveSingleHash(dataHash, dataType...)
+}
+
+// ProveSingleHashContext is like ProveSingleHash but honors ctx cancellation and deadlines
+func ProveSingleHashContext(ctx context.Context, dataHash string, dataType ...string) (*ProveSingleHashResponse, error) {
+	return DefaultClient.ProveSingleHashContext(ctx, dataHash, dataType...)
+}
+
+// GetRecordByHash gets a Kayros record by hash
+func GetRecordByHash(recordHash string) (*GetRecordResponse, error) {
+	return DefaultClient.GetRecordByHash(recordHash)
+}
+
+// GetRecordByHashContext is like GetRecordByHash but honors ctx cancellation and deadlines
+func GetRecordByHashContext(ctx context.Context, recordHash string) (*GetRecordResponse, error) {
+	return DefaultClient.GetRecordByHashContext(ctx, recordHash)
+}
@@ -6,7 +6,7 @@ import (
 )
 
 // Note: These tests validate the function signatures and data type validation.
-// Full integration tests would require mocking HTTP calls.
+// End-to-end tests against a fake Kayros live in the provabletest package.
 
 func TestProveSingleHashValidation(t *testing.T) {
 	t.Run("should accept call without data type (uses default)", func(t *testing.T) {
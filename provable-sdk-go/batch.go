@@ -0,0 +1,426 @@
+package provable
+
+import (
+	"cmp"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+
+	lightnetpb "github.com/provable/provable-sdk-go/proto/lightnet"
+)
+
+// DefaultMaxInFlight is the default number of hashes a BatchProver sends
+// before waiting for acknowledgements
+const DefaultMaxInFlight = 256
+
+// DefaultReconnectPolicy controls how a BatchProver reopens a failed stream
+var DefaultReconnectPolicy = RetryPolicy{
+	MaxAttempts:    5,
+	InitialBackoff: 200 * time.Millisecond,
+	MaxBackoff:     5 * time.Second,
+	Multiplier:     2,
+	Jitter:         0.2,
+}
+
+// BatchResult is the outcome of one hash submitted to a BatchProver
+type BatchResult struct {
+	DataTypeHex string
+	DataItemHex string
+	Response    *SingleHashResponse
+	Err         error
+}
+
+// BatchOption configures a BatchProver
+type BatchOption func(*BatchProver)
+
+// WithMaxInFlight sets how many hashes may be awaiting acknowledgement at once
+func WithMaxInFlight(n int) BatchOption {
+	return func(b *BatchProver) {
+		if n > 0 {
+			b.maxInFlight = n
+		}
+	}
+}
+
+// WithReconnectPolicy sets the backoff used to reopen a failed stream
+// MaxAttempts bounds consecutive failed attempts before the prover gives up
+func WithReconnectPolicy(policy RetryPolicy) BatchOption {
+	return func(b *BatchProver) {
+		b.reconnectPolicy = policy
+	}
+}
+
+// BatchProver pipelines hashes over the SubmitHashStream RPC
+//
+// Hashes are sent as soon as they are submitted, with at most MaxInFlight
+// awaiting acknowledgement. Responses are matched back to their hash by
+// data_type_hex and data_item_hex, so the server may answer in any order. If the stream
+// fails it is reopened and every unacknowledged hash is sent again.
+//
+// Results must be drained: once the results buffer is full the prover stops
+// reading responses, which in turn blocks Submit.
+type BatchProver struct {
+	client          *LightnetClient
+	maxInFlight     int
+	reconnectPolicy RetryPolicy
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	window  chan struct{}
+	results chan BatchResult
+	done    chan struct{}
+
+	// sendMu serializes Send and CloseSend on stream and is taken before mu
+	sendMu sync.Mutex
+	stream lightnetpb.HashService_SubmitHashStreamClient
+
+	mu      sync.Mutex
+	pending map[batchKey][]*batchItem
+	seq     uint64
+	closing bool
+	err     error
+}
+
+// batchKey identifies the responses a hash may be answered by
+type batchKey struct {
+	dataTypeHex string
+	dataItemHex string
+}
+
+type batchItem struct {
+	seq     uint64
+	key     batchKey
+	request *lightnetpb.HashRequest
+}
+
+// NewBatchProver opens a SubmitHashStream and returns a BatchProver using it
+// The stream lives until Close is called or ctx is done.
+func (c *LightnetClient) NewBatchProver(ctx context.Context, opts ...BatchOption) (*BatchProver, error) {
+	b := &BatchProver{
+		client:          c,
+		maxInFlight:     DefaultMaxInFlight,
+		reconnectPolicy: DefaultReconnectPolicy,
+		pending:         make(map[batchKey][]*batchItem),
+		done:            make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	b.window = make(chan struct{}, b.maxInFlight)
+	b.results = make(chan BatchResult, b.maxInFlight)
+	b.ctx, b.cancel = context.WithCancel(ctx)
+
+	stream, err := c.client.SubmitHashStream(b.ctx)
+	if err != nil {
+		b.cancel()
+		return nil, fmt.Errorf("lightnet SubmitHashStream: %w", err)
+	}
+	b.stream = stream
+
+	go b.receive()
+	return b, nil
+}
+
+// ProveStream submits every hash read from in and returns their results
+// The results channel is closed once in is closed and every hash is answered,
+// or when ctx is done.
+func (c *LightnetClient) ProveStream(ctx context.Context, in <-chan string, opts ...BatchOption) (<-chan BatchResult, error) {
+	b, err := c.NewBatchProver(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan BatchResult, b.maxInFlight)
+	var forwarding sync.WaitGroup
+	forwarding.Add(1)
+	go func() {
+		defer forwarding.Done()
+		for result := range b.Results() {
+			out <- result
+		}
+	}()
+
+	go func() {
+		// in may never be closed once ctx is done, so stop reading it then
+	feed:
+		for {
+			select {
+			case <-ctx.Done():
+				break feed
+			case dataItemHex, ok := <-in:
+				if !ok {
+					break feed
+				}
+				if err := b.Submit(ctx, dataItemHex); err != nil {
+					if ctx.Err() != nil {
+						break feed
+					}
+					out <- BatchResult{DataItemHex: dataItemHex, Err: err}
+				}
+			}
+		}
+		b.Close()
+		forwarding.Wait()
+		close(out)
+	}()
+
+	return out, nil
+}
+
+// Results returns the channel results are delivered on
+// It is closed after Close returns or the prover fails.
+func (b *BatchProver) Results() <-chan BatchResult {
+	return b.results
+}
+
+// Submit queues a hash for proving, blocking while MaxInFlight hashes are
+// awaiting acknowledgement
+// dataType is optional and defaults to the client's data type
+func (b *BatchProver) Submit(ctx context.Context, dataItemHex string, dataType ...string) error {
+	req, err := b.client.hashRequest(dataItemHex, dataType)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case b.window <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-b.done:
+		return b.closedErr()
+	}
+
+	// Holding sendMu from the pending insert through Send keeps reconnect
+	// from resending the item before it is first sent, which would send it
+	// twice on the new stream
+	b.sendMu.Lock()
+	b.mu.Lock()
+	if b.closing || b.err != nil {
+		b.mu.Unlock()
+		b.sendMu.Unlock()
+		<-b.window
+		return b.closedErr()
+	}
+	b.seq++
+	key := batchKey{dataTypeHex: hex.EncodeToString(req.DataType), dataItemHex: hex.EncodeToString(req.DataItem)}
+	item := &batchItem{seq: b.seq, key: key, request: req}
+	b.pending[key] = append(b.pending[key], item)
+	b.mu.Unlock()
+	err = b.stream.Send(req)
+	b.sendMu.Unlock()
+
+	// io.EOF means the stream broke; the receive error says why, and the
+	// item is sent again once the stream is reopened. Any other error
+	// refused this request (e.g. for its size), so resending can't help.
+	if err != nil && !errors.Is(err, io.EOF) && b.forget(item) {
+		<-b.window
+		return fmt.Errorf("lightnet SubmitHashStream: %w", err)
+	}
+	return nil
+}
+
+// forget removes item from the pending hashes, reporting whether it was
+// still pending
+func (b *BatchProver) forget(item *batchItem) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	items := b.pending[item.key]
+	i := slices.Index(items, item)
+	if i < 0 {
+		return false
+	}
+	if len(items) == 1 {
+		delete(b.pending, item.key)
+	} else {
+		b.pending[item.key] = slices.Delete(items, i, i+1)
+	}
+	return true
+}
+
+// Close stops accepting hashes, half-closes the stream and waits for every
+// submitted hash to be answered
+func (b *BatchProver) Close() error {
+	b.mu.Lock()
+	alreadyClosing := b.closing
+	b.closing = true
+	b.mu.Unlock()
+
+	if !alreadyClosing {
+		b.closeSend()
+	}
+
+	<-b.done
+	b.cancel()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.err
+}
+
+func (b *BatchProver) closedErr() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.err != nil {
+		return b.err
+	}
+	return ErrBatchProverClosed
+}
+
+// inFlight returns the number of unacknowledged hashes; b.mu must be held
+func (b *BatchProver) inFlight() int {
+	n := 0
+	for _, items := range b.pending {
+		n += len(items)
+	}
+	return n
+}
+
+func (b *BatchProver) closeSend() {
+	b.sendMu.Lock()
+	defer b.sendMu.Unlock()
+	b.stream.CloseSend()
+}
+
+// receive reads responses until the stream ends, reconnecting on failure
+func (b *BatchProver) receive() {
+	defer close(b.done)
+	defer close(b.results)
+
+	failures := 0
+	for {
+		resp, err := b.stream.Recv()
+		if err == nil {
+			failures = 0
+			b.acknowledge(resp)
+			continue
+		}
+
+		b.mu.Lock()
+		finished := b.closing && b.inFlight() == 0
+		b.mu.Unlock()
+		if finished {
+			return
+		}
+		if b.ctx.Err() != nil {
+			b.fail(b.ctx.Err())
+			return
+		}
+
+		failures++
+		if failures >= b.reconnectPolicy.MaxAttempts {
+			b.fail(fmt.Errorf("lightnet SubmitHashStream: %w", err))
+			return
+		}
+		if sleepContext(b.ctx, b.reconnectPolicy.backoff(failures)) != nil {
+			b.fail(b.ctx.Err())
+			return
+		}
+		// On failure the old stream keeps failing, which brings us back here
+		b.reconnect()
+	}
+}
+
+// acknowledge delivers the result for a response and frees its window slot
+func (b *BatchProver) acknowledge(resp *lightnetpb.HashResponse) {
+	b.mu.Lock()
+	key := batchKey{dataTypeHex: strings.ToLower(resp.GetDataTypeHex()), dataItemHex: strings.ToLower(resp.GetDataItemHex())}
+	if key.dataTypeHex == "" {
+		key = b.oldestPending(key.dataItemHex)
+	}
+	items := b.pending[key]
+	if len(items) == 0 {
+		// Not ours, or a duplicate answer for a resubmitted hash
+		b.mu.Unlock()
+		return
+	}
+	if len(items) == 1 {
+		delete(b.pending, key)
+	} else {
+		b.pending[key] = items[1:]
+	}
+	b.mu.Unlock()
+
+	result := BatchResult{DataTypeHex: key.dataTypeHex, DataItemHex: key.dataItemHex}
+	if resp.GetSuccess() {
+		result.Response = singleHashResponseFromProto(resp)
+	} else {
+		result.Err = &LightnetError{Method: "SubmitHashStream", Code: codes.Unknown, Message: resp.GetMessage()}
+	}
+	<-b.window
+	b.results <- result
+}
+
+// oldestPending returns the key of the earliest pending hash for
+// dataItemHex under any data type, for a response that doesn't echo its data
+// type; b.mu must be held
+func (b *BatchProver) oldestPending(dataItemHex string) batchKey {
+	var oldest *batchItem
+	for key, items := range b.pending {
+		if key.dataItemHex == dataItemHex && (oldest == nil || items[0].seq < oldest.seq) {
+			oldest = items[0]
+		}
+	}
+	if oldest == nil {
+		return batchKey{dataItemHex: dataItemHex}
+	}
+	return oldest.key
+}
+
+// reconnect opens a new stream and resends every unacknowledged hash in
+// submission order
+func (b *BatchProver) reconnect() error {
+	b.sendMu.Lock()
+	defer b.sendMu.Unlock()
+
+	stream, err := b.client.client.SubmitHashStream(b.ctx)
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	var items []*batchItem
+	for _, pending := range b.pending {
+		items = append(items, pending...)
+	}
+	closing := b.closing
+	b.mu.Unlock()
+	slices.SortFunc(items, func(x, y *batchItem) int { return cmp.Compare(x.seq, y.seq) })
+
+	b.stream = stream
+	for _, item := range items {
+		if err := stream.Send(item.request); err != nil {
+			return err
+		}
+	}
+	if closing {
+		stream.CloseSend()
+	}
+	return nil
+}
+
+// fail reports err for every unacknowledged hash and marks the prover failed
+func (b *BatchProver) fail(err error) {
+	b.mu.Lock()
+	b.err = err
+	var items []*batchItem
+	for _, pending := range b.pending {
+		items = append(items, pending...)
+	}
+	b.pending = make(map[batchKey][]*batchItem)
+	b.mu.Unlock()
+	slices.SortFunc(items, func(x, y *batchItem) int { return cmp.Compare(x.seq, y.seq) })
+
+	for _, item := range items {
+		<-b.window
+		b.results <- BatchResult{DataTypeHex: item.key.dataTypeHex, DataItemHex: item.key.dataItemHex, Err: err}
+	}
+}
@@ -0,0 +1,414 @@
+package provable
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	lightnetpb "github.com/provable/provable-sdk-go/proto/lightnet"
+)
+
+// streamHashServer answers SubmitHashStream requests in batches of
+// batchSize, in reverse order, so responses arrive out of order. The first
+// dropAfter requests of the first stream are received but the stream is
+// then broken with only the first ackBeforeDrop acknowledged.
+type streamHashServer struct {
+	lightnetpb.UnimplementedHashServiceServer
+	batchSize     int
+	dropAfter     int
+	ackBeforeDrop int
+	rejectItem    string
+
+	streams  atomic.Int32
+	mu       sync.Mutex
+	received map[string]int
+	// reopened counts the requests received on streams after the first
+	reopened map[string]int
+}
+
+func (s *streamHashServer) respond(req *lightnetpb.HashRequest) *lightnetpb.HashResponse {
+	item := hex.EncodeToString(req.DataItem)
+	if item == s.rejectItem {
+		return &lightnetpb.HashResponse{Success: false, Message: "rejected", DataItemHex: item}
+	}
+	return &lightnetpb.HashResponse{
+		Success:         true,
+		ComputedHashHex: Keccak256Str(item),
+		DataTypeHex:     hex.EncodeToString(req.DataType),
+		DataItemHex:     item,
+	}
+}
+
+func (s *streamHashServer) SubmitHashStream(stream grpc.BidiStreamingServer[lightnetpb.HashRequest, lightnetpb.HashResponse]) error {
+	first := s.streams.Add(1) == 1
+	batchSize := max(s.batchSize, 1)
+
+	var batch []*lightnetpb.HashRequest
+	flush := func() error {
+		for i := len(batch) - 1; i >= 0; i-- {
+			if err := stream.Send(s.respond(batch[i])); err != nil {
+				return err
+			}
+		}
+		batch = batch[:0]
+		return nil
+	}
+
+	count := 0
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return flush()
+		}
+		if err != nil {
+			return err
+		}
+
+		s.mu.Lock()
+		s.received[hex.EncodeToString(req.DataItem)]++
+		if !first {
+			s.reopened[hex.EncodeToString(req.DataItem)]++
+		}
+		s.mu.Unlock()
+		count++
+
+		if first && s.dropAfter > 0 {
+			if count <= s.ackBeforeDrop {
+				stream.Send(s.respond(req))
+			}
+			if count == s.dropAfter {
+				return status.Error(codes.Unavailable, "connection reset")
+			}
+			continue
+		}
+
+		batch = append(batch, req)
+		if len(batch) == batchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func newStreamLightnetClient(t *testing.T, srv *streamHashServer) *LightnetClient {
+	t.Helper()
+	srv.received = make(map[string]int)
+	srv.reopened = make(map[string]int)
+	lis := startBufconnServer(t, srv)
+	return dialBufconn(t, lis)
+}
+
+func testHashes(n int) []string {
+	hashes := make([]string, n)
+	for i := range hashes {
+		hashes[i] = Keccak256Str(fmt.Sprintf("item-%d", i))
+	}
+	return hashes
+}
+
+func collectResults(t *testing.T, results <-chan BatchResult) map[string]BatchResult {
+	t.Helper()
+	got := make(map[string]BatchResult)
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case r, ok := <-results:
+			if !ok {
+				return got
+			}
+			got[r.DataItemHex] = r
+		case <-timeout:
+			t.Fatal("timed out waiting for results")
+		}
+	}
+}
+
+func TestBatchProver(t *testing.T) {
+	ctx := context.Background()
+	fastReconnect := WithReconnectPolicy(RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Millisecond, Multiplier: 1})
+
+	t.Run("correlate out-of-order responses", func(t *testing.T) {
+		c := newStreamLightnetClient(t, &streamHashServer{batchSize: 8})
+		b, err := c.NewBatchProver(ctx, WithMaxInFlight(16))
+		if err != nil {
+			t.Fatalf("NewBatchProver() error = %v", err)
+		}
+
+		hashes := testHashes(100)
+		var got map[string]BatchResult
+		collected := make(chan struct{})
+		go func() {
+			got = collectResults(t, b.Results())
+			close(collected)
+		}()
+
+		for _, h := range hashes {
+			if err := b.Submit(ctx, h); err != nil {
+				t.Fatalf("Submit() error = %v", err)
+			}
+		}
+		if err := b.Close(); err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+		<-collected
+
+		if len(got) != len(hashes) {
+			t.Fatalf("got %d results, want %d", len(got), len(hashes))
+		}
+		for _, h := range hashes {
+			r := got[h]
+			if r.Err != nil {
+				t.Errorf("result for %s error = %v", h, r.Err)
+				continue
+			}
+			if r.Response.ComputedHashHex != Keccak256Str(h) {
+				t.Errorf("result for %s has computed hash of another item", h)
+			}
+		}
+	})
+
+	t.Run("bound in-flight hashes", func(t *testing.T) {
+		// Batches of 4 are only answered once full, so a window of 2 must block
+		c := newStreamLightnetClient(t, &streamHashServer{batchSize: 4})
+		b, err := c.NewBatchProver(ctx, WithMaxInFlight(2))
+		if err != nil {
+			t.Fatalf("NewBatchProver() error = %v", err)
+		}
+		defer b.Close()
+
+		hashes := testHashes(3)
+		for _, h := range hashes[:2] {
+			if err := b.Submit(ctx, h); err != nil {
+				t.Fatalf("Submit() error = %v", err)
+			}
+		}
+
+		shortCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		if err := b.Submit(shortCtx, hashes[2]); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Submit() error = %v, want context.DeadlineExceeded while window is full", err)
+		}
+	})
+
+	t.Run("resubmit unacknowledged hashes after stream failure", func(t *testing.T) {
+		srv := &streamHashServer{batchSize: 1, dropAfter: 5, ackBeforeDrop: 2}
+		c := newStreamLightnetClient(t, srv)
+		b, err := c.NewBatchProver(ctx, WithMaxInFlight(5), fastReconnect)
+		if err != nil {
+			t.Fatalf("NewBatchProver() error = %v", err)
+		}
+
+		hashes := testHashes(10)
+		var got map[string]BatchResult
+		collected := make(chan struct{})
+		go func() {
+			got = collectResults(t, b.Results())
+			close(collected)
+		}()
+
+		for _, h := range hashes {
+			if err := b.Submit(ctx, h); err != nil {
+				t.Fatalf("Submit() error = %v", err)
+			}
+		}
+		if err := b.Close(); err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+		<-collected
+
+		if len(got) != len(hashes) {
+			t.Fatalf("got %d results, want %d", len(got), len(hashes))
+		}
+		for _, h := range hashes {
+			if got[h].Err != nil {
+				t.Errorf("result for %s error = %v", h, got[h].Err)
+			}
+		}
+		if srv.streams.Load() < 2 {
+			t.Errorf("streams = %d, want a reconnect", srv.streams.Load())
+		}
+		for _, h := range hashes[:2] {
+			if srv.received[h] != 1 {
+				t.Errorf("acknowledged hash %s received %d times, want 1", h, srv.received[h])
+			}
+		}
+		for _, h := range hashes[2:5] {
+			if srv.received[h] != 2 {
+				t.Errorf("unacknowledged hash %s received %d times, want 2", h, srv.received[h])
+			}
+		}
+	})
+
+	t.Run("send each hash once per stream while reconnecting", func(t *testing.T) {
+		srv := &streamHashServer{batchSize: 1, dropAfter: 50}
+		c := newStreamLightnetClient(t, srv)
+		b, err := c.NewBatchProver(ctx, WithMaxInFlight(512), fastReconnect)
+		if err != nil {
+			t.Fatalf("NewBatchProver() error = %v", err)
+		}
+
+		hashes := testHashes(400)
+		var got map[string]BatchResult
+		collected := make(chan struct{})
+		go func() {
+			got = collectResults(t, b.Results())
+			close(collected)
+		}()
+
+		// Submit races the reconnect that follows the first hash
+		var wg sync.WaitGroup
+		for w := 0; w < 8; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+				for i := w; i < len(hashes); i += 8 {
+					if err := b.Submit(ctx, hashes[i]); err != nil {
+						t.Errorf("Submit() error = %v", err)
+					}
+				}
+			}(w)
+		}
+		wg.Wait()
+		if err := b.Close(); err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+		<-collected
+
+		if len(got) != len(hashes) {
+			t.Fatalf("got %d results, want %d", len(got), len(hashes))
+		}
+		srv.mu.Lock()
+		defer srv.mu.Unlock()
+		for _, h := range hashes {
+			if n := srv.reopened[h]; n > 1 {
+				t.Errorf("hash %s received %d times on the reopened stream, want at most 1", h, n)
+			}
+		}
+	})
+
+	t.Run("report rejected hashes as LightnetError", func(t *testing.T) {
+		hashes := testHashes(3)
+		c := newStreamLightnetClient(t, &streamHashServer{batchSize: 1, rejectItem: hashes[1]})
+
+		in := make(chan string)
+		results, err := c.ProveStream(ctx, in)
+		if err != nil {
+			t.Fatalf("ProveStream() error = %v", err)
+		}
+		go func() {
+			for _, h := range hashes {
+				in <- h
+			}
+			in <- "not-a-hash"
+			close(in)
+		}()
+
+		got := collectResults(t, results)
+		if len(got) != 4 {
+			t.Fatalf("got %d results, want 4", len(got))
+		}
+		var lnErr *LightnetError
+		if !errors.As(got[hashes[1]].Err, &lnErr) {
+			t.Errorf("rejected hash error = %v, want *LightnetError", got[hashes[1]].Err)
+		}
+		if !errors.Is(got["not-a-hash"].Err, ErrInvalidHash) {
+			t.Errorf("invalid hash error = %v, want ErrInvalidHash", got["not-a-hash"].Err)
+		}
+		if got[hashes[0]].Err != nil || got[hashes[2]].Err != nil {
+			t.Error("valid hashes should succeed")
+		}
+	})
+
+	t.Run("close stream results when ctx is cancelled without closing in", func(t *testing.T) {
+		c := newStreamLightnetClient(t, &streamHashServer{batchSize: 1})
+		streamCtx, cancel := context.WithCancel(ctx)
+		in := make(chan string)
+		results, err := c.ProveStream(streamCtx, in)
+		if err != nil {
+			t.Fatalf("ProveStream() error = %v", err)
+		}
+		in <- testHashes(1)[0]
+		cancel()
+		collectResults(t, results)
+	})
+
+	t.Run("key responses by data type and data item", func(t *testing.T) {
+		c := newStreamLightnetClient(t, &streamHashServer{batchSize: 2})
+		b, err := c.NewBatchProver(ctx)
+		if err != nil {
+			t.Fatalf("NewBatchProver() error = %v", err)
+		}
+
+		h := testHashes(1)[0]
+		otherType := strings.Repeat("ab", 32)
+		b.Submit(ctx, h)
+		b.Submit(ctx, h, otherType)
+		if err := b.Close(); err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+
+		got := make(map[string]BatchResult)
+		for r := range b.Results() {
+			got[r.DataTypeHex] = r
+		}
+		for _, dataType := range []string{DataType, otherType} {
+			if r, ok := got[dataType]; !ok || r.Err != nil || r.DataItemHex != h || r.Response.DataTypeHex != dataType {
+				t.Errorf("result under %s = %+v, want a proof of %s", dataType, r, h)
+			}
+		}
+	})
+
+	t.Run("return send errors", func(t *testing.T) {
+		lis := startBufconnServer(t, &streamHashServer{batchSize: 1, received: make(map[string]int), reopened: make(map[string]int)})
+		conn, err := grpc.NewClient("passthrough:///bufnet",
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+			grpc.WithDefaultCallOptions(grpc.MaxCallSendMsgSize(8)),
+		)
+		if err != nil {
+			t.Fatalf("grpc.NewClient() error = %v", err)
+		}
+		defer conn.Close()
+		c, _ := NewLightnetClient(lightnetpb.NewHashServiceClient(conn))
+		b, err := c.NewBatchProver(ctx, fastReconnect)
+		if err != nil {
+			t.Fatalf("NewBatchProver() error = %v", err)
+		}
+
+		if err := b.Submit(ctx, testHashes(1)[0]); status.Code(err) != codes.ResourceExhausted {
+			t.Errorf("Submit() error = %v, want ResourceExhausted", err)
+		}
+		b.Close()
+		for r := range b.Results() {
+			t.Errorf("result = %+v, want none for a hash that was never sent", r)
+		}
+	})
+
+	t.Run("reject submissions after close", func(t *testing.T) {
+		c := newStreamLightnetClient(t, &streamHashServer{batchSize: 1})
+		b, err := c.NewBatchProver(ctx)
+		if err != nil {
+			t.Fatalf("NewBatchProver() error = %v", err)
+		}
+		if err := b.Close(); err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+		if err := b.Submit(ctx, testHashes(1)[0]); !errors.Is(err, ErrBatchProverClosed) {
+			t.Errorf("Submit() error = %v, want ErrBatchProverClosed", err)
+		}
+	})
+}
@@ -0,0 +1,198 @@
+package provable
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"unicode/utf16"
+)
+
+// Canonicalization modes recorded in KayrosMetadata.Canonicalization
+const (
+	// CanonicalizationNone hashes non-string data as produced by
+	// encoding/json.Marshal; it is what envelopes without a canonicalization
+	// field use
+	CanonicalizationNone = ""
+
+	// CanonicalizationJCS hashes non-string data as RFC 8785 JSON
+	// Canonicalization Scheme output, which all SDKs reproduce identically
+	CanonicalizationJCS = "jcs"
+)
+
+// CanonicalizeJSON returns the RFC 8785 (JCS) serialization of v
+//
+// v is first marshaled with encoding/json, so struct tags and Marshaler
+// implementations apply. Object keys are then sorted by UTF-16 code units,
+// numbers are written as ECMAScript would and strings use the minimal JSON
+// escaping.
+func CanonicalizeJSON(v interface{}) ([]byte, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := writeCanonical(&buf, value); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// normalizeEnvelopeData returns data as it reads back from an archived
+// envelope: marshaled with encoding/json and decoded into generic JSON values
+// (string, bool, json.Number, []interface{}, map[string]interface{} or nil)
+// Hashing the normalized value gives the same hash before and after a JSON
+// round trip, e.g. for []byte, named string types and json.RawMessage.
+// Only CanonicalizationJCS normalizes; CanonicalizationNone hashes
+// json.Marshal output, which keeps struct fields in declaration order.
+func normalizeEnvelopeData(data interface{}) (interface{}, error) {
+	if str, ok := data.(string); ok {
+		return str, nil
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// envelopeDataString returns the string that is hashed for envelope data:
+// strings are hashed as-is and anything else is serialized as JSON according
+// to the canonicalization mode, with JCS data normalized first
+func envelopeDataString(data interface{}, canonicalization string) (string, error) {
+	switch canonicalization {
+	case CanonicalizationNone:
+		if str, ok := data.(string); ok {
+			return str, nil
+		}
+		b, err := json.Marshal(data)
+		return string(b), err
+	case CanonicalizationJCS:
+		data, err := normalizeEnvelopeData(data)
+		if err != nil {
+			return "", err
+		}
+		if str, ok := data.(string); ok {
+			return str, nil
+		}
+		b, err := CanonicalizeJSON(data)
+		return string(b), err
+	}
+	return "", fmt.Errorf("%w: %q", ErrUnsupportedCanonicalization, canonicalization)
+}
+
+func writeCanonical(buf *bytes.Buffer, value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		buf.WriteString(strconv.FormatBool(v))
+	case json.Number:
+		f, err := strconv.ParseFloat(string(v), 64)
+		if err != nil {
+			return fmt.Errorf("number %s is not representable as an IEEE 754 double: %w", v, err)
+		}
+		writeCanonicalNumber(buf, f)
+	case string:
+		writeCanonicalString(buf, v)
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, elem := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeCanonical(buf, elem); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		slices.SortFunc(keys, compareUTF16)
+
+		buf.WriteByte('{')
+		for i, k := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeCanonicalString(buf, k)
+			buf.WriteByte(':')
+			if err := writeCanonical(buf, v[k]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	default:
+		return fmt.Errorf("unexpected JSON value of type %T", value)
+	}
+	return nil
+}
+
+// writeCanonicalNumber writes f as ECMAScript's Number.prototype.toString
+// would; encoding/json already formats float64 that way, except for -0
+func writeCanonicalNumber(buf *bytes.Buffer, f float64) {
+	if f == 0 {
+		buf.WriteByte('0')
+		return
+	}
+	b, _ := json.Marshal(f)
+	buf.Write(b)
+}
+
+// writeCanonicalString writes s as a JSON string, escaping only '"', '\\'
+// and control characters
+func writeCanonicalString(buf *bytes.Buffer, s string) {
+	const hexDigits = "0123456789abcdef"
+
+	buf.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				buf.WriteString(`\u00`)
+				buf.WriteByte(hexDigits[r>>4])
+				buf.WriteByte(hexDigits[r&0xf])
+			} else {
+				buf.WriteRune(r)
+			}
+		}
+	}
+	buf.WriteByte('"')
+}
+
+// compareUTF16 orders strings by their UTF-16 code units, as RFC 8785 requires
+func compareUTF16(a, b string) int {
+	return slices.Compare(utf16.Encode([]rune(a)), utf16.Encode([]rune(b)))
+}
@@ -0,0 +1,152 @@
+package provable
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+)
+
+// The canonicalTest vector is shared with the JS and Python SDK tests, which must
+// produce the same canonical string and hash
+const (
+	canonicalTestInput     = `{"b":[1,2.5,"x",1e21,-0],"a":{"\u00e9":true,"z":null,"10":"<&>"}}`
+	canonicalTestOutput    = `{"a":{"10":"<&>","z":null,"é":true},"b":[1,2.5,"x",1e+21,0]}`
+	canonicalTestOutputHex = "8df12ae4acff018b3cbf25e2a5bccce7394cc41739c4108486971bccb8dc68b3"
+)
+
+func canonicalizeString(t *testing.T, input string) string {
+	t.Helper()
+	var v interface{}
+	if err := json.Unmarshal([]byte(input), &v); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	out, err := CanonicalizeJSON(v)
+	if err != nil {
+		t.Fatalf("CanonicalizeJSON() error = %v", err)
+	}
+	return string(out)
+}
+
+func TestCanonicalizeJSON(t *testing.T) {
+	t.Run("match RFC 8785 example", func(t *testing.T) {
+		input := `{"numbers":[333333333.33333329,1E30,4.50,2e-3,0.000000000000000000000000001],"string":"\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/","literals":[null,true,false]}`
+		want := `{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"€$\u000f\nA'B\"\\\\\"/"}`
+		if got := canonicalizeString(t, input); got != want {
+			t.Errorf("CanonicalizeJSON() = %s, want %s", got, want)
+		}
+	})
+
+	t.Run("sort keys by UTF-16 code units", func(t *testing.T) {
+		input := `{"\u20ac":0,"\r":0,"\ufb33":0,"1":0,"\ud83d\ude00":0,"\u0080":0,"\u00f6":0}`
+		want := "{\"\\r\":0,\"1\":0,\"\u0080\":0,\"ö\":0,\"€\":0,\"😀\":0,\"\ufb33\":0}"
+		if got := canonicalizeString(t, input); got != want {
+			t.Errorf("CanonicalizeJSON() = %s, want %s", got, want)
+		}
+	})
+
+	t.Run("format numbers as ECMAScript", func(t *testing.T) {
+		tests := []struct {
+			in   float64
+			want string
+		}{
+			{0, "0"},
+			{math.Copysign(0, -1), "0"},
+			{1, "1"},
+			{-1.5, "-1.5"},
+			{1e20, "100000000000000000000"},
+			{1e21, "1e+21"},
+			{1e-6, "0.000001"},
+			{1e-7, "1e-7"},
+			{5e-324, "5e-324"},
+			{9007199254740993, "9007199254740992"},
+		}
+		for _, tt := range tests {
+			out, err := CanonicalizeJSON(tt.in)
+			if err != nil {
+				t.Fatalf("CanonicalizeJSON(%v) error = %v", tt.in, err)
+			}
+			if string(out) != tt.want {
+				t.Errorf("CanonicalizeJSON(%v) = %s, want %s", tt.in, out, tt.want)
+			}
+		}
+	})
+
+	t.Run("ignore struct field order and HTML escaping", func(t *testing.T) {
+		type record struct {
+			Name  string `json:"name"`
+			Count int    `json:"count"`
+			Note  string `json:"note"`
+		}
+		out, err := CanonicalizeJSON(record{Name: "a", Count: 2, Note: "<b>"})
+		if err != nil {
+			t.Fatalf("CanonicalizeJSON() error = %v", err)
+		}
+		want := `{"count":2,"name":"a","note":"<b>"}`
+		if string(out) != want {
+			t.Errorf("CanonicalizeJSON() = %s, want %s", out, want)
+		}
+	})
+
+	t.Run("match cross-SDK vector", func(t *testing.T) {
+		got := canonicalizeString(t, canonicalTestInput)
+		if got != canonicalTestOutput {
+			t.Errorf("CanonicalizeJSON() = %s, want %s", got, canonicalTestOutput)
+		}
+		if hash := Keccak256Str(got); hash != canonicalTestOutputHex {
+			t.Errorf("Keccak256Str() = %s, want %s", hash, canonicalTestOutputHex)
+		}
+	})
+
+	t.Run("reject unsupported values", func(t *testing.T) {
+		if _, err := CanonicalizeJSON(math.Inf(1)); err == nil {
+			t.Error("CanonicalizeJSON(+Inf) error = nil, want error")
+		}
+	})
+}
+
+func TestVerifyCanonicalization(t *testing.T) {
+	data := map[string]interface{}{"b": 1, "a": "<x>"}
+
+	t.Run("verify JCS envelope", func(t *testing.T) {
+		envelope := &KayrosEnvelope{
+			Data: data,
+			Kayros: KayrosMetadata{
+				Hash:             Keccak256Str(`{"a":"<x>","b":1}`),
+				Canonicalization: CanonicalizationJCS,
+			},
+		}
+		if result := Verify(envelope); !result.Valid {
+			t.Errorf("Verify() = %+v, want valid", result)
+		}
+	})
+
+	t.Run("keep encoding/json for envelopes without canonicalization", func(t *testing.T) {
+		legacy, _ := json.Marshal(data)
+		envelope := &KayrosEnvelope{Data: data, Kayros: KayrosMetadata{Hash: Keccak256(legacy)}}
+		if result := Verify(envelope); !result.Valid {
+			t.Errorf("Verify() = %+v, want valid", result)
+		}
+	})
+
+	t.Run("hash strings as-is", func(t *testing.T) {
+		envelope := &KayrosEnvelope{
+			Data:   "hello",
+			Kayros: KayrosMetadata{Hash: Keccak256Str("hello"), Canonicalization: CanonicalizationJCS},
+		}
+		if result := Verify(envelope); !result.Valid {
+			t.Errorf("Verify() = %+v, want valid", result)
+		}
+	})
+
+	t.Run("reject unknown canonicalization", func(t *testing.T) {
+		envelope := &KayrosEnvelope{
+			Data:   data,
+			Kayros: KayrosMetadata{Hash: Keccak256Str("x"), Canonicalization: "c14n"},
+		}
+		result := Verify(envelope)
+		if result.Valid || !errors.Is(result.Err, ErrUnsupportedCanonicalization) {
+			t.Errorf("Verify() = %+v, want ErrUnsupportedCanonicalization", result)
+		}
+	})
+}
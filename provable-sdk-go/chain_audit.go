@@ -0,0 +1,313 @@
+package provable
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// AuditFindingKind identifies what a ChainAuditor found wrong with a record
+type AuditFindingKind string
+
+const (
+	// AuditGap means a record's prev hash matches no audited record, so
+	// records are missing between it and the one before
+	AuditGap AuditFindingKind = "gap"
+
+	// AuditReorder means a record's prev hash matches an audited record other
+	// than the one before it
+	AuditReorder AuditFindingKind = "reorder"
+
+	// AuditTimestampRegression means a record's timestamp is earlier than the
+	// one of the record before it
+	// Only AuditRecords and AuditUUIDs report it: AuditRange lists records in
+	// timestamp order, so their timestamps can't go backwards.
+	AuditTimestampRegression AuditFindingKind = "timestamp_regression"
+
+	// AuditDuplicate means a record's hash was already seen in the walk
+	AuditDuplicate AuditFindingKind = "duplicate"
+
+	// AuditHashMismatch means a record's hash doesn't match the one
+	// recomputed from its fields (see WithAuditRecomputeHashes)
+	AuditHashMismatch AuditFindingKind = "hash_mismatch"
+)
+
+// AuditFinding describes one problem found in a chain
+type AuditFinding struct {
+	Kind        AuditFindingKind `json:"kind"`
+	Index       int              `json:"index"` // position of the record, oldest first
+	UUIDHex     string           `json:"uuid_hex,omitempty"`
+	HashItemHex string           `json:"hash_item_hex,omitempty"`
+	Expected    string           `json:"expected,omitempty"`
+	Actual      string           `json:"actual,omitempty"`
+	Message     string           `json:"message"`
+}
+
+// breaksChain reports whether the finding means the chain isn't continuous
+func (f AuditFinding) breaksChain() bool {
+	return f.Kind == AuditGap || f.Kind == AuditReorder || f.Kind == AuditHashMismatch
+}
+
+// AuditReport is the result of a chain audit
+type AuditReport struct {
+	Valid          bool   `json:"valid"`
+	Records        int    `json:"records"`
+	LinksChecked   int    `json:"links_checked"`
+	HashesChecked  int    `json:"hashes_checked"`
+	FirstTimestamp string `json:"first_timestamp,omitempty"`
+	LastTimestamp  string `json:"last_timestamp,omitempty"`
+	// FirstBreak is the first gap, reorder or hash mismatch, if any
+	FirstBreak *AuditFinding  `json:"first_break,omitempty"`
+	Findings   []AuditFinding `json:"findings"`
+}
+
+// ChainAuditor checks that Kayros records form an unbroken hash chain, where
+// each record's PrevHashHex is the HashItemHex of the record before it
+type ChainAuditor struct {
+	client    *Client
+	anchor    string
+	recompute bool
+}
+
+// AuditOption configures a ChainAuditor
+type AuditOption func(*ChainAuditor)
+
+// WithAuditAnchor sets the prev hash expected on the first audited record,
+// e.g. the last hash of a previous audit
+// Without it the first record's link isn't checked.
+func WithAuditAnchor(prevHashHex string) AuditOption {
+	return func(a *ChainAuditor) {
+		a.anchor = prevHashHex
+	}
+}
+
+// WithAuditRecomputeHashes also recomputes each record's hash locally with
+// VerifyRecordLocal, so a record can't be altered without breaking the chain
+// Prev hash links are checked with or without it. It is opt-in until the
+// record hash layout is confirmed by TestRecordHashFixtures.
+func WithAuditRecomputeHashes() AuditOption {
+	return func(a *ChainAuditor) {
+		a.recompute = true
+	}
+}
+
+// NewChainAuditor creates a ChainAuditor fetching records through c
+func (c *Client) NewChainAuditor(opts ...AuditOption) *ChainAuditor {
+	a := &ChainAuditor{client: c}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// NewChainAuditor creates a ChainAuditor using DefaultClient
+func NewChainAuditor(opts ...AuditOption) *ChainAuditor {
+	return DefaultClient.NewChainAuditor(opts...)
+}
+
+// AuditUUIDs fetches the records with GetRecordWithPrevHash, in chain order
+// (oldest first), and audits them
+func (a *ChainAuditor) AuditUUIDs(ctx context.Context, uuids []string) (*AuditReport, error) {
+	records := make([]DatabaseRecord, 0, len(uuids))
+	for _, uuid := range uuids {
+		record, err := a.client.GetRecordWithPrevHashContext(ctx, uuid)
+		if err != nil {
+			return nil, fmt.Errorf("fetch record %s: %w", uuid, err)
+		}
+		if record.UUIDHex == "" {
+			record.UUIDHex = uuid
+		}
+		records = append(records, *record)
+	}
+	return a.AuditRecords(records), nil
+}
+
+// AuditRange walks every record matching query with QueryHashes, paging by
+// timestamp cursor, and audits them in chain order (oldest first)
+// QueryHashes doesn't return prev hashes or UUIDs, so each record is looked
+// up with GetRecordByHash and GetRecordWithPrevHash to check its link; that's
+// two more requests per record.
+// Records are walked in timestamp order, so AuditRange doesn't report
+// timestamp regressions. Records whose prev hash links them out of that order
+// are reported as reordered.
+func (a *ChainAuditor) AuditRange(ctx context.Context, query DatabaseQuery) (*AuditReport, error) {
+	if query.OrderBy == "" {
+		query.OrderBy = OrderTimestampAsc
+	}
+
+	var records []DatabaseRecord
+	for listed, err := range a.client.AllHashes(ctx, query, WithTimestampCursor()) {
+		if err != nil {
+			return nil, err
+		}
+		record, err := a.fetchLinked(ctx, listed)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, *record)
+	}
+	if query.OrderBy == OrderTimestampDesc {
+		slices.Reverse(records)
+	}
+	return a.audit(records, false), nil
+}
+
+// fetchLinked fetches the full record, with its prev hash, for a record
+// listed by QueryHashes
+func (a *ChainAuditor) fetchLinked(ctx context.Context, listed HashRecord) (*DatabaseRecord, error) {
+	hash := hashItemHex(listed.HashItem)
+	byHash, err := a.client.GetRecordByHashContext(ctx, hash)
+	if err != nil {
+		return nil, fmt.Errorf("fetch record %s: %w", hash, err)
+	}
+	uuid := byHash.Data.UUIDHex
+	if uuid == "" {
+		return nil, fmt.Errorf("fetch record %s: Kayros returned no uuid", hash)
+	}
+	record, err := a.client.GetRecordWithPrevHashContext(ctx, uuid)
+	if err != nil {
+		return nil, fmt.Errorf("fetch record %s: %w", uuid, err)
+	}
+	record.UUIDHex = firstNonEmpty(record.UUIDHex, uuid)
+	record.HashItemHex = firstNonEmpty(record.HashItemHex, hash)
+	record.Timestamp = firstNonEmpty(record.Timestamp, listed.Timestamp)
+	return record, nil
+}
+
+// hashItemHex returns a QueryHashes hash item as hex, decoding it if it's a
+// base64 32-byte hash
+func hashItemHex(item string) string {
+	if len(item) == 64 {
+		return strings.ToLower(item)
+	}
+	if b, err := base64.StdEncoding.DecodeString(item); err == nil && len(b) == 32 {
+		return hex.EncodeToString(b)
+	}
+	return item
+}
+
+// AuditRecords audits records already in hand, in chain order (oldest first)
+func (a *ChainAuditor) AuditRecords(records []DatabaseRecord) *AuditReport {
+	return a.audit(records, true)
+}
+
+// audit checks records in chain order; checkTimestamps is false when the
+// records were listed in timestamp order, so their timestamps can't regress
+func (a *ChainAuditor) audit(records []DatabaseRecord, checkTimestamps bool) *AuditReport {
+	report := &AuditReport{Records: len(records), Findings: []AuditFinding{}}
+	if len(records) == 0 {
+		report.Valid = true
+		return report
+	}
+	report.FirstTimestamp = records[0].Timestamp
+	report.LastTimestamp = records[len(records)-1].Timestamp
+
+	index := make(map[string]int, len(records))
+	for i, record := range records {
+		hash := strings.ToLower(record.HashItemHex)
+		if _, ok := index[hash]; !ok {
+			index[hash] = i
+		}
+	}
+
+	add := func(f AuditFinding) {
+		record := records[f.Index]
+		f.UUIDHex = record.UUIDHex
+		f.HashItemHex = record.HashItemHex
+		report.Findings = append(report.Findings, f)
+	}
+
+	seen := make(map[string]int, len(records))
+	for i, record := range records {
+		hash := strings.ToLower(record.HashItemHex)
+		if j, ok := seen[hash]; ok {
+			add(AuditFinding{Kind: AuditDuplicate, Index: i, Message: fmt.Sprintf("hash already seen at record %d", j)})
+		} else {
+			seen[hash] = i
+		}
+
+		if checkTimestamps && i > 0 {
+			prev := records[i-1].Timestamp
+			if timestampBefore(record.Timestamp, prev) {
+				add(AuditFinding{
+					Kind:     AuditTimestampRegression,
+					Index:    i,
+					Expected: prev,
+					Actual:   record.Timestamp,
+					Message:  "timestamp goes backwards from the previous record",
+				})
+			}
+		}
+
+		if a.recompute {
+			report.HashesChecked++
+			valid, result, err := VerifyRecordLocal(record)
+			switch {
+			case err != nil:
+				add(AuditFinding{Kind: AuditHashMismatch, Index: i, Message: fmt.Sprintf("can't recompute hash: %v", err)})
+			case !valid:
+				add(AuditFinding{
+					Kind:     AuditHashMismatch,
+					Index:    i,
+					Expected: result.ComputedHash,
+					Actual:   record.HashItemHex,
+					Message:  "hash doesn't match the recomputed hash",
+				})
+			}
+		}
+
+		var expected string
+		switch {
+		case i > 0:
+			expected = records[i-1].HashItemHex
+		case a.anchor != "":
+			expected = a.anchor
+		default:
+			continue
+		}
+		report.LinksChecked++
+		if strings.EqualFold(record.PrevHashHex, expected) {
+			continue
+		}
+		if j, ok := index[strings.ToLower(record.PrevHashHex)]; ok && j != i {
+			add(AuditFinding{
+				Kind:     AuditReorder,
+				Index:    i,
+				Expected: expected,
+				Actual:   record.PrevHashHex,
+				Message:  fmt.Sprintf("prev hash links to record %d instead of the previous record", j),
+			})
+			continue
+		}
+		add(AuditFinding{
+			Kind:     AuditGap,
+			Index:    i,
+			Expected: expected,
+			Actual:   record.PrevHashHex,
+			Message:  "prev hash matches no audited record",
+		})
+	}
+
+	for i := range report.Findings {
+		if report.Findings[i].breaksChain() {
+			report.FirstBreak = &report.Findings[i]
+			break
+		}
+	}
+	report.Valid = len(report.Findings) == 0
+	return report
+}
+
+// timestampBefore reports whether a is earlier than b, comparing timestamps
+// parseRecordTimestamp accepts as times and anything else as strings
+func timestampBefore(a, b string) bool {
+	ta, errA := parseRecordTimestamp(a)
+	tb, errB := parseRecordTimestamp(b)
+	if errA != nil || errB != nil {
+		return a < b
+	}
+	return ta.Before(tb)
+}
@@ -0,0 +1,305 @@
+package provable
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// testChain builds n linked records, one second apart, with hashes computed
+// from their fields
+func testChain(t *testing.T, n int) []DatabaseRecord {
+	t.Helper()
+	records := make([]DatabaseRecord, n)
+	prev := ""
+	for i := range records {
+		records[i] = DatabaseRecord{
+			UUIDHex:     fmt.Sprintf("%032x", i),
+			DataType:    strings.Repeat("aa", 32),
+			DataItemHex: Keccak256Str(fmt.Sprintf("record %d", i)),
+			PrevHashHex: prev,
+			HashType:    RecordHashBLAKE3,
+			Timestamp:   fmt.Sprintf("2025-01-01T00:00:%02dZ", i),
+		}
+		result, err := VerifyHashLocal(RecordHashRequest(records[i]))
+		if err != nil {
+			t.Fatalf("VerifyHashLocal() error = %v", err)
+		}
+		records[i].HashItemHex = result.ComputedHash
+		prev = result.ComputedHash
+	}
+	return records
+}
+
+func findingKinds(report *AuditReport) []AuditFindingKind {
+	kinds := make([]AuditFindingKind, len(report.Findings))
+	for i, f := range report.Findings {
+		kinds[i] = f.Kind
+	}
+	return kinds
+}
+
+func TestChainAuditorAuditRecords(t *testing.T) {
+	a := (&Client{}).NewChainAuditor()
+
+	t.Run("accept an unbroken chain", func(t *testing.T) {
+		report := a.AuditRecords(testChain(t, 5))
+		if !report.Valid || report.LinksChecked != 4 || report.FirstBreak != nil {
+			t.Errorf("AuditRecords() = %+v, want valid with 4 links", report)
+		}
+	})
+
+	t.Run("accept an empty chain", func(t *testing.T) {
+		if report := a.AuditRecords(nil); !report.Valid || report.Records != 0 {
+			t.Errorf("AuditRecords(nil) = %+v, want valid", report)
+		}
+	})
+
+	t.Run("report a gap", func(t *testing.T) {
+		records := testChain(t, 5)
+		records = append(records[:2], records[3:]...)
+		report := a.AuditRecords(records)
+		if report.Valid || report.FirstBreak == nil {
+			t.Fatalf("AuditRecords() = %+v, want a break", report)
+		}
+		if report.FirstBreak.Kind != AuditGap || report.FirstBreak.Index != 2 || report.FirstBreak.UUIDHex != records[2].UUIDHex {
+			t.Errorf("FirstBreak = %+v, want gap at the fourth record", report.FirstBreak)
+		}
+		if report.FirstBreak.Expected != records[1].HashItemHex {
+			t.Errorf("Expected = %v, want %v", report.FirstBreak.Expected, records[1].HashItemHex)
+		}
+	})
+
+	t.Run("report reordered records", func(t *testing.T) {
+		records := testChain(t, 4)
+		records[1], records[2] = records[2], records[1]
+		report := a.AuditRecords(records)
+		if report.FirstBreak == nil || report.FirstBreak.Kind != AuditReorder || report.FirstBreak.Index != 1 {
+			t.Errorf("FirstBreak = %+v, want reorder at 1", report.FirstBreak)
+		}
+		if kinds := findingKinds(report); !slices.Contains(kinds, AuditTimestampRegression) {
+			t.Errorf("Findings = %v, want a timestamp regression", kinds)
+		}
+	})
+
+	t.Run("report timestamp regressions", func(t *testing.T) {
+		records := testChain(t, 3)
+		records[2].Timestamp = "2024-12-31T23:59:59Z"
+		report := a.AuditRecords(records)
+		if kinds := findingKinds(report); len(kinds) != 1 || kinds[0] != AuditTimestampRegression {
+			t.Errorf("Findings = %v, want one timestamp regression", kinds)
+		}
+		if report.FirstBreak != nil {
+			t.Errorf("FirstBreak = %+v, want nil for a linked chain", report.FirstBreak)
+		}
+	})
+
+	t.Run("report duplicates", func(t *testing.T) {
+		records := testChain(t, 2)
+		records = append(records, records[1])
+		kinds := findingKinds(a.AuditRecords(records))
+		if !slices.Contains(kinds, AuditDuplicate) {
+			t.Errorf("Findings = %v, want a duplicate", kinds)
+		}
+	})
+
+	t.Run("check the first link against the anchor", func(t *testing.T) {
+		chain := testChain(t, 4)
+		tail := chain[2:]
+		if report := (&Client{}).NewChainAuditor(WithAuditAnchor(chain[1].HashItemHex)).AuditRecords(tail); !report.Valid || report.LinksChecked != 2 {
+			t.Errorf("AuditRecords() = %+v, want valid with 2 links", report)
+		}
+		report := (&Client{}).NewChainAuditor(WithAuditAnchor(chain[0].HashItemHex)).AuditRecords(tail)
+		if report.FirstBreak == nil || report.FirstBreak.Index != 0 {
+			t.Errorf("FirstBreak = %+v, want break at 0", report.FirstBreak)
+		}
+	})
+}
+
+func TestChainAuditorAuditUUIDs(t *testing.T) {
+	records := testChain(t, 3)
+	byUUID := map[string]DatabaseRecord{}
+	for _, r := range records {
+		byUUID[r.UUIDHex] = r
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		record, ok := byUUID[r.URL.Query().Get("uuid")]
+		if r.URL.Path != "/api/database/record-with-prev" || !ok {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": record})
+	}))
+	defer server.Close()
+	c, _ := NewClient(WithBaseURL(server.URL), WithRetryPolicy(NoRetry))
+	a := c.NewChainAuditor()
+
+	t.Run("fetch and audit records", func(t *testing.T) {
+		report, err := a.AuditUUIDs(context.Background(), []string{records[0].UUIDHex, records[1].UUIDHex, records[2].UUIDHex})
+		if err != nil {
+			t.Fatalf("AuditUUIDs() error = %v", err)
+		}
+		if !report.Valid || report.Records != 3 {
+			t.Errorf("AuditUUIDs() = %+v, want 3 valid records", report)
+		}
+	})
+
+	t.Run("report a skipped UUID as a gap", func(t *testing.T) {
+		report, err := a.AuditUUIDs(context.Background(), []string{records[0].UUIDHex, records[2].UUIDHex})
+		if err != nil {
+			t.Fatalf("AuditUUIDs() error = %v", err)
+		}
+		if report.FirstBreak == nil || report.FirstBreak.Kind != AuditGap {
+			t.Errorf("FirstBreak = %+v, want gap", report.FirstBreak)
+		}
+	})
+
+	t.Run("return fetch errors", func(t *testing.T) {
+		_, err := a.AuditUUIDs(context.Background(), []string{records[0].UUIDHex, "missing"})
+		if !errors.Is(err, ErrNotFound) {
+			t.Errorf("AuditUUIDs() error = %v, want ErrNotFound", err)
+		}
+	})
+}
+
+// newAuditRangeClient serves chain from a fakeHashDB for QueryHashes, plus
+// the record lookups AuditRange makes; withPrev is what record-with-prev
+// returns, defaulting to chain
+func newAuditRangeClient(t *testing.T, chain []DatabaseRecord, withPrev ...DatabaseRecord) (*Client, *fakeHashDB) {
+	t.Helper()
+	if withPrev == nil {
+		withPrev = chain
+	}
+	db := &fakeHashDB{}
+	for _, r := range chain {
+		db.records = append(db.records, HashRecord{Timestamp: r.Timestamp, HashItem: r.HashItemHex})
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case GetRecordByHashRoute:
+			for _, record := range chain {
+				if record.HashItemHex == r.URL.Query().Get("hash_item") {
+					json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]string{"uuid_hex": record.UUIDHex}})
+					return
+				}
+			}
+			http.NotFound(w, r)
+		case "/api/database/record-with-prev":
+			for _, record := range withPrev {
+				if record.UUIDHex == r.URL.Query().Get("uuid") {
+					json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": record})
+					return
+				}
+			}
+			http.NotFound(w, r)
+		default:
+			db.ServeHTTP(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	c, err := NewClient(WithBaseURL(server.URL), WithRetryPolicy(NoRetry))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	return c, db
+}
+
+func TestChainAuditorAuditRange(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("walk every page and check links", func(t *testing.T) {
+		chain := testChain(t, 5)
+		c, db := newAuditRangeClient(t, chain)
+		report, err := c.NewChainAuditor().AuditRange(ctx, DatabaseQuery{Limit: 2})
+		if err != nil {
+			t.Fatalf("AuditRange() error = %v", err)
+		}
+		if !report.Valid || report.Records != 5 || report.LinksChecked != 4 {
+			t.Errorf("AuditRange() = %+v, want 5 valid records and 4 links", report)
+		}
+		if report.LastTimestamp != chain[4].Timestamp || len(db.queries) != 3 {
+			t.Errorf("LastTimestamp = %v after %d queries, want %v after 3", report.LastTimestamp, len(db.queries), chain[4].Timestamp)
+		}
+	})
+
+	t.Run("check links in descending order", func(t *testing.T) {
+		c, _ := newAuditRangeClient(t, testChain(t, 4))
+		report, err := c.NewChainAuditor().AuditRange(ctx, DatabaseQuery{Limit: 3, OrderBy: OrderTimestampDesc})
+		if err != nil {
+			t.Fatalf("AuditRange() error = %v", err)
+		}
+		if !report.Valid || report.LinksChecked != 3 || report.FirstTimestamp != "2025-01-01T00:00:00Z" {
+			t.Errorf("AuditRange() = %+v, want 3 valid links, oldest first", report)
+		}
+	})
+
+	t.Run("report broken links", func(t *testing.T) {
+		chain := testChain(t, 4)
+		withPrev := slices.Clone(chain)
+		withPrev[2].PrevHashHex = Keccak256Str("forged")
+		c, _ := newAuditRangeClient(t, chain, withPrev...)
+
+		report, err := c.NewChainAuditor().AuditRange(ctx, DatabaseQuery{})
+		if err != nil {
+			t.Fatalf("AuditRange() error = %v", err)
+		}
+		if report.FirstBreak == nil || report.FirstBreak.Kind != AuditGap || report.FirstBreak.UUIDHex != chain[2].UUIDHex {
+			t.Errorf("FirstBreak = %+v, want gap at the third record", report.FirstBreak)
+		}
+		if report.HashesChecked != 0 {
+			t.Errorf("HashesChecked = %d, want 0 without WithAuditRecomputeHashes", report.HashesChecked)
+		}
+
+		// The forged prev hash no longer matches the record's hash either
+		report, err = c.NewChainAuditor(WithAuditRecomputeHashes()).AuditRange(ctx, DatabaseQuery{})
+		if err != nil {
+			t.Fatalf("AuditRange() error = %v", err)
+		}
+		if kinds := findingKinds(report); !slices.Equal(kinds, []AuditFindingKind{AuditHashMismatch, AuditGap}) || report.HashesChecked != 4 {
+			t.Errorf("Findings = %v after %d hashes, want hash mismatch and gap after 4", kinds, report.HashesChecked)
+		}
+	})
+
+	t.Run("report records linked out of timestamp order", func(t *testing.T) {
+		chain := testChain(t, 4)
+		chain[1].Timestamp, chain[2].Timestamp = chain[2].Timestamp, chain[1].Timestamp
+		c, _ := newAuditRangeClient(t, chain)
+		report, err := c.NewChainAuditor(WithAuditRecomputeHashes()).AuditRange(ctx, DatabaseQuery{})
+		if err != nil {
+			t.Fatalf("AuditRange() error = %v", err)
+		}
+		if report.FirstBreak == nil || report.FirstBreak.Kind != AuditReorder || report.FirstBreak.UUIDHex != chain[2].UUIDHex {
+			t.Errorf("FirstBreak = %+v, want reorder at the third record", report.FirstBreak)
+		}
+		if kinds := findingKinds(report); slices.Contains(kinds, AuditTimestampRegression) || slices.Contains(kinds, AuditHashMismatch) {
+			t.Errorf("Findings = %v, want no timestamp regression or hash mismatch", kinds)
+		}
+	})
+
+	t.Run("report duplicate hashes", func(t *testing.T) {
+		chain := testChain(t, 3)
+		c, db := newAuditRangeClient(t, chain)
+		db.records = append(db.records, db.records[1])
+		report, err := c.NewChainAuditor().AuditRange(ctx, DatabaseQuery{OrderBy: OrderTimestampDesc})
+		if err != nil {
+			t.Fatalf("AuditRange() error = %v", err)
+		}
+		if kinds := findingKinds(report); !slices.Contains(kinds, AuditDuplicate) {
+			t.Errorf("Findings = %v, want a duplicate", kinds)
+		}
+	})
+
+	t.Run("return lookup errors", func(t *testing.T) {
+		chain := testChain(t, 3)
+		c, _ := newAuditRangeClient(t, chain, chain[:2]...)
+		if _, err := c.NewChainAuditor().AuditRange(ctx, DatabaseQuery{}); !errors.Is(err, ErrNotFound) {
+			t.Errorf("AuditRange() error = %v, want ErrNotFound", err)
+		}
+	})
+}
@@ -0,0 +1,240 @@
+package provable
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultUserAgent is the User-Agent header sent by clients that don't override it
+const DefaultUserAgent = "provable-sdk-go"
+
+// Client talks to a Kayros deployment. Use NewClient to build one; the
+// package-level functions use DefaultClient.
+type Client struct {
+	baseURL     string
+	httpClient  *http.Client
+	userAgent   string
+	dataType    string
+	timeout     time.Duration
+	retryPolicy RetryPolicy
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithBaseURL sets the Kayros base URL (defaults to KayrosHost)
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.baseURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+// WithHTTPClient sets the HTTP client used for requests (defaults to http.DefaultClient)
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithDataType sets the data type used when a call doesn't pass one explicitly
+// It must be 64 hex characters (32 bytes), see ValidateDataType
+func WithDataType(dataType string) Option {
+	return func(c *Client) {
+		c.dataType = dataType
+	}
+}
+
+// WithTimeout sets an overall timeout for each request
+// The HTTP client passed to WithHTTPClient is copied, never modified
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// DefaultClient is the client used by the package-level functions
+var DefaultClient = newDefaultClient()
+
+func newDefaultClient() *Client {
+	return &Client{
+		baseURL:     KayrosHost,
+		httpClient:  http.DefaultClient,
+		userAgent:   DefaultUserAgent,
+		dataType:    DataType,
+		retryPolicy: DefaultRetryPolicy,
+	}
+}
+
+// NewClient creates a Client pointed at KayrosHost, adjusted by opts
+func NewClient(opts ...Option) (*Client, error) {
+	c := newDefaultClient()
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.baseURL == "" {
+		return nil, fmt.Errorf("base URL must not be empty")
+	}
+	if c.httpClient == nil {
+		c.httpClient = http.DefaultClient
+	}
+	if err := ValidateDataType(c.dataType); err != nil {
+		return nil, err
+	}
+	if c.timeout > 0 {
+		httpClient := *c.httpClient
+		httpClient.Timeout = c.timeout
+		c.httpClient = &httpClient
+	}
+
+	return c, nil
+}
+
+// BaseURL returns the Kayros base URL the client talks to
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
+
+// DataType returns the client's default data type
+func (c *Client) DataType() string {
+	return c.dataType
+}
+
+// URL builds a full Kayros API URL from a route
+func (c *Client) URL(route string) string {
+	return c.baseURL + route
+}
+
+// RecordURL returns the URL to view a record on Kayros by its hash
+func (c *Client) RecordURL(hash string) string {
+	return fmt.Sprintf("%s%s?hash_item=%s", c.baseURL, GetRecordByHashRoute, hash)
+}
+
+// get issues a GET request to route and decodes the JSON response into out
+func (c *Client) get(ctx context.Context, route string, out interface{}) error {
+	return c.do(ctx, http.MethodGet, route, nil, out, true, c.retryPolicy)
+}
+
+// post issues a read-only POST request to route with body encoded as JSON and
+// decodes the JSON response into out
+func (c *Client) post(ctx context.Context, route string, body, out interface{}) error {
+	return c.do(ctx, http.MethodPost, route, body, out, true, c.retryPolicy)
+}
+
+// submit is like post for requests that create a record on Kayros, which
+// are not idempotent
+func (c *Client) submit(ctx context.Context, route string, body, out interface{}) error {
+	return c.do(ctx, http.MethodPost, route, body, out, false, c.retryPolicy)
+}
+
+// do sends a request, retrying according to policy, and decodes the JSON
+// response into out
+func (c *Client) do(ctx context.Context, method, route string, body, out interface{}, idempotent bool, policy RetryPolicy) error {
+	var jsonData []byte
+	if body != nil {
+		var err error
+		jsonData, err = json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+	}
+
+	for attempt := 1; ; attempt++ {
+		resp, respBody, err := c.send(ctx, method, route, jsonData)
+		if err != nil {
+			if attempt >= policy.MaxAttempts || ctx.Err() != nil || !policy.shouldRetryError(idempotent) {
+				return err
+			}
+			if sleepErr := sleepContext(ctx, policy.backoff(attempt)); sleepErr != nil {
+				return fmt.Errorf("%w: %w", sleepErr, err)
+			}
+			continue
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			apiErr := newAPIError(resp, method, route, respBody)
+			if attempt >= policy.MaxAttempts || !policy.shouldRetryStatus(resp.StatusCode, idempotent) {
+				return apiErr
+			}
+			delay := policy.backoff(attempt)
+			if wait, ok := retryAfter(resp.Header, time.Now()); ok {
+				if policy.MaxBackoff > 0 && wait > policy.MaxBackoff {
+					return apiErr
+				}
+				delay = wait
+			}
+			if sleepErr := sleepContext(ctx, delay); sleepErr != nil {
+				return fmt.Errorf("%w: %w", sleepErr, apiErr)
+			}
+			continue
+		}
+
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return fmt.Errorf("%w: %w", ErrDecodeResponse, err)
+		}
+
+		// Lightnet endpoints can report failure in the body with a 200 status,
+		// including ones like prove whose response type has no success field
+		if result, ok := out.(*APIResponse); ok && !result.Success || reportsFailure(respBody) {
+			apiErr := newAPIError(resp, method, route, respBody)
+			apiErr.StatusCode = 0
+			return apiErr
+		}
+
+		return nil
+	}
+}
+
+// reportsFailure reports whether body is a JSON object with success=false
+func reportsFailure(body []byte) bool {
+	var status struct {
+		Success *bool `json:"success"`
+	}
+	return json.Unmarshal(body, &status) == nil && status.Success != nil && !*status.Success
+}
+
+// send makes a single HTTP attempt and returns the response with its body read
+func (c *Client) send(ctx context.Context, method, route string, jsonData []byte) (*http.Response, []byte, error) {
+	var reqBody io.Reader
+	if jsonData != nil {
+		reqBody = bytes.NewReader(jsonData)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.URL(route), reqBody)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if jsonData != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	return resp, respBody, nil
+}
@@ -0,0 +1,293 @@
+package provable
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewClient(t *testing.T) {
+	t.Run("use defaults without options", func(t *testing.T) {
+		c, err := NewClient()
+		if err != nil {
+			t.Fatalf("NewClient() error = %v", err)
+		}
+		if c.BaseURL() != KayrosHost {
+			t.Errorf("BaseURL() = %v, want %v", c.BaseURL(), KayrosHost)
+		}
+		if c.DataType() != DataType {
+			t.Errorf("DataType() = %v, want %v", c.DataType(), DataType)
+		}
+	})
+
+	t.Run("trim trailing slash from base URL", func(t *testing.T) {
+		c, err := NewClient(WithBaseURL("https://staging.example.com/"))
+		if err != nil {
+			t.Fatalf("NewClient() error = %v", err)
+		}
+		if got := c.URL("/api/test"); got != "https://staging.example.com/api/test" {
+			t.Errorf("URL() = %v", got)
+		}
+	})
+
+	t.Run("reject empty base URL", func(t *testing.T) {
+		if _, err := NewClient(WithBaseURL("")); err == nil {
+			t.Error("NewClient() error = nil, want error for empty base URL")
+		}
+	})
+
+	t.Run("reject invalid default data type", func(t *testing.T) {
+		_, err := NewClient(WithDataType("short"))
+		if err == nil {
+			t.Fatal("NewClient() error = nil, want error for invalid data type")
+		}
+		if !strings.Contains(err.Error(), "data_type must be exactly 64 hex characters") {
+			t.Errorf("NewClient() error = %v", err)
+		}
+	})
+
+	t.Run("copy HTTP client when setting timeout", func(t *testing.T) {
+		hc := &http.Client{}
+		c, err := NewClient(WithHTTPClient(hc), WithTimeout(5*time.Second))
+		if err != nil {
+			t.Fatalf("NewClient() error = %v", err)
+		}
+		if hc.Timeout != 0 {
+			t.Error("WithTimeout modified the caller's HTTP client")
+		}
+		if c.httpClient.Timeout != 5*time.Second {
+			t.Errorf("timeout = %v, want 5s", c.httpClient.Timeout)
+		}
+	})
+}
+
+func TestClientRecordURL(t *testing.T) {
+	c, err := NewClient(WithBaseURL("http://localhost:8080"))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	want := "http://localhost:8080/api/database/record-by-hash?hash_item=abc"
+	if got := c.RecordURL("abc"); got != want {
+		t.Errorf("RecordURL() = %v, want %v", got, want)
+	}
+}
+
+func TestClientProveSingleHash(t *testing.T) {
+	customDataType := strings.Repeat("ab", 32)
+	var gotBody map[string]string
+	var gotUserAgent string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != ProveSingleHashRoute {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		gotUserAgent = r.Header.Get("User-Agent")
+		json.NewDecoder(r.Body).Decode(&gotBody)
+		w.Write([]byte(`{"data":{"computed_hash_hex":"feed"}}`))
+	}))
+	defer server.Close()
+
+	c, err := NewClient(WithBaseURL(server.URL), WithUserAgent("test-agent"), WithDataType(customDataType))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	t.Run("use client data type by default", func(t *testing.T) {
+		resp, err := c.ProveSingleHash("1234")
+		if err != nil {
+			t.Fatalf("ProveSingleHash() error = %v", err)
+		}
+		if resp.Data.ComputedHashHex != "feed" {
+			t.Errorf("computed_hash_hex = %v, want feed", resp.Data.ComputedHashHex)
+		}
+		if gotBody["data_type"] != customDataType {
+			t.Errorf("data_type = %v, want %v", gotBody["data_type"], customDataType)
+		}
+		if gotBody["data_item"] != "1234" {
+			t.Errorf("data_item = %v, want 1234", gotBody["data_item"])
+		}
+		if gotUserAgent != "test-agent" {
+			t.Errorf("User-Agent = %v, want test-agent", gotUserAgent)
+		}
+	})
+
+	t.Run("prefer explicit data type", func(t *testing.T) {
+		if _, err := c.ProveSingleHash("1234", DataType); err != nil {
+			t.Fatalf("ProveSingleHash() error = %v", err)
+		}
+		if gotBody["data_type"] != DataType {
+			t.Errorf("data_type = %v, want %v", gotBody["data_type"], DataType)
+		}
+	})
+
+	t.Run("hash data before proving", func(t *testing.T) {
+		if _, err := c.ProveDataStr("hello"); err != nil {
+			t.Fatalf("ProveDataStr() error = %v", err)
+		}
+		if gotBody["data_item"] != Keccak256Str("hello") {
+			t.Errorf("data_item = %v, want %v", gotBody["data_item"], Keccak256Str("hello"))
+		}
+	})
+}
+
+func TestClientGetRecordByHash(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != GetRecordByHashRoute {
+			t.Errorf("path = %v, want %v", r.URL.Path, GetRecordByHashRoute)
+		}
+		if r.URL.Query().Get("hash_item") != "abc" {
+			t.Errorf("hash_item = %v, want abc", r.URL.Query().Get("hash_item"))
+		}
+		w.Write([]byte(`{"data":{"data_item_hex":"def","timestamp":"2025-01-01T00:00:00Z"}}`))
+	}))
+	defer server.Close()
+
+	c, err := NewClient(WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	record, err := c.GetRecordByHash("abc")
+	if err != nil {
+		t.Fatalf("GetRecordByHash() error = %v", err)
+	}
+	if record.Data.DataItemHex != "def" {
+		t.Errorf("data_item_hex = %v, want def", record.Data.DataItemHex)
+	}
+}
+
+func TestClientLightnetRoutes(t *testing.T) {
+	var gotMethod, gotPath, gotQuery string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMethod, gotPath, gotQuery = r.Method, r.URL.Path, r.URL.RawQuery
+		w.Write([]byte(`{"success":true}`))
+	}))
+	defer server.Close()
+
+	c, err := NewClient(WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	testCases := []struct {
+		name   string
+		call   func() error
+		method string
+		path   string
+		query  string
+	}{
+		{"QueryHashes", func() error { _, err := c.QueryHashes(DatabaseQuery{Limit: 10}); return err }, http.MethodPost, "/api/database/query", ""},
+		{"GetDatabaseStats", func() error { _, err := c.GetDatabaseStats(); return err }, http.MethodGet, "/api/database/stats", ""},
+		{"GetLatestHashes", func() error { _, err := c.GetLatestHashes(5); return err }, http.MethodGet, "/api/database/latest", "limit=5"},
+		{"GetTables", func() error { _, err := c.GetTables(); return err }, http.MethodGet, "/api/database/tables", ""},
+		{"GetTableSchema", func() error { _, err := c.GetTableSchema("a b"); return err }, http.MethodGet, "/api/database/schema", "table=a+b"},
+		{"BrowseTable", func() error { _, err := c.BrowseTable(TableBrowseRequest{TableName: "t"}); return err }, http.MethodPost, "/api/database/browse", ""},
+		{"GetRecord", func() error { _, err := c.GetRecord("u1"); return err }, http.MethodGet, "/api/database/record", "uuid=u1"},
+		{"GetRecordWithPrevHash", func() error { _, err := c.GetRecordWithPrevHash("u1"); return err }, http.MethodGet, "/api/database/record-with-prev", "uuid=u1"},
+		{"VerifyHash", func() error { _, err := c.VerifyHash(HashVerifyRequest{}); return err }, http.MethodPost, "/api/verify-hash", ""},
+		{"ComputeHashFromHex", func() error { _, err := c.ComputeHashFromHex(ComputeHashRequest{}); return err }, http.MethodPost, "/api/compute-hash-from-hex", ""},
+		{"SendSingleGRPCRequest", func() error { _, err := c.SendSingleGRPCRequest(SingleHashRequest{}); return err }, http.MethodPost, "/api/grpc/single-hash", ""},
+		{"GenerateMerkleProof", func() error { _, err := c.GenerateMerkleProof(GenerateMerkleProofRequest{}); return err }, http.MethodPost, "/api/merkle/generate-proof", ""},
+		{"VerifyMerkleProof", func() error { _, err := c.VerifyMerkleProof(VerifyMerkleProofRequest{}); return err }, http.MethodPost, "/api/merkle/verify-proof", ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.call(); err != nil {
+				t.Fatalf("%s() error = %v", tc.name, err)
+			}
+			if gotMethod != tc.method || gotPath != tc.path || gotQuery != tc.query {
+				t.Errorf("request = %s %s?%s, want %s %s?%s", gotMethod, gotPath, gotQuery, tc.method, tc.path, tc.query)
+			}
+		})
+	}
+}
+
+func TestClientErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	c, err := NewClient(WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	_, err = c.GetRecordByHash("abc")
+	if err == nil {
+		t.Fatal("GetRecordByHash() error = nil, want error")
+	}
+	if !strings.Contains(err.Error(), "500") || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("GetRecordByHash() error = %v, want status and body", err)
+	}
+}
+
+func TestClientContext(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	c, err := NewClient(WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	t.Run("return immediately for cancelled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := c.ProveSingleHashContext(ctx, "1234")
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("ProveSingleHashContext() error = %v, want context.Canceled", err)
+		}
+	})
+
+	t.Run("stop hung request at deadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		_, err := c.QueryHashesContext(ctx, DatabaseQuery{Limit: 1})
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("QueryHashesContext() error = %v, want context.DeadlineExceeded", err)
+		}
+	})
+
+	t.Run("propagate into verify remote lookup", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		data := "hello"
+		envelope := &KayrosEnvelope{
+			Data: data,
+			Kayros: KayrosMetadata{
+				Hash: Keccak256Str(data),
+				Timestamp: &KayrosTimestamp{
+					Service:  c.URL(ProveSingleHashRoute),
+					Response: ProveSingleHashResponse{Data: ProveSingleHashResponseData{ComputedHashHex: "feed"}},
+				},
+			},
+		}
+
+		start := time.Now()
+		result := c.VerifyContext(ctx, envelope)
+		if result.Valid {
+			t.Fatal("VerifyContext() valid = true, want false")
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("VerifyContext() took %v, want it to stop at the deadline", elapsed)
+		}
+		if !strings.Contains(result.Error, "Failed to fetch remote record") {
+			t.Errorf("VerifyContext() error = %v", result.Error)
+		}
+	})
+}
@@ -0,0 +1,139 @@
+// Command lightnetd serves the Lightnet gRPC HashService from memory, for
+// developing against Lightnet without network access
+//
+// Usage:
+//
+//	lightnetd [-listen addr] [-start time] [-tick duration] [-hash-type blake3|xxh3] [-grace duration]
+//
+// Records live only as long as the process and are timestamped with the
+// current time. With -start, timestamps start there instead and advance by
+// -tick per record, so the run gives the same hashes every time.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc"
+
+	provable "github.com/provable/provable-sdk-go"
+	lightnetpb "github.com/provable/provable-sdk-go/proto/lightnet"
+	"github.com/provable/provable-sdk-go/provabletest"
+)
+
+// Exit statuses
+const (
+	exitOK    = 0
+	exitUsage = 2
+	exitError = 3
+)
+
+// defaultGrace is how long lightnetd waits for open streams to finish when
+// asked to stop
+const defaultGrace = 5 * time.Second
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	code := run(ctx, os.Args[1:], os.Stderr)
+	stop()
+	os.Exit(code)
+}
+
+// run parses args, then serves until ctx is done, and returns the exit status
+func run(ctx context.Context, args []string, stderr io.Writer) int {
+	fs := flag.NewFlagSet("lightnetd", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	listen := fs.String("listen", "localhost:50051", "address to listen on")
+	start := fs.String("start", "", "RFC 3339 timestamp of the first record, for reproducible runs (default: timestamp records with the current time)")
+	tick := fs.Duration("tick", provabletest.DefaultTick, "time between consecutive records with -start")
+	grace := fs.Duration("grace", defaultGrace, "how long to wait for open streams on shutdown before closing them")
+	hashType := fs.String("hash-type", provable.RecordHashBLAKE3, "record hash type, blake3 or xxh3")
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(stderr, "lightnetd: unexpected arguments %q\n", fs.Args())
+		return exitUsage
+	}
+	if *hashType != provable.RecordHashBLAKE3 && *hashType != provable.RecordHashXXH3 {
+		fmt.Fprintf(stderr, "lightnetd: unknown hash type %q\n", *hashType)
+		return exitUsage
+	}
+
+	server, err := newServer(*start, *tick, *hashType, time.Now)
+	if err != nil {
+		fmt.Fprintf(stderr, "lightnetd: %v\n", err)
+		return exitUsage
+	}
+
+	lis, err := net.Listen("tcp", *listen)
+	if err != nil {
+		fmt.Fprintf(stderr, "lightnetd: %v\n", err)
+		return exitError
+	}
+	fmt.Fprintf(stderr, "lightnetd: serving Lightnet on %s\n", lis.Addr())
+
+	if err := serve(ctx, lis, server, *grace); err != nil {
+		fmt.Fprintf(stderr, "lightnetd: %v\n", err)
+		return exitError
+	}
+	return exitOK
+}
+
+// newServer creates the LightnetServer to serve, timestamping records with
+// now, or from start by tick when start is set
+func newServer(start string, tick time.Duration, hashType string, now func() time.Time) (*provabletest.LightnetServer, error) {
+	opts := []provabletest.Option{provabletest.WithHashType(hashType)}
+	if start == "" {
+		opts = append(opts, provabletest.WithClock(now))
+	} else {
+		startTime, err := time.Parse(time.RFC3339Nano, start)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -start: %w", err)
+		}
+		opts = append(opts, provabletest.WithStartTime(startTime), provabletest.WithTick(tick))
+	}
+	return provabletest.NewLightnetServer(opts...), nil
+}
+
+// serve serves srv on lis until ctx is done, then stops gracefully, closing
+// connections still open after grace, e.g. streams of a BatchProver
+func serve(ctx context.Context, lis net.Listener, srv lightnetpb.HashServiceServer, grace time.Duration) error {
+	server := grpc.NewServer()
+	lightnetpb.RegisterHashServiceServer(server, srv)
+
+	served := make(chan error, 1)
+	go func() { served <- server.Serve(lis) }()
+	select {
+	case err := <-served:
+		return err
+	case <-ctx.Done():
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+	timer := time.NewTimer(grace)
+	defer timer.Stop()
+	select {
+	case <-stopped:
+	case <-timer.C:
+		server.Stop()
+		<-stopped
+	}
+
+	if err := <-served; err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	provable "github.com/provable/provable-sdk-go"
+	"github.com/provable/provable-sdk-go/provabletest"
+)
+
+func TestServe(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() error = %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- serve(ctx, lis, provabletest.NewLightnetServer(), defaultGrace) }()
+
+	c, err := provable.DialLightnet(lis.Addr().String(), provable.WithInsecure())
+	if err != nil {
+		t.Fatalf("DialLightnet() error = %v", err)
+	}
+	defer c.Close()
+	item := provable.Keccak256Str("hello")
+	if _, err := c.SubmitHash(ctx, item); err != nil {
+		t.Fatalf("SubmitHash() error = %v", err)
+	}
+	if root, err := c.GetMerkleRoot(ctx); err != nil || root.TotalRecords != 1 {
+		t.Errorf("GetMerkleRoot() = %+v, %v, want 1 record", root, err)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("serve() error = %v, want nil after cancel", err)
+	}
+}
+
+func TestServeStopsOpenStreams(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() error = %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- serve(ctx, lis, provabletest.NewLightnetServer(), 50*time.Millisecond) }()
+
+	c, err := provable.DialLightnet(lis.Addr().String(), provable.WithInsecure())
+	if err != nil {
+		t.Fatalf("DialLightnet() error = %v", err)
+	}
+	defer c.Close()
+	prover, err := c.NewBatchProver(context.Background())
+	if err != nil {
+		t.Fatalf("NewBatchProver() error = %v", err)
+	}
+	defer prover.Close()
+	if err := prover.Submit(context.Background(), provable.Keccak256Str("open")); err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	<-prover.Results()
+
+	// The stream stays open, so a graceful stop alone would wait forever
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("serve() error = %v, want nil after cancel", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("serve() still running 5s after cancel with a stream open")
+	}
+}
+
+func TestNewServer(t *testing.T) {
+	item := provable.Keccak256Str("hello")
+
+	t.Run("timestamp records with the clock", func(t *testing.T) {
+		now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+		server, err := newServer("", time.Hour, provable.RecordHashBLAKE3, func() time.Time { return now })
+		if err != nil {
+			t.Fatalf("newServer() error = %v", err)
+		}
+		first, _ := server.Append(provable.DataType, item)
+		now = now.Add(3 * time.Second)
+		second, _ := server.Append(provable.DataType, item)
+		if first.Timestamp != "2026-03-01T12:00:00.000000Z" || second.Timestamp != "2026-03-01T12:00:03.000000Z" {
+			t.Errorf("timestamps = %s, %s, want the clock's", first.Timestamp, second.Timestamp)
+		}
+	})
+
+	t.Run("count ticks from -start", func(t *testing.T) {
+		server, err := newServer("2024-01-01T00:00:00Z", time.Second, provable.RecordHashBLAKE3, func() time.Time {
+			t.Error("clock called with -start")
+			return time.Time{}
+		})
+		if err != nil {
+			t.Fatalf("newServer() error = %v", err)
+		}
+		server.Append(provable.DataType, item)
+		second, _ := server.Append(provable.DataType, item)
+		if second.Timestamp != "2024-01-01T00:00:01.000000Z" {
+			t.Errorf("Timestamp = %s, want one tick after -start", second.Timestamp)
+		}
+	})
+}
+
+func TestRun(t *testing.T) {
+	for _, args := range [][]string{{"-nope"}, {"extra"}, {"-hash-type", "md5"}, {"-start", "yesterday"}} {
+		var stderr bytes.Buffer
+		if code := run(context.Background(), args, &stderr); code != exitUsage {
+			t.Errorf("%v exit = %d, want %d", args, code, exitUsage)
+		}
+	}
+}
@@ -0,0 +1,433 @@
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"slices"
+
+	provable "github.com/provable/provable-sdk-go"
+)
+
+type hashOutput struct {
+	Name      string `json:"name"`
+	Algorithm string `json:"algorithm"`
+	Hash      string `json:"hash"`
+	Size      int64  `json:"size"`
+}
+
+func runHash(c *cli, args []string) int {
+	fs := c.flagSet("hash", "[file...]")
+	algorithm := fs.String("algorithm", provable.HashAlgorithmKeccak256, "hash algorithm, e.g. keccak256 or sha256")
+	if code, ok := c.parse(fs, args, nil); !ok {
+		return code
+	}
+	newHash, err := provable.LookupStreamingHashAlgorithm(*algorithm)
+	if err != nil {
+		return c.usageError("%v", err)
+	}
+
+	names := fs.Args()
+	if len(names) == 0 {
+		names = []string{"-"}
+	}
+	var out []hashOutput
+	for _, name := range names {
+		r, err := c.open(name)
+		if err != nil {
+			return c.fail(err)
+		}
+		h := newHash()
+		size, err := io.Copy(h, r)
+		r.Close()
+		if err != nil {
+			return c.fail(fmt.Errorf("read %s: %w", name, err))
+		}
+		out = append(out, hashOutput{Name: name, Algorithm: *algorithm, Hash: hex.EncodeToString(h.Sum(nil)), Size: size})
+	}
+
+	c.print(out, func(w io.Writer) {
+		for _, o := range out {
+			fmt.Fprintf(w, "%s  %s\n", o.Hash, o.Name)
+		}
+	})
+	return exitOK
+}
+
+type proveOutput struct {
+	Hash            string                   `json:"hash"`
+	ComputedHashHex string                   `json:"computed_hash_hex"`
+	RecordURL       string                   `json:"record_url"`
+	Envelope        *provable.KayrosEnvelope `json:"envelope,omitempty"`
+}
+
+// isHash reports whether s is a 32-byte hash in hex
+func isHash(s string) bool {
+	_, err := hex.DecodeString(s)
+	return len(s) == 64 && err == nil
+}
+
+func runProve(c *cli, args []string) int {
+	fs := c.flagSet("prove", "[file | -hash hex]")
+	algorithm := fs.String("algorithm", provable.HashAlgorithmKeccak256, "hash algorithm for file contents")
+	dataType := fs.String("data-type", "", "Kayros data type, 64 hex characters (defaults to the SDK's)")
+	hash := fs.String("hash", "", "prove this hash instead of hashing a file")
+	output := fs.String("o", "", "write the detached envelope to this file")
+	if code, ok := c.parse(fs, args, func(n int) bool { return n <= 1 }); !ok {
+		return code
+	}
+	if *hash != "" && (fs.NArg() > 0 || *output != "") {
+		return c.usageError("-hash cannot be combined with a file or -o")
+	}
+	if *hash != "" && !isHash(*hash) {
+		return c.usageError("-hash must be 64 hex characters, got %q", *hash)
+	}
+	if *dataType != "" {
+		if err := provable.ValidateDataType(*dataType); err != nil {
+			return c.usageError("%v", err)
+		}
+	}
+	client, err := c.client()
+	if err != nil {
+		return c.fail(err)
+	}
+
+	out := proveOutput{Hash: *hash}
+	if *hash != "" {
+		var dataTypes []string
+		if *dataType != "" {
+			dataTypes = append(dataTypes, *dataType)
+		}
+		proof, err := client.ProveSingleHashContext(c.ctx, *hash, dataTypes...)
+		if err != nil {
+			return c.fail(err)
+		}
+		out.ComputedHashHex = proof.Data.ComputedHashHex
+	} else {
+		opts := []provable.EnvelopeOption{provable.WithHashAlgorithm(*algorithm)}
+		if *dataType != "" {
+			opts = append(opts, provable.WithEnvelopeDataType(*dataType))
+		}
+		var proof *provable.ProveSingleHashResponse
+		if name := fs.Arg(0); name != "" && name != "-" {
+			proof, out.Envelope, err = client.ProveFileContext(c.ctx, name, opts...)
+		} else {
+			proof, out.Envelope, err = client.ProveReader(c.ctx, c.stdin, append(opts, provable.WithDetachedName("stdin"))...)
+		}
+		if err != nil {
+			return c.fail(err)
+		}
+		out.Hash = out.Envelope.Kayros.Hash
+		out.ComputedHashHex = proof.Data.ComputedHashHex
+		if *output != "" {
+			if err := writeJSON(*output, out.Envelope); err != nil {
+				return c.fail(err)
+			}
+		}
+	}
+	out.RecordURL = client.RecordURL(out.ComputedHashHex)
+
+	c.print(out, func(w io.Writer) {
+		fmt.Fprintf(w, "hash:          %s\n", out.Hash)
+		fmt.Fprintf(w, "computed hash: %s\n", out.ComputedHashHex)
+		fmt.Fprintf(w, "record:        %s\n", out.RecordURL)
+		if *output != "" {
+			fmt.Fprintf(w, "envelope:      %s\n", *output)
+		}
+	})
+	return exitOK
+}
+
+func runRecord(c *cli, args []string) int {
+	fs := c.flagSet("record", "hash")
+	if code, ok := c.parse(fs, args, exactly(1)); !ok {
+		return code
+	}
+	client, err := c.client()
+	if err != nil {
+		return c.fail(err)
+	}
+	record, err := client.GetRecordByHashContext(c.ctx, fs.Arg(0))
+	if err != nil {
+		return c.fail(err)
+	}
+
+	c.print(record, func(w io.Writer) {
+		fmt.Fprintf(w, "data item: %s\n", record.Data.DataItemHex)
+		if record.Data.DataType != "" {
+			fmt.Fprintf(w, "data type: %s\n", record.Data.DataType)
+		}
+		if record.Data.Timestamp != "" {
+			fmt.Fprintf(w, "timestamp: %s\n", record.Data.Timestamp)
+		}
+	})
+	return exitOK
+}
+
+func runVerify(c *cli, args []string) int {
+	fs := c.flagSet("verify", "envelope.json")
+	content := fs.String("content", "", "content file of a detached envelope (\"-\" for stdin)")
+	offline := fs.Bool("offline", false, "verify from the evidence embedded in the envelope (needs -trusted-root)")
+	var trustedRoots stringList
+	fs.Var(&trustedRoots, "trusted-root", "trusted Merkle root (repeatable)")
+	if code, ok := c.parse(fs, args, exactly(1)); !ok {
+		return code
+	}
+
+	var envelope provable.KayrosEnvelope
+	if err := c.readJSON(fs.Arg(0), &envelope); err != nil {
+		return c.fail(err)
+	}
+	client, err := c.client()
+	if err != nil {
+		return c.fail(err)
+	}
+
+	opts := provable.VerifyOptions{Offline: *offline, TrustedRoots: trustedRoots}
+	var result *provable.VerifyResult
+	switch {
+	case envelope.Kayros.Detached == nil:
+		result = client.VerifyWithOptions(c.ctx, &envelope, opts)
+	case *content == "":
+		return c.usageError("%s is a detached envelope; pass its content with -content", fs.Arg(0))
+	default:
+		r, err := c.open(*content)
+		if err != nil {
+			return c.fail(err)
+		}
+		defer r.Close()
+		result = client.VerifyReader(c.ctx, &envelope, r, opts)
+	}
+
+	c.print(result, func(w io.Writer) {
+		if result.Valid {
+			fmt.Fprintln(w, "valid")
+		} else {
+			fmt.Fprintf(w, "invalid: %s (%s)\n", result.Error, result.Code)
+		}
+		if d := result.Details; d != nil {
+			fmt.Fprintf(w, "computed hash: %s\n", d.ComputedHash)
+			if d.RemoteHash != "" {
+				fmt.Fprintf(w, "remote hash:   %s\n", d.RemoteHash)
+			}
+			if d.MerkleRoot != "" {
+				fmt.Fprintf(w, "merkle root:   %s\n", d.MerkleRoot)
+			}
+		}
+	})
+	if !result.Valid {
+		return exitInvalid
+	}
+	return exitOK
+}
+
+func runMerkle(c *cli, args []string) int {
+	if len(args) == 0 {
+		return c.usageError("merkle needs a subcommand: proof, verify or root")
+	}
+	switch args[0] {
+	case "proof":
+		return runMerkleProof(c, args[1:])
+	case "verify":
+		return runMerkleVerify(c, args[1:])
+	case "root":
+		return runMerkleRoot(c, args[1:])
+	}
+	return c.usageError("unknown merkle subcommand %q", args[0])
+}
+
+func runMerkleProof(c *cli, args []string) int {
+	fs := c.flagSet("merkle proof", "hash")
+	dataType := fs.String("data-type", "", "data type of the record")
+	timestamp := fs.String("timestamp", "", "timestamp of the record")
+	output := fs.String("o", "", "write the proof to this file")
+	if code, ok := c.parse(fs, args, exactly(1)); !ok {
+		return code
+	}
+	client, err := c.client()
+	if err != nil {
+		return c.fail(err)
+	}
+	proof, err := client.GenerateMerkleProofContext(c.ctx, provable.GenerateMerkleProofRequest{
+		HashItem:  fs.Arg(0),
+		DataType:  *dataType,
+		Timestamp: *timestamp,
+	})
+	if err != nil {
+		return c.fail(err)
+	}
+	if *output != "" {
+		if err := writeJSON(*output, proof); err != nil {
+			return c.fail(err)
+		}
+	}
+
+	c.print(proof, func(w io.Writer) {
+		fmt.Fprintf(w, "target:   %s\n", proof.TargetHashHex)
+		fmt.Fprintf(w, "root:     %s\n", proof.RootHashHex)
+		fmt.Fprintf(w, "position: %d\n", proof.Position)
+		fmt.Fprintf(w, "levels:   %d\n", proof.Levels)
+	})
+	return exitOK
+}
+
+func runMerkleVerify(c *cli, args []string) int {
+	fs := c.flagSet("merkle verify", "proof.json")
+	local := fs.Bool("local", false, "recompute the root locally instead of asking Kayros")
+	if code, ok := c.parse(fs, args, exactly(1)); !ok {
+		return code
+	}
+	var proof provable.MerkleProof
+	if err := c.readJSON(fs.Arg(0), &proof); err != nil {
+		return c.fail(err)
+	}
+
+	var result *provable.MerkleProofVerificationResult
+	var err error
+	if *local {
+		result, err = provable.VerifyMerkleProofLocal(proof)
+	} else {
+		client, cerr := c.client()
+		if cerr != nil {
+			return c.fail(cerr)
+		}
+		root := proof.RootHashHex
+		if root == "" {
+			root = proof.StoredRootHex
+		}
+		result, err = client.VerifyMerkleProofContext(c.ctx, provable.VerifyMerkleProofRequest{
+			TargetHashHex:  proof.TargetHashHex,
+			ProofHashesHex: proof.ProofHashesHex,
+			Levels:         proof.Levels,
+			Position:       proof.Position,
+			RootHashHex:    root,
+		})
+	}
+	if err != nil {
+		return c.fail(err)
+	}
+
+	c.print(result, func(w io.Writer) {
+		if result.Valid {
+			fmt.Fprintln(w, "valid")
+		} else {
+			fmt.Fprintf(w, "invalid: %s\n", result.Message)
+		}
+		fmt.Fprintf(w, "computed root: %s\n", result.ComputedRootHex)
+	})
+	if !result.Valid {
+		return exitInvalid
+	}
+	return exitOK
+}
+
+func runMerkleRoot(c *cli, args []string) int {
+	fs := c.flagSet("merkle root", "")
+	addr := fs.String("lightnet", c.getenv(lightnetEnv), "Lightnet gRPC address, host:port (env "+lightnetEnv+")")
+	plaintext := fs.Bool("insecure", false, "connect to Lightnet without TLS, e.g. to a local lightnetd")
+	if code, ok := c.parse(fs, args, exactly(0)); !ok {
+		return code
+	}
+	if *addr == "" {
+		return c.usageError("merkle root needs a Lightnet address: -lightnet or $%s", lightnetEnv)
+	}
+
+	var opts []provable.LightnetOption
+	if *plaintext {
+		opts = append(opts, provable.WithInsecure())
+	}
+	ln, err := provable.DialLightnet(*addr, opts...)
+	if err != nil {
+		return c.fail(err)
+	}
+	defer ln.Close()
+
+	ctx, cancel := context.WithTimeout(c.ctx, c.timeout)
+	defer cancel()
+	root, err := ln.GetMerkleRoot(ctx)
+	if err != nil {
+		return c.fail(err)
+	}
+
+	c.print(root, func(w io.Writer) {
+		fmt.Fprintf(w, "root:    %s\n", root.RootHashHex)
+		fmt.Fprintf(w, "records: %d\n", root.TotalRecords)
+	})
+	return exitOK
+}
+
+func runStats(c *cli, args []string) int {
+	fs := c.flagSet("stats", "")
+	if code, ok := c.parse(fs, args, exactly(0)); !ok {
+		return code
+	}
+	client, err := c.client()
+	if err != nil {
+		return c.fail(err)
+	}
+	stats, err := client.GetDatabaseStatsContext(c.ctx)
+	if err != nil {
+		return c.fail(err)
+	}
+
+	c.print(stats, func(w io.Writer) {
+		fmt.Fprintf(w, "total hashes: %d\n", stats.TotalHashes)
+		fmt.Fprintf(w, "first:        %s\n", stats.MinTimestamp)
+		fmt.Fprintf(w, "last:         %s\n", stats.MaxTimestamp)
+		types := make([]string, 0, len(stats.CountByType))
+		for t := range stats.CountByType {
+			types = append(types, t)
+		}
+		slices.Sort(types)
+		for _, t := range types {
+			fmt.Fprintf(w, "  %s  %d\n", t, stats.CountByType[t])
+		}
+	})
+	return exitOK
+}
+
+func runQuery(c *cli, args []string) int {
+	fs := c.flagSet("query", "")
+	dataType := fs.String("data-type", "", "only hashes of this data type")
+	hashType := fs.String("hash-type", "", "only hashes of this hash type")
+	since := fs.String("since", "", "only hashes at or after this timestamp")
+	until := fs.String("until", "", "only hashes at or before this timestamp")
+	limit := fs.Int("limit", provable.DefaultPageSize, "maximum number of hashes")
+	offset := fs.Int("offset", 0, "number of hashes to skip")
+	order := fs.String("order", provable.OrderTimestampDesc, "ts_asc or ts_desc")
+	if code, ok := c.parse(fs, args, exactly(0)); !ok {
+		return code
+	}
+	if *order != provable.OrderTimestampAsc && *order != provable.OrderTimestampDesc {
+		return c.usageError("-order must be %s or %s", provable.OrderTimestampAsc, provable.OrderTimestampDesc)
+	}
+	client, err := c.client()
+	if err != nil {
+		return c.fail(err)
+	}
+
+	query := provable.DatabaseQuery{Limit: *limit, Offset: *offset, OrderBy: *order}
+	optional := func(s string) *string {
+		if s == "" {
+			return nil
+		}
+		return &s
+	}
+	query.DataType = optional(*dataType)
+	query.HashType = optional(*hashType)
+	query.MinTimestamp = optional(*since)
+	query.MaxTimestamp = optional(*until)
+	records, err := client.QueryHashesContext(c.ctx, query)
+	if err != nil {
+		return c.fail(err)
+	}
+
+	c.print(records, func(w io.Writer) {
+		for _, r := range records {
+			fmt.Fprintf(w, "%s  %s  %s\n", r.Timestamp, r.DataType, r.HashItem)
+		}
+		fmt.Fprintf(w, "%d hashes\n", len(records))
+	})
+	return exitOK
+}
@@ -0,0 +1,220 @@
+// Command provable hashes, proves and verifies data with Kayros
+//
+// Usage:
+//
+//	provable <command> [flags] [args]
+//
+// Every command accepts -host (or PROVABLE_HOST), -json and -timeout;
+// merkle root talks to Lightnet at -lightnet (or PROVABLE_LIGHTNET) instead.
+// The exit status is 0 on success, 1 when a verification fails, 2 on a usage
+// error and 3 when the command could not be carried out.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	provable "github.com/provable/provable-sdk-go"
+)
+
+// Exit statuses
+const (
+	exitOK      = 0
+	exitInvalid = 1
+	exitUsage   = 2
+	exitError   = 3
+)
+
+// hostEnv names the environment variable that sets the default Kayros host
+const hostEnv = "PROVABLE_HOST"
+
+// lightnetEnv names the environment variable that sets the default Lightnet
+// gRPC address, for the commands that talk to Lightnet directly
+const lightnetEnv = "PROVABLE_LIGHTNET"
+
+// command is a provable subcommand
+type command struct {
+	name    string
+	args    string
+	summary string
+	run     func(c *cli, args []string) int
+}
+
+var commands = []command{
+	{"hash", "[file...]", "Hash files or stdin", runHash},
+	{"prove", "[file | -hash hex]", "Hash and prove a file or stdin, or prove a hash", runProve},
+	{"record", "hash", "Get the Kayros record of a computed hash", runRecord},
+	{"verify", "envelope.json", "Verify an envelope", runVerify},
+	{"merkle", "proof|verify|root ...", "Generate or verify Merkle proofs, or get the Merkle root", runMerkle},
+	{"stats", "", "Show database statistics", runStats},
+	{"query", "", "Query proved hashes", runQuery},
+}
+
+// cli holds the I/O and common flags shared by every command
+type cli struct {
+	ctx    context.Context
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	getenv func(string) string
+
+	host    string
+	json    bool
+	timeout time.Duration
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	code := run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr, os.Getenv)
+	stop()
+	os.Exit(code)
+}
+
+// run executes the command line args and returns the exit status
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer, getenv func(string) string) int {
+	c := &cli{ctx: ctx, stdin: stdin, stdout: stdout, stderr: stderr, getenv: getenv}
+	if len(args) == 0 {
+		c.usage()
+		return exitUsage
+	}
+	switch args[0] {
+	case "help", "-h", "-help", "--help":
+		c.usage()
+		return exitOK
+	}
+	for _, cmd := range commands {
+		if cmd.name == args[0] {
+			return cmd.run(c, args[1:])
+		}
+	}
+	fmt.Fprintf(stderr, "provable: unknown command %q\n", args[0])
+	c.usage()
+	return exitUsage
+}
+
+func (c *cli) usage() {
+	fmt.Fprintln(c.stderr, "Usage: provable <command> [flags] [args]")
+	fmt.Fprintln(c.stderr)
+	fmt.Fprintln(c.stderr, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(c.stderr, "  %-8s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(c.stderr)
+	fmt.Fprintf(c.stderr, "Run 'provable <command> -h' for a command's flags. The Kayros host\ndefaults to $%s, then %s.\n", hostEnv, provable.KayrosHost)
+}
+
+// flagSet returns a flag set for name with the common flags registered
+func (c *cli) flagSet(name, args string) *flag.FlagSet {
+	fs := flag.NewFlagSet("provable "+name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(c.stderr, "Usage: provable %s [flags] %s\n\nFlags:\n", name, args)
+		fs.PrintDefaults()
+	}
+
+	host := c.getenv(hostEnv)
+	if host == "" {
+		host = provable.KayrosHost
+	}
+	fs.StringVar(&c.host, "host", host, "Kayros base URL (env "+hostEnv+")")
+	fs.BoolVar(&c.json, "json", false, "print JSON instead of text")
+	fs.DurationVar(&c.timeout, "timeout", 30*time.Second, "timeout for each request")
+	return fs
+}
+
+// parse parses args into fs, returning false if the command should exit
+// with the returned status
+func (c *cli) parse(fs *flag.FlagSet, args []string, nargs func(int) bool) (int, bool) {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK, false
+		}
+		return exitUsage, false
+	}
+	if nargs != nil && !nargs(fs.NArg()) {
+		fs.Usage()
+		return exitUsage, false
+	}
+	return exitOK, true
+}
+
+func (c *cli) client() (*provable.Client, error) {
+	return provable.NewClient(provable.WithBaseURL(c.host), provable.WithTimeout(c.timeout))
+}
+
+// print writes v as JSON, or calls text when -json is not set
+func (c *cli) print(v interface{}, text func(w io.Writer)) {
+	if !c.json {
+		text(c.stdout)
+		return
+	}
+	enc := json.NewEncoder(c.stdout)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
+
+// fail reports err and returns exitError
+func (c *cli) fail(err error) int {
+	fmt.Fprintf(c.stderr, "provable: %v\n", err)
+	return exitError
+}
+
+// usageError reports a bad argument and returns exitUsage
+func (c *cli) usageError(format string, args ...interface{}) int {
+	fmt.Fprintf(c.stderr, "provable: "+format+"\n", args...)
+	return exitUsage
+}
+
+// open opens name for reading, with "-" meaning stdin
+func (c *cli) open(name string) (io.ReadCloser, error) {
+	if name == "-" {
+		return io.NopCloser(c.stdin), nil
+	}
+	return os.Open(name)
+}
+
+// readJSON decodes the JSON file name ("-" for stdin) into v
+func (c *cli) readJSON(name string, v interface{}) error {
+	r, err := c.open(name)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	if err := json.NewDecoder(r).Decode(v); err != nil {
+		return fmt.Errorf("decode %s: %w", name, err)
+	}
+	return nil
+}
+
+// writeJSON writes v as indented JSON to the file name
+func writeJSON(name string, v interface{}) error {
+	raw, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(name, append(raw, '\n'), 0o644)
+}
+
+// stringList is a flag that may be repeated
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(s string) error {
+	*l = append(*l, s)
+	return nil
+}
+
+func exactly(n int) func(int) bool {
+	return func(got int) bool { return got == n }
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+
+	provable "github.com/provable/provable-sdk-go"
+	lightnetpb "github.com/provable/provable-sdk-go/proto/lightnet"
+	"github.com/provable/provable-sdk-go/provabletest"
+)
+
+// newFakeKayros starts a provabletest.Server for the duration of the test
+func newFakeKayros(t *testing.T) *provabletest.Server {
+	t.Helper()
+	server := provabletest.NewServer()
+	t.Cleanup(server.Close)
+	return server
+}
+
+// newFakeLightnet serves a provabletest.LightnetServer over TCP for the
+// duration of the test and returns it with its address
+func newFakeLightnet(t *testing.T) (*provabletest.LightnetServer, string) {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() error = %v", err)
+	}
+	ln := provabletest.NewLightnetServer()
+	server := grpc.NewServer()
+	lightnetpb.RegisterHashServiceServer(server, ln)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+	return ln, lis.Addr().String()
+}
+
+// runCLI runs the command line args against host and returns the exit
+// status with stdout and stderr
+func runCLI(t *testing.T, host string, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	getenv := func(key string) string {
+		if key == hostEnv {
+			return host
+		}
+		return ""
+	}
+	code := run(context.Background(), args, strings.NewReader(stdin), &stdout, &stderr, getenv)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestHash(t *testing.T) {
+	t.Run("hash stdin", func(t *testing.T) {
+		code, stdout, _ := runCLI(t, "", "abc", "hash")
+		if want := provable.Keccak256Str("abc") + "  -\n"; code != exitOK || stdout != want {
+			t.Errorf("hash = %d %q, want %q", code, stdout, want)
+		}
+	})
+
+	t.Run("hash files as JSON", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "a.txt")
+		os.WriteFile(path, []byte("abc"), 0o600)
+		code, stdout, _ := runCLI(t, "", "", "hash", "-json", "-algorithm", "sha256", path)
+		var out []hashOutput
+		if err := json.Unmarshal([]byte(stdout), &out); err != nil || code != exitOK {
+			t.Fatalf("hash = %d %q, want JSON", code, stdout)
+		}
+		if len(out) != 1 || out[0].Hash != provable.SHA256Str("abc") || out[0].Size != 3 {
+			t.Errorf("hash = %+v, want SHA-256 of abc", out)
+		}
+	})
+
+	t.Run("reject unknown algorithms", func(t *testing.T) {
+		if code, _, _ := runCLI(t, "", "", "hash", "-algorithm", "md5"); code != exitUsage {
+			t.Errorf("exit = %d, want %d", code, exitUsage)
+		}
+	})
+}
+
+func TestProveAndVerify(t *testing.T) {
+	server := newFakeKayros(t)
+	dir := t.TempDir()
+	content := filepath.Join(dir, "backup.tar")
+	envelope := filepath.Join(dir, "backup.tar.kayros.json")
+	os.WriteFile(content, []byte("backup"), 0o600)
+
+	code, stdout, stderr := runCLI(t, server.URL, "", "prove", "-o", envelope, content)
+	if code != exitOK {
+		t.Fatalf("prove = %d, stderr %q", code, stderr)
+	}
+	if !strings.Contains(stdout, provable.Keccak256Str("backup")) {
+		t.Errorf("prove stdout = %q, want the file hash", stdout)
+	}
+
+	t.Run("accept the content", func(t *testing.T) {
+		if code, stdout, stderr := runCLI(t, server.URL, "", "verify", "-content", content, envelope); code != exitOK || !strings.HasPrefix(stdout, "valid") {
+			t.Errorf("verify = %d %q %q, want valid", code, stdout, stderr)
+		}
+	})
+
+	t.Run("reject other content", func(t *testing.T) {
+		code, stdout, _ := runCLI(t, server.URL, "changed", "verify", "-json", "-content", "-", envelope)
+		var result provable.VerifyResult
+		json.Unmarshal([]byte(stdout), &result)
+		if code != exitInvalid || result.Code != provable.VerifyErrorHashMismatch {
+			t.Errorf("verify = %d %+v, want hash mismatch", code, result)
+		}
+	})
+
+	t.Run("require content for detached envelopes", func(t *testing.T) {
+		if code, _, _ := runCLI(t, server.URL, "", "verify", envelope); code != exitUsage {
+			t.Errorf("exit = %d, want %d", code, exitUsage)
+		}
+	})
+
+	t.Run("verify attached envelopes", func(t *testing.T) {
+		client, _ := server.Client()
+		sealed, _ := client.Seal(map[string]string{"a": "b"})
+		path := filepath.Join(dir, "sealed.json")
+		raw, _ := json.Marshal(sealed)
+		os.WriteFile(path, raw, 0o600)
+		if code, stdout, _ := runCLI(t, server.URL, "", "verify", path); code != exitOK {
+			t.Errorf("verify = %d %q, want valid", code, stdout)
+		}
+	})
+
+	t.Run("reject malformed hashes", func(t *testing.T) {
+		for _, hash := range []string{"abc", strings.Repeat("zz", 32), provable.Keccak256Str("x") + "00"} {
+			if code, _, stderr := runCLI(t, server.URL, "", "prove", "-hash", hash); code != exitUsage {
+				t.Errorf("prove -hash %s = %d %q, want %d", hash, code, stderr, exitUsage)
+			}
+		}
+	})
+
+	t.Run("report unreachable hosts", func(t *testing.T) {
+		if code, _, _ := runCLI(t, "http://127.0.0.1:1", "", "prove", "-hash", provable.Keccak256Str("x"), "-timeout", "1s"); code != exitError {
+			t.Errorf("exit = %d, want %d", code, exitError)
+		}
+	})
+}
+
+func TestMerkle(t *testing.T) {
+	target := strings.Repeat("aa", 32)
+	sibling := strings.Repeat("bb", 32)
+	root, _ := provable.ComputeMerkleRoot(target, []string{sibling}, 1, 0)
+
+	proofFile := func(t *testing.T, rootHex string) string {
+		path := filepath.Join(t.TempDir(), "proof.json")
+		raw, _ := json.Marshal(provable.MerkleProof{TargetHashHex: target, ProofHashesHex: []string{sibling}, Levels: 1, RootHashHex: rootHex})
+		os.WriteFile(path, raw, 0o600)
+		return path
+	}
+
+	t.Run("verify proofs locally", func(t *testing.T) {
+		if code, stdout, _ := runCLI(t, "", "", "merkle", "verify", "-local", proofFile(t, root)); code != exitOK {
+			t.Errorf("merkle verify = %d %q, want valid", code, stdout)
+		}
+		if code, _, _ := runCLI(t, "", "", "merkle", "verify", "-local", proofFile(t, strings.Repeat("00", 32))); code != exitInvalid {
+			t.Errorf("exit = %d, want %d", code, exitInvalid)
+		}
+	})
+
+	t.Run("verify proofs with Kayros", func(t *testing.T) {
+		server := newFakeKayros(t)
+		client, _ := server.Client()
+		proved, err := client.ProveSingleHash(provable.Keccak256Str("merkle"))
+		if err != nil {
+			t.Fatalf("ProveSingleHash() error = %v", err)
+		}
+		path := filepath.Join(t.TempDir(), "proof.json")
+		if code, _, stderr := runCLI(t, server.URL, "", "merkle", "proof", "-o", path, proved.Data.ComputedHashHex); code != exitOK {
+			t.Fatalf("merkle proof = %d %q", code, stderr)
+		}
+		if code, stdout, stderr := runCLI(t, server.URL, "", "merkle", "verify", path); code != exitOK || !strings.HasPrefix(stdout, "valid") {
+			t.Errorf("merkle verify = %d %q %q, want valid", code, stdout, stderr)
+		}
+	})
+
+	t.Run("get the root from Lightnet", func(t *testing.T) {
+		ln, addr := newFakeLightnet(t)
+		for _, item := range []string{"a", "b", "c"} {
+			if _, err := ln.Append(provable.DataType, provable.Keccak256Str(item)); err != nil {
+				t.Fatalf("Append() error = %v", err)
+			}
+		}
+		client, _ := ln.Client()
+		want, err := client.GetMerkleRoot(context.Background())
+		if err != nil {
+			t.Fatalf("GetMerkleRoot() error = %v", err)
+		}
+
+		code, stdout, stderr := runCLI(t, "", "", "merkle", "root", "-json", "-insecure", "-lightnet", addr)
+		var got provable.MerkleRoot
+		if err := json.Unmarshal([]byte(stdout), &got); err != nil || code != exitOK {
+			t.Fatalf("merkle root = %d %q %q, want JSON", code, stdout, stderr)
+		}
+		if got != *want || got.TotalRecords != 3 {
+			t.Errorf("merkle root = %+v, want %+v", got, *want)
+		}
+	})
+
+	t.Run("require a Lightnet address for the root", func(t *testing.T) {
+		if code, _, _ := runCLI(t, "", "", "merkle", "root"); code != exitUsage {
+			t.Errorf("exit = %d, want %d", code, exitUsage)
+		}
+	})
+
+	t.Run("reject unknown subcommands", func(t *testing.T) {
+		if code, _, _ := runCLI(t, "", "", "merkle", "prune"); code != exitUsage {
+			t.Errorf("exit = %d, want %d", code, exitUsage)
+		}
+	})
+}
+
+func TestRun(t *testing.T) {
+	server := newFakeKayros(t)
+
+	t.Run("take the host from the environment or flag", func(t *testing.T) {
+		code, stdout, _ := runCLI(t, server.URL, "", "stats", "-json")
+		var stats provable.DatabaseStats
+		if err := json.Unmarshal([]byte(stdout), &stats); err != nil || code != exitOK {
+			t.Errorf("stats = %d %q, want JSON", code, stdout)
+		}
+		if code, _, _ := runCLI(t, "http://127.0.0.1:1", "", "stats", "-host", server.URL); code != exitOK {
+			t.Errorf("stats -host = %d, want %d", code, exitOK)
+		}
+	})
+
+	t.Run("report usage errors", func(t *testing.T) {
+		for _, args := range [][]string{{}, {"bogus"}, {"record"}, {"query", "-order", "random"}, {"verify", "-nope", "x"}} {
+			if code, _, _ := runCLI(t, server.URL, "", args...); code != exitUsage {
+				t.Errorf("%v exit = %d, want %d", args, code, exitUsage)
+			}
+		}
+	})
+}
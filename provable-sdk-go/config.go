@@ -21,20 +21,20 @@ const (
 	DataType = "70726f7661626c655f73646b0000000000000000000000000000000000000000"
 )
 
-// GetKayrosURL builds a full Kayros API URL from a route
+// GetKayrosURL builds a full Kayros API URL from a route using DefaultClient
 func GetKayrosURL(route string) string {
-	return KayrosHost + route
+	return DefaultClient.URL(route)
 }
 
-// GetRecordURL returns the URL to view a record on Kayros by its hash
+// GetRecordURL returns the URL to view a record on Kayros by its hash using DefaultClient
 func GetRecordURL(hash string) string {
-	return fmt.Sprintf("%s/api/database/record-by-hash?hash_item=%s", KayrosHost, hash)
+	return DefaultClient.RecordURL(hash)
 }
 
 // ValidateDataType validates that a data type is exactly 32 bytes (64 hex characters)
 func ValidateDataType(dataType string) error {
 	if len(dataType) != 64 {
-		return fmt.Errorf("data_type must be exactly 64 hex characters (32 bytes), got %d characters", len(dataType))
+		return fmt.Errorf("%w: data_type must be exactly 64 hex characters (32 bytes), got %d characters", ErrInvalidDataType, len(dataType))
 	}
 
 	matched, err := regexp.MatchString("^[0-9a-fA-F]{64}$", dataType)
@@ -42,7 +42,7 @@ func ValidateDataType(dataType string) error {
 		return fmt.Errorf("failed to validate data_type: %w", err)
 	}
 	if !matched {
-		return fmt.Errorf("data_type must contain only valid hex characters (0-9, a-f, A-F)")
+		return fmt.Errorf("%w: data_type must contain only valid hex characters (0-9, a-f, A-F)", ErrInvalidDataType)
 	}
 
 	return nil
@@ -0,0 +1,140 @@
+package provable
+
+import (
+	"context"
+	"fmt"
+)
+
+// envelopeOptions holds the settings used to build an envelope
+type envelopeOptions struct {
+	hashAlgorithm    string
+	canonicalization string
+	dataType         []string
+	name             string
+	progress         ProgressFunc
+}
+
+// EnvelopeOption configures NewEnvelope and Seal
+type EnvelopeOption func(*envelopeOptions)
+
+// WithHashAlgorithm sets the registered hash algorithm used and recorded in
+// the envelope (defaults to HashAlgorithmKeccak256)
+func WithHashAlgorithm(algorithm string) EnvelopeOption {
+	return func(o *envelopeOptions) {
+		o.hashAlgorithm = algorithm
+	}
+}
+
+// WithCanonicalization sets how non-string data is serialized before hashing
+// (defaults to CanonicalizationJCS; pass CanonicalizationNone for
+// encoding/json output)
+func WithCanonicalization(mode string) EnvelopeOption {
+	return func(o *envelopeOptions) {
+		o.canonicalization = mode
+	}
+}
+
+// WithEnvelopeDataType sets the data type Seal proves the hash under
+// (defaults to the client's data type)
+func WithEnvelopeDataType(dataType string) EnvelopeOption {
+	return func(o *envelopeOptions) {
+		o.dataType = []string{dataType}
+	}
+}
+
+// NewEnvelope wraps data in an envelope carrying its hash, without proving it
+// Data is stored as generic JSON values and serialized exactly as Verify will
+// serialize it, so the envelope's hash matches before and after a JSON round
+// trip.
+func NewEnvelope(data interface{}, opts ...EnvelopeOption) (*KayrosEnvelope, error) {
+	return newEnvelope(data, applyEnvelopeOptions(opts))
+}
+
+func applyEnvelopeOptions(opts []EnvelopeOption) envelopeOptions {
+	o := envelopeOptions{
+		hashAlgorithm:    HashAlgorithmKeccak256,
+		canonicalization: CanonicalizationJCS,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+func newEnvelope(data interface{}, o envelopeOptions) (*KayrosEnvelope, error) {
+	hash, err := LookupHashAlgorithm(o.hashAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	// JCS envelopes hold the normalized data, so they verify the same in
+	// memory and once archived as JSON
+	if o.canonicalization == CanonicalizationJCS {
+		data, err = normalizeEnvelopeData(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize data: %w", err)
+		}
+	}
+	dataString, err := envelopeDataString(data, o.canonicalization)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize data: %w", err)
+	}
+
+	return &KayrosEnvelope{
+		Data: data,
+		Kayros: KayrosMetadata{
+			Hash:             hash([]byte(dataString)),
+			HashAlgorithm:    o.hashAlgorithm,
+			Canonicalization: o.canonicalization,
+		},
+	}, nil
+}
+
+// Seal hashes data, proves the hash via Kayros and returns a complete
+// envelope that Verify accepts
+func (c *Client) Seal(data interface{}, opts ...EnvelopeOption) (*KayrosEnvelope, error) {
+	return c.SealContext(context.Background(), data, opts...)
+}
+
+// SealContext is like Seal but honors ctx cancellation and deadlines
+func (c *Client) SealContext(ctx context.Context, data interface{}, opts ...EnvelopeOption) (*KayrosEnvelope, error) {
+	o := applyEnvelopeOptions(opts)
+	envelope, err := newEnvelope(data, o)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := c.timestampEnvelope(ctx, envelope, o); err != nil {
+		return nil, err
+	}
+	return envelope, nil
+}
+
+// timestampEnvelope proves the envelope's hash and records the proof in it
+func (c *Client) timestampEnvelope(ctx context.Context, envelope *KayrosEnvelope, o envelopeOptions) (*ProveSingleHashResponse, error) {
+	proof, err := c.ProveSingleHashContext(ctx, envelope.Kayros.Hash, o.dataType...)
+	if err != nil {
+		return nil, err
+	}
+
+	envelope.Kayros.DataType = c.dataType
+	if len(o.dataType) > 0 && o.dataType[0] != "" {
+		envelope.Kayros.DataType = o.dataType[0]
+	}
+	envelope.Kayros.Timestamp = &KayrosTimestamp{
+		Service:  c.URL(ProveSingleHashRoute),
+		Response: proof,
+	}
+	return proof, nil
+}
+
+// Seal hashes data, proves the hash via Kayros and returns a complete
+// envelope that Verify accepts, using DefaultClient
+func Seal(data interface{}, opts ...EnvelopeOption) (*KayrosEnvelope, error) {
+	return DefaultClient.Seal(data, opts...)
+}
+
+// SealContext is like Seal but honors ctx cancellation and deadlines
+func SealContext(ctx context.Context, data interface{}, opts ...EnvelopeOption) (*KayrosEnvelope, error) {
+	return DefaultClient.SealContext(ctx, data, opts...)
+}
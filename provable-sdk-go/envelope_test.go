@@ -0,0 +1,220 @@
+package provable
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// newSealTestClient returns a client backed by a fake Kayros that stores
+// every proved hash and serves it back by computed hash
+func newSealTestClient(t *testing.T) (*Client, *[]map[string]string) {
+	t.Helper()
+	var mu sync.Mutex
+	records := make(map[string]string)
+	var requests []map[string]string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.URL.Path {
+		case ProveSingleHashRoute:
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			requests = append(requests, body)
+			computed := Keccak256Str("computed:" + body["data_item"])
+			records[computed] = body["data_item"]
+			json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]string{"computed_hash_hex": computed}})
+		case GetRecordByHashRoute:
+			item, ok := records[r.URL.Query().Get("hash_item")]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]string{"data_item_hex": item}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := NewClient(WithBaseURL(server.URL), WithRetryPolicy(NoRetry))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	return c, &requests
+}
+
+func TestNewEnvelope(t *testing.T) {
+	t.Run("record hash algorithm and canonicalization", func(t *testing.T) {
+		envelope, err := NewEnvelope(map[string]interface{}{"b": 1, "a": 2})
+		if err != nil {
+			t.Fatalf("NewEnvelope() error = %v", err)
+		}
+		if envelope.Kayros.Hash != Keccak256Str(`{"a":2,"b":1}`) {
+			t.Errorf("Hash = %v, want hash of canonical JSON", envelope.Kayros.Hash)
+		}
+		if envelope.Kayros.HashAlgorithm != HashAlgorithmKeccak256 || envelope.Kayros.Canonicalization != CanonicalizationJCS {
+			t.Errorf("Kayros = %+v", envelope.Kayros)
+		}
+		if envelope.Kayros.Timestamp != nil {
+			t.Error("NewEnvelope() should not prove the hash")
+		}
+	})
+
+	t.Run("round-trip through Verify", func(t *testing.T) {
+		type payload struct {
+			Note  string  `json:"note"`
+			Value float64 `json:"value"`
+		}
+		for _, data := range []interface{}{"plain text", payload{Note: "<b>", Value: 1e21}, []int{1, 2, 3}} {
+			for _, mode := range []string{CanonicalizationJCS, CanonicalizationNone} {
+				envelope, err := NewEnvelope(data, WithCanonicalization(mode))
+				if err != nil {
+					t.Fatalf("NewEnvelope(%v) error = %v", data, err)
+				}
+				if result := Verify(envelope); !result.Valid {
+					t.Errorf("Verify(NewEnvelope(%v, %q)) = %+v, want valid", data, mode, result)
+				}
+			}
+		}
+	})
+
+	t.Run("reject unsupported options", func(t *testing.T) {
+		if _, err := NewEnvelope("x", WithHashAlgorithm("md5")); !errors.Is(err, ErrUnsupportedHashAlgorithm) {
+			t.Errorf("NewEnvelope() error = %v, want ErrUnsupportedHashAlgorithm", err)
+		}
+		if _, err := NewEnvelope(1, WithCanonicalization("c14n")); !errors.Is(err, ErrUnsupportedCanonicalization) {
+			t.Errorf("NewEnvelope() error = %v, want ErrUnsupportedCanonicalization", err)
+		}
+	})
+}
+
+func TestSeal(t *testing.T) {
+	data := map[string]interface{}{"message": "Hello, Provable!", "n": 42}
+
+	t.Run("prove and fill in timestamp", func(t *testing.T) {
+		c, requests := newSealTestClient(t)
+		envelope, err := c.Seal(data)
+		if err != nil {
+			t.Fatalf("Seal() error = %v", err)
+		}
+		if len(*requests) != 1 || (*requests)[0]["data_item"] != envelope.Kayros.Hash {
+			t.Errorf("prove requests = %v, want one for %s", *requests, envelope.Kayros.Hash)
+		}
+		ts := envelope.Kayros.Timestamp
+		if ts == nil {
+			t.Fatal("Timestamp = nil")
+		}
+		if ts.Service != c.URL(ProveSingleHashRoute) {
+			t.Errorf("Service = %v, want %v", ts.Service, c.URL(ProveSingleHashRoute))
+		}
+		if _, ok := ts.Response.(*ProveSingleHashResponse); !ok {
+			t.Errorf("Response = %T, want *ProveSingleHashResponse", ts.Response)
+		}
+	})
+
+	t.Run("round-trip through Verify", func(t *testing.T) {
+		c, _ := newSealTestClient(t)
+		envelope, err := c.Seal(data)
+		if err != nil {
+			t.Fatalf("Seal() error = %v", err)
+		}
+		if result := c.Verify(envelope); !result.Valid {
+			t.Errorf("Verify() = %+v, want valid", result)
+		}
+	})
+
+	t.Run("round-trip through JSON and Verify", func(t *testing.T) {
+		c, _ := newSealTestClient(t)
+		envelope, err := c.Seal(data)
+		if err != nil {
+			t.Fatalf("Seal() error = %v", err)
+		}
+		raw, err := json.Marshal(envelope)
+		if err != nil {
+			t.Fatalf("json.Marshal() error = %v", err)
+		}
+		var decoded KayrosEnvelope
+		if err := json.Unmarshal(raw, &decoded); err != nil {
+			t.Fatalf("json.Unmarshal() error = %v", err)
+		}
+		if result := c.Verify(&decoded); !result.Valid {
+			t.Errorf("Verify() = %+v, want valid", result)
+		}
+	})
+
+	t.Run("round-trip data that changes type through JSON", func(t *testing.T) {
+		type label string
+		type payload struct {
+			Z string `json:"z"`
+			A int    `json:"a"`
+		}
+		c, _ := newSealTestClient(t)
+		for _, data := range []interface{}{
+			[]byte("raw bytes"),
+			label("named string"),
+			json.RawMessage(`"raw JSON string"`),
+			payload{Z: "fields out of key order", A: 1},
+		} {
+			envelope, err := c.Seal(data)
+			if err != nil {
+				t.Fatalf("Seal(%T) error = %v", data, err)
+			}
+			if result := c.Verify(envelope); !result.Valid {
+				t.Errorf("Verify(Seal(%T)) = %+v, want valid", data, result)
+			}
+			raw, _ := json.Marshal(envelope)
+			var decoded KayrosEnvelope
+			if err := json.Unmarshal(raw, &decoded); err != nil {
+				t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+			if result := c.Verify(&decoded); !result.Valid {
+				t.Errorf("Verify(JSON round trip of Seal(%T)) = %+v, want valid", data, result)
+			}
+		}
+	})
+
+	t.Run("keep struct field order without canonicalization", func(t *testing.T) {
+		type payload struct {
+			Z string `json:"z"`
+			A int    `json:"a"`
+		}
+		data := payload{Z: "fields out of key order", A: 1}
+		c, _ := newSealTestClient(t)
+		envelope, err := c.Seal(data, WithCanonicalization(CanonicalizationNone))
+		if err != nil {
+			t.Fatalf("Seal() error = %v", err)
+		}
+		if envelope.Data != data {
+			t.Errorf("Data = %#v, want %#v", envelope.Data, data)
+		}
+		if want := HashStr(`{"z":"fields out of key order","a":1}`); envelope.Kayros.Hash != want {
+			t.Errorf("Hash = %s, want %s", envelope.Kayros.Hash, want)
+		}
+		if result := c.Verify(envelope); !result.Valid {
+			t.Errorf("Verify() = %+v, want valid", result)
+		}
+	})
+
+	t.Run("pass data type through", func(t *testing.T) {
+		c, requests := newSealTestClient(t)
+		customDataType := "abababababababababababababababababababababababababababababababab"
+		if _, err := c.Seal(data, WithEnvelopeDataType(customDataType)); err != nil {
+			t.Fatalf("Seal() error = %v", err)
+		}
+		if (*requests)[0]["data_type"] != customDataType {
+			t.Errorf("data_type = %v, want %v", (*requests)[0]["data_type"], customDataType)
+		}
+	})
+
+	t.Run("return prove errors", func(t *testing.T) {
+		c, _ := newSealTestClient(t)
+		if _, err := c.Seal(data, WithEnvelopeDataType("short")); !errors.Is(err, ErrInvalidDataType) {
+			t.Errorf("Seal() error = %v, want ErrInvalidDataType", err)
+		}
+	})
+}
@@ -0,0 +1,164 @@
+package provable
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Sentinel errors, usable with errors.Is
+var (
+	// ErrNotFound matches an *APIError with status 404
+	ErrNotFound = errors.New("not found")
+
+	// ErrRateLimited matches an *APIError with status 429
+	ErrRateLimited = errors.New("rate limited")
+
+	// ErrRequestFailed matches an *APIError for a 200 response whose body
+	// reports success=false
+	ErrRequestFailed = errors.New("request failed")
+
+	// ErrInvalidDataType is returned when a data type fails ValidateDataType
+	ErrInvalidDataType = errors.New("invalid data type")
+
+	// ErrInvalidHash is returned when a hash isn't 64 hex characters (32 bytes)
+	ErrInvalidHash = errors.New("invalid hash")
+
+	// ErrBatchProverClosed is returned when submitting to a closed BatchProver
+	ErrBatchProverClosed = errors.New("batch prover closed")
+
+	// ErrOutboxClosed is returned when using a closed Outbox
+	ErrOutboxClosed = errors.New("outbox closed")
+
+	// ErrInvalidMerkleProof is returned when a Merkle proof is structurally invalid
+	ErrInvalidMerkleProof = errors.New("invalid merkle proof")
+
+	// ErrUnsupportedCanonicalization is returned for an unknown
+	// KayrosMetadata.Canonicalization value
+	ErrUnsupportedCanonicalization = errors.New("unsupported canonicalization")
+
+	// ErrUnsupportedHashAlgorithm is returned for an unknown hash algorithm name
+	ErrUnsupportedHashAlgorithm = errors.New("unsupported hash algorithm")
+
+	// ErrDecodeResponse is returned when a Kayros response body can't be decoded
+	ErrDecodeResponse = errors.New("failed to decode response")
+
+	// ErrReceiptNotFound is returned when a ReceiptStore has no matching receipt
+	ErrReceiptNotFound = errors.New("receipt not found")
+)
+
+// RequestIDHeader is the response header carrying the Kayros request ID
+const RequestIDHeader = "X-Request-Id"
+
+// APIError is returned when Kayros answers with a non-200 status, or with
+// a body whose success is false
+// In the second case StatusCode is 0, since the HTTP exchange succeeded, and
+// the error matches ErrRequestFailed.
+type APIError struct {
+	StatusCode int    // HTTP status code, 0 when the body reported the failure
+	Method     string // HTTP method of the request
+	Route      string // API route, without query string
+	RequestID  string // value of the X-Request-Id response header, if any
+	Message    string // APIResponse.Error or APIResponse.Message from the body, if any
+	Body       string // raw response body
+}
+
+// Error implements the error interface
+func (e *APIError) Error() string {
+	detail := e.Message
+	if detail == "" {
+		detail = e.Body
+	}
+	msg := fmt.Sprintf("kayros API error: %d %s - %s", e.StatusCode, http.StatusText(e.StatusCode), detail)
+	if e.StatusCode == 0 {
+		msg = "kayros API error: request failed - " + detail
+	}
+	if e.RequestID != "" {
+		msg += fmt.Sprintf(" (request id %s)", e.RequestID)
+	}
+	return msg
+}
+
+// Is reports whether the error matches ErrNotFound, ErrRateLimited or
+// ErrRequestFailed
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrRequestFailed:
+		return e.StatusCode == 0
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	}
+	return false
+}
+
+// LightnetError is returned when a Lightnet RPC fails with a gRPC status or
+// answers with success=false
+type LightnetError struct {
+	Method  string     // RPC name, e.g. "SubmitHash"
+	Code    codes.Code // gRPC status code, codes.Unknown for success=false
+	Message string     // message returned by Lightnet
+
+	err error // the gRPC status error, if any
+}
+
+// Error implements the error interface
+func (e *LightnetError) Error() string {
+	return fmt.Sprintf("lightnet %s failed: %s", e.Method, e.Message)
+}
+
+// Unwrap returns the gRPC status error, so status.Code still works on it
+func (e *LightnetError) Unwrap() error {
+	return e.err
+}
+
+// Is reports whether the error matches ErrNotFound, i.e. Lightnet answered
+// with codes.NotFound
+func (e *LightnetError) Is(target error) bool {
+	return target == ErrNotFound && e.Code == codes.NotFound
+}
+
+// newLightnetError wraps an error returned by a Lightnet RPC, turning gRPC
+// status errors into a *LightnetError
+func newLightnetError(method string, err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return fmt.Errorf("lightnet %s: %w", method, err)
+	}
+	return &LightnetError{Method: method, Code: st.Code(), Message: st.Message(), err: err}
+}
+
+// newAPIError builds an APIError from a response, pulling the server's
+// error or message out of the body when it's an APIResponse
+func newAPIError(resp *http.Response, method, route string, body []byte) *APIError {
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Method:     method,
+		Route:      stripQuery(route),
+		RequestID:  resp.Header.Get(RequestIDHeader),
+		Body:       string(body),
+	}
+
+	var parsed APIResponse
+	if err := json.Unmarshal(body, &parsed); err == nil {
+		apiErr.Message = parsed.Error
+		if apiErr.Message == "" {
+			apiErr.Message = parsed.Message
+		}
+	}
+
+	return apiErr
+}
+
+func stripQuery(route string) string {
+	if i := strings.IndexByte(route, '?'); i >= 0 {
+		return route[:i]
+	}
+	return route
+}
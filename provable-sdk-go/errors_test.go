@@ -0,0 +1,260 @@
+package provable
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newErrorTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	c, err := NewClient(WithBaseURL(server.URL), WithRetryPolicy(NoRetry))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	return c
+}
+
+func TestAPIError(t *testing.T) {
+	t.Run("match ErrNotFound for 404", func(t *testing.T) {
+		c := newErrorTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(RequestIDHeader, "req-123")
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"success":false,"error":"record not found"}`))
+		})
+
+		_, err := c.GetRecordByHash("abc")
+		if !errors.Is(err, ErrNotFound) {
+			t.Fatalf("GetRecordByHash() error = %v, want ErrNotFound", err)
+		}
+		if errors.Is(err, ErrRateLimited) {
+			t.Error("404 error matched ErrRateLimited")
+		}
+
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("GetRecordByHash() error = %T, want *APIError", err)
+		}
+		if apiErr.StatusCode != http.StatusNotFound {
+			t.Errorf("StatusCode = %v, want 404", apiErr.StatusCode)
+		}
+		if apiErr.Route != GetRecordByHashRoute {
+			t.Errorf("Route = %v, want %v", apiErr.Route, GetRecordByHashRoute)
+		}
+		if apiErr.Method != http.MethodGet {
+			t.Errorf("Method = %v, want GET", apiErr.Method)
+		}
+		if apiErr.RequestID != "req-123" {
+			t.Errorf("RequestID = %v, want req-123", apiErr.RequestID)
+		}
+		if apiErr.Message != "record not found" {
+			t.Errorf("Message = %v, want record not found", apiErr.Message)
+		}
+		if !strings.Contains(apiErr.Body, "record not found") {
+			t.Errorf("Body = %v", apiErr.Body)
+		}
+	})
+
+	t.Run("match ErrRateLimited for 429", func(t *testing.T) {
+		c := newErrorTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "slow down", http.StatusTooManyRequests)
+		})
+
+		_, err := c.QueryHashes(DatabaseQuery{Limit: 1})
+		if !errors.Is(err, ErrRateLimited) {
+			t.Fatalf("QueryHashes() error = %v, want ErrRateLimited", err)
+		}
+		if errors.Is(err, ErrNotFound) {
+			t.Error("429 error matched ErrNotFound")
+		}
+	})
+
+	t.Run("fall back to message field", func(t *testing.T) {
+		c := newErrorTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"success":false,"message":"bad hash"}`))
+		})
+
+		_, err := c.ProveSingleHash("xyz")
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("ProveSingleHash() error = %v, want *APIError", err)
+		}
+		if apiErr.Message != "bad hash" {
+			t.Errorf("Message = %v, want bad hash", apiErr.Message)
+		}
+		if !strings.Contains(err.Error(), "bad hash") {
+			t.Errorf("Error() = %v, want message", err.Error())
+		}
+	})
+
+	t.Run("report unsuccessful APIResponse with 200 status", func(t *testing.T) {
+		c := newErrorTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"success":false,"error":"table does not exist"}`))
+		})
+
+		_, err := c.GetTableSchema("missing")
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("GetTableSchema() error = %v, want *APIError", err)
+		}
+		if apiErr.Message != "table does not exist" {
+			t.Errorf("Message = %v, want table does not exist", apiErr.Message)
+		}
+		if apiErr.Route != "/api/database/schema" {
+			t.Errorf("Route = %v, want /api/database/schema", apiErr.Route)
+		}
+		if apiErr.StatusCode != 0 || !errors.Is(err, ErrRequestFailed) {
+			t.Errorf("StatusCode = %d, want 0 and ErrRequestFailed", apiErr.StatusCode)
+		}
+		if errors.Is(err, ErrNotFound) || !strings.Contains(err.Error(), "request failed - table does not exist") {
+			t.Errorf("Error() = %v, want the request failure", err.Error())
+		}
+	})
+
+	t.Run("report unsuccessful APIResponse with only a message", func(t *testing.T) {
+		c := newErrorTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"success":false,"message":"table does not exist"}`))
+		})
+
+		_, err := c.GetTableSchema("missing")
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("GetTableSchema() error = %v, want *APIError", err)
+		}
+		if apiErr.Message != "table does not exist" || !errors.Is(err, ErrRequestFailed) {
+			t.Errorf("GetTableSchema() error = %+v, want ErrRequestFailed with the message", apiErr)
+		}
+	})
+
+	t.Run("report unsuccessful bodies of responses without a success field", func(t *testing.T) {
+		c := newErrorTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"success":false,"error":"hash rejected"}`))
+		})
+
+		_, err := c.ProveSingleHash(Keccak256Str("rejected"))
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || apiErr.Message != "hash rejected" || !errors.Is(err, ErrRequestFailed) {
+			t.Errorf("ProveSingleHash() error = %v, want ErrRequestFailed with the message", err)
+		}
+	})
+}
+
+func TestDecodeError(t *testing.T) {
+	c := newErrorTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`not json`))
+	})
+
+	_, err := c.GetRecordByHash("abc")
+	if !errors.Is(err, ErrDecodeResponse) {
+		t.Fatalf("GetRecordByHash() error = %v, want ErrDecodeResponse", err)
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		t.Error("decode failure should not be an *APIError")
+	}
+}
+
+func TestInvalidDataTypeError(t *testing.T) {
+	t.Run("wrap ErrInvalidDataType from ValidateDataType", func(t *testing.T) {
+		if err := ValidateDataType("short"); !errors.Is(err, ErrInvalidDataType) {
+			t.Errorf("ValidateDataType() error = %v, want ErrInvalidDataType", err)
+		}
+		if err := ValidateDataType("gggg" + strings.Repeat("0", 60)); !errors.Is(err, ErrInvalidDataType) {
+			t.Errorf("ValidateDataType() error = %v, want ErrInvalidDataType", err)
+		}
+	})
+
+	t.Run("return ErrInvalidDataType from ProveSingleHash", func(t *testing.T) {
+		_, err := ProveSingleHash("abc", "short")
+		if !errors.Is(err, ErrInvalidDataType) {
+			t.Errorf("ProveSingleHash() error = %v, want ErrInvalidDataType", err)
+		}
+	})
+}
+
+func TestVerifyResultErr(t *testing.T) {
+	c := newErrorTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
+	})
+
+	data := "hello"
+	result := c.Verify(&KayrosEnvelope{
+		Data: data,
+		Kayros: KayrosMetadata{
+			Hash: Keccak256Str(data),
+			Timestamp: &KayrosTimestamp{
+				Response: &ProveSingleHashResponse{Data: ProveSingleHashResponseData{ComputedHashHex: "feed"}},
+			},
+		},
+	})
+	if result.Valid {
+		t.Fatal("Verify() valid = true, want false")
+	}
+	if !errors.Is(result.Err, ErrNotFound) {
+		t.Errorf("Verify() Err = %v, want ErrNotFound", result.Err)
+	}
+}
+
+func TestVerifyErrorCode(t *testing.T) {
+	data := "hello"
+	hash := Keccak256Str(data)
+	c := newErrorTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("hash_item") {
+		case "bad-json":
+			w.Write([]byte("not json"))
+		case "empty":
+			w.Write([]byte(`{"data":{}}`))
+		case "other":
+			w.Write([]byte(`{"data":{"data_item_hex":"` + Keccak256Str("other") + `"}}`))
+		default:
+			http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
+		}
+	})
+	timestamped := func(recordHash string) *KayrosEnvelope {
+		return &KayrosEnvelope{Data: data, Kayros: KayrosMetadata{
+			Hash:      hash,
+			Timestamp: &KayrosTimestamp{Response: ProveSingleHashResponse{Data: ProveSingleHashResponseData{ComputedHashHex: recordHash}}},
+		}}
+	}
+
+	tests := []struct {
+		name     string
+		envelope *KayrosEnvelope
+		opts     VerifyOptions
+		want     VerifyErrorCode
+	}{
+		{"missing hash", &KayrosEnvelope{Data: data}, VerifyOptions{}, VerifyErrorMissingHash},
+		{"unknown canonicalization", &KayrosEnvelope{Data: map[string]int{}, Kayros: KayrosMetadata{Hash: hash, Canonicalization: "xml"}}, VerifyOptions{}, VerifyErrorSerializationFailed},
+		{"unknown hash algorithm", &KayrosEnvelope{Data: data, Kayros: KayrosMetadata{Hash: hash, HashAlgorithm: "md5"}}, VerifyOptions{}, VerifyErrorUnsupportedHashAlgorithm},
+		{"hash mismatch", &KayrosEnvelope{Data: "changed", Kayros: KayrosMetadata{Hash: hash}}, VerifyOptions{}, VerifyErrorHashMismatch},
+		{"malformed timestamp", &KayrosEnvelope{Data: data, Kayros: KayrosMetadata{Hash: hash, Timestamp: &KayrosTimestamp{Response: "x"}}}, VerifyOptions{}, VerifyErrorMalformedTimestamp},
+		{"remote unavailable", timestamped("missing"), VerifyOptions{}, VerifyErrorRemoteUnavailable},
+		{"undecodable remote record", timestamped("bad-json"), VerifyOptions{}, VerifyErrorMalformedRemoteRecord},
+		{"empty remote record", timestamped("empty"), VerifyOptions{}, VerifyErrorMalformedRemoteRecord},
+		{"remote mismatch", timestamped("other"), VerifyOptions{}, VerifyErrorRemoteMismatch},
+		{"missing evidence", timestamped("other"), VerifyOptions{Offline: true}, VerifyErrorMissingEvidence},
+		{"policy violation", &KayrosEnvelope{Data: data, Kayros: KayrosMetadata{Hash: hash}}, VerifyOptions{Policy: &VerifyPolicy{RequiredHashAlgorithm: HashAlgorithmSHA256}}, VerifyErrorPolicyViolation},
+	}
+	for _, tt := range tests {
+		t.Run("report "+tt.name, func(t *testing.T) {
+			result := c.VerifyWithOptions(context.Background(), tt.envelope, tt.opts)
+			if result.Valid || result.Code != tt.want {
+				t.Errorf("VerifyWithOptions() Code = %q (%s), want %q", result.Code, result.Error, tt.want)
+			}
+		})
+	}
+
+	t.Run("leave code empty when valid", func(t *testing.T) {
+		result := c.Verify(&KayrosEnvelope{Data: data, Kayros: KayrosMetadata{Hash: hash}})
+		if !result.Valid || result.Code != "" {
+			t.Errorf("Verify() = %+v, want valid without code", result)
+		}
+	})
+}
@@ -0,0 +1,73 @@
+package provable
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// EnrichEnvelope fetches the Kayros record, with its prev hash, and Merkle
+// proof for a timestamped envelope and stores them in
+// envelope.Kayros.Evidence, so it can later be verified with
+// VerifyOptions{Offline: true}
+func (c *Client) EnrichEnvelope(envelope *KayrosEnvelope) error {
+	return c.EnrichEnvelopeContext(context.Background(), envelope)
+}
+
+// EnrichEnvelopeContext is like EnrichEnvelope but honors ctx cancellation and deadlines
+func (c *Client) EnrichEnvelopeContext(ctx context.Context, envelope *KayrosEnvelope) error {
+	if envelope.Kayros.Timestamp == nil {
+		return errors.New("envelope has no timestamp to collect evidence for")
+	}
+	recordHash, errMsg := timestampRecordHash(envelope.Kayros.Timestamp)
+	if errMsg != "" {
+		return errors.New(errMsg)
+	}
+
+	record, err := c.getRecordByHash(ctx, recordHash, c.retryPolicy.withStatus(http.StatusNotFound))
+	if err != nil {
+		return fmt.Errorf("fetch record: %w", err)
+	}
+	uuid := record.Data.UUIDHex
+	if uuid == "" {
+		var proved ProveSingleHashResponse
+		if err := convertJSON(envelope.Kayros.Timestamp.Response, &proved); err == nil {
+			uuid = proved.Data.TimeuuidHex
+		}
+	}
+	if uuid == "" {
+		return errors.New("record has no UUID to fetch the chain record by")
+	}
+	chainRecord, err := c.GetRecordWithPrevHashContext(ctx, uuid)
+	if err != nil {
+		return fmt.Errorf("fetch chain record: %w", err)
+	}
+	proof, err := c.GenerateMerkleProofContext(ctx, GenerateMerkleProofRequest{HashItem: recordHash})
+	if err != nil {
+		return fmt.Errorf("generate Merkle proof: %w", err)
+	}
+	local := *proof
+	if local.TargetHashHex == "" {
+		local.TargetHashHex = recordHash
+	}
+	if result, err := VerifyMerkleProofLocal(local); err != nil {
+		return fmt.Errorf("check Merkle proof: %w", err)
+	} else if !result.Valid {
+		return fmt.Errorf("%w: Kayros proof does not verify locally: %s", ErrInvalidMerkleProof, result.Message)
+	}
+
+	envelope.Kayros.Evidence = &KayrosEvidence{Record: record, ChainRecord: chainRecord, MerkleProof: proof}
+	return nil
+}
+
+// EnrichEnvelope stores offline verification evidence in envelope using
+// DefaultClient
+func EnrichEnvelope(envelope *KayrosEnvelope) error {
+	return DefaultClient.EnrichEnvelope(envelope)
+}
+
+// EnrichEnvelopeContext is like EnrichEnvelope but honors ctx cancellation and deadlines
+func EnrichEnvelopeContext(ctx context.Context, envelope *KayrosEnvelope) error {
+	return DefaultClient.EnrichEnvelopeContext(ctx, envelope)
+}
@@ -0,0 +1,340 @@
+package provable
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zeebo/blake3"
+)
+
+var evidenceTestSibling = strings.Repeat("11", 32)
+
+// evidenceTestRoot is the root of a one-level tree with recordHash on the left
+// It is hashed here rather than with merkleParent, so the fake doesn't agree
+// with the code under test by construction.
+func evidenceTestRoot(recordHash string) string {
+	left, _ := hex.DecodeString(recordHash)
+	right, _ := hex.DecodeString(evidenceTestSibling)
+	sum := blake3.Sum256(append(left, right...))
+	return hex.EncodeToString(sum[:])
+}
+
+// newEvidenceTestClient returns a client backed by a fake Kayros that proves
+// hashes into a BLAKE3 hash chain, serves its records and generates
+// one-level Merkle proofs, and a counter of the requests it received
+// Like a verifier that only recomputes, it accepts back a proof unchanged or
+// a zero-level proof whose root is its target, whatever the root.
+// Proving the same data item under the same data type again returns the
+// existing record.
+func newEvidenceTestClient(t *testing.T) (*Client, *atomic.Int32) {
+	t.Helper()
+	var mu sync.Mutex
+	byHash := make(map[string]*DatabaseRecord)
+	byUUID := make(map[string]*DatabaseRecord)
+	byItem := make(map[[2]string]*DatabaseRecord)
+	prevHash := strings.Repeat("00", 32)
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.URL.Path {
+		case ProveSingleHashRoute:
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			key := [2]string{body["data_type"], body["data_item"]}
+			record, ok := byItem[key]
+			if !ok {
+				record = &DatabaseRecord{
+					DataType:    body["data_type"],
+					DataItemHex: body["data_item"],
+					UUIDHex:     fmt.Sprintf("%032x", len(byHash)+1),
+					PrevHashHex: prevHash,
+					HashType:    RecordHashBLAKE3,
+					Timestamp:   time.Now().UTC().Format(time.RFC3339Nano),
+				}
+				result, err := VerifyHashLocal(RecordHashRequest(*record))
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+				record.HashItemHex = result.ComputedHash
+				prevHash = record.HashItemHex
+				byHash[record.HashItemHex], byUUID[record.UUIDHex], byItem[key] = record, record, record
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]string{
+				"computed_hash_hex": record.HashItemHex,
+				"timeuuid_hex":      record.UUIDHex,
+			}})
+		case GetRecordByHashRoute:
+			record, ok := byHash[r.URL.Query().Get("hash_item")]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"data": GetRecordResponseData{
+				DataItemHex: record.DataItemHex,
+				DataType:    record.DataType,
+				Timestamp:   record.Timestamp,
+			}})
+		case "/api/database/record-with-prev":
+			record, ok := byUUID[r.URL.Query().Get("uuid")]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": record})
+		case "/api/merkle/generate-proof":
+			var req GenerateMerkleProofRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": MerkleProof{
+				TargetHashHex:  req.HashItem,
+				RootHashHex:    evidenceTestRoot(req.HashItem),
+				ProofHashesHex: []string{evidenceTestSibling},
+				Levels:         1,
+			}})
+		case "/api/merkle/verify-proof":
+			var req VerifyMerkleProofRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			var computed string
+			switch {
+			case req.Levels == 0:
+				computed = req.TargetHashHex
+			case req.Levels == 1 && req.Position == 0 && len(req.ProofHashesHex) > 0 && req.ProofHashesHex[0] == evidenceTestSibling:
+				computed = evidenceTestRoot(req.TargetHashHex)
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": MerkleProofVerificationResult{
+				Valid:           computed != "" && req.RootHashHex == computed,
+				ComputedRootHex: computed,
+				TargetHashHex:   req.TargetHashHex,
+			}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := NewClient(WithBaseURL(server.URL), WithRetryPolicy(NoRetry))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	return c, &requests
+}
+
+// enrichedTestEnvelope seals and enriches data, then round-trips the envelope
+// through JSON as if it had been archived
+func enrichedTestEnvelope(t *testing.T, c *Client, data interface{}, opts ...EnvelopeOption) *KayrosEnvelope {
+	t.Helper()
+	envelope, err := c.Seal(data, opts...)
+	if err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+	if err := c.EnrichEnvelope(envelope); err != nil {
+		t.Fatalf("EnrichEnvelope() error = %v", err)
+	}
+	raw, _ := json.Marshal(envelope)
+	var archived KayrosEnvelope
+	if err := json.Unmarshal(raw, &archived); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	return &archived
+}
+
+// anchoredOffline returns offline options trusting the root of the
+// envelope's evidence, as if it had been published out of band
+func anchoredOffline(envelope *KayrosEnvelope) VerifyOptions {
+	return VerifyOptions{Offline: true, TrustedRoots: []string{envelope.Kayros.Evidence.MerkleProof.RootHashHex}}
+}
+
+func TestVerifyOffline(t *testing.T) {
+	ctx := context.Background()
+	c, requests := newEvidenceTestClient(t)
+
+	t.Run("verify from embedded evidence without requests", func(t *testing.T) {
+		envelope := enrichedTestEnvelope(t, c, map[string]string{"a": "b"})
+		before := requests.Load()
+		result := c.VerifyWithOptions(ctx, envelope, anchoredOffline(envelope))
+		if !result.Valid || !result.Details.RemoteMatch || !result.Details.MerkleMatch {
+			t.Errorf("VerifyWithOptions() = %+v, want valid with Merkle match", result)
+		}
+		if n := requests.Load() - before; n != 0 {
+			t.Errorf("requests = %d, want 0", n)
+		}
+	})
+
+	t.Run("check against trusted roots", func(t *testing.T) {
+		envelope := enrichedTestEnvelope(t, c, "trusted")
+		root := envelope.Kayros.Evidence.MerkleProof.RootHashHex
+		if result := c.VerifyWithOptions(ctx, envelope, VerifyOptions{Offline: true, TrustedRoots: []string{root}}); !result.Valid {
+			t.Errorf("VerifyWithOptions() = %+v, want valid", result)
+		}
+		result := c.VerifyWithOptions(ctx, envelope, VerifyOptions{Offline: true, TrustedRoots: []string{strings.Repeat("00", 32)}})
+		if result.Valid || !strings.Contains(result.Error, "not trusted") {
+			t.Errorf("VerifyWithOptions() = %+v, want untrusted root", result)
+		}
+	})
+
+	t.Run("reject unanchored evidence", func(t *testing.T) {
+		envelope := enrichedTestEnvelope(t, c, "unanchored")
+		result := c.VerifyWithOptions(ctx, envelope, VerifyOptions{Offline: true})
+		if result.Valid || result.Code != VerifyErrorUnanchoredProof || result.Details.MerkleMatch {
+			t.Errorf("VerifyWithOptions() = %+v, want unanchored proof without a Merkle match", result)
+		}
+
+		envelope.Kayros.Evidence.MerkleProof = nil
+		if result := c.VerifyWithOptions(ctx, envelope, VerifyOptions{Offline: true}); result.Valid || result.Code != VerifyErrorUnanchoredProof {
+			t.Errorf("VerifyWithOptions() = %+v, want unanchored proof", result)
+		}
+		result = c.VerifyWithOptions(ctx, envelope, VerifyOptions{Offline: true, TrustedRoots: []string{"ff"}})
+		if result.Valid || !strings.Contains(result.Error, "missing evidence proof") {
+			t.Errorf("VerifyWithOptions() = %+v, want missing proof", result)
+		}
+	})
+
+	t.Run("reject missing evidence", func(t *testing.T) {
+		envelope, _ := c.Seal("no evidence")
+		result := c.VerifyWithOptions(ctx, envelope, VerifyOptions{Offline: true})
+		if result.Valid || !strings.Contains(result.Error, "missing evidence record") {
+			t.Errorf("VerifyWithOptions() = %+v, want missing evidence", result)
+		}
+
+		envelope = enrichedTestEnvelope(t, c, "record only")
+		offline := anchoredOffline(envelope)
+		envelope.Kayros.Evidence.ChainRecord = nil
+		if result := c.VerifyWithOptions(ctx, envelope, offline); result.Code != VerifyErrorMissingEvidence {
+			t.Errorf("VerifyWithOptions() = %+v, want missing evidence without the chain record", result)
+		}
+	})
+
+	t.Run("reject tampered evidence", func(t *testing.T) {
+		// Swap in other data, with the record claiming it was proved
+		envelope := enrichedTestEnvelope(t, c, "tampered record")
+		other, _ := NewEnvelope("other data")
+		envelope.Data, envelope.Kayros.Hash = other.Data, other.Kayros.Hash
+		envelope.Kayros.Evidence.ChainRecord.DataItemHex = other.Kayros.Hash
+		envelope.Kayros.Evidence.Record.Data.DataItemHex = other.Kayros.Hash
+		if result := c.VerifyWithOptions(ctx, envelope, anchoredOffline(envelope)); result.Valid || result.Code != VerifyErrorRemoteMismatch || !strings.Contains(result.Error, "does not hash") {
+			t.Errorf("VerifyWithOptions() = %+v, want a record that does not hash to computed_hash_hex", result)
+		}
+
+		envelope = enrichedTestEnvelope(t, c, "malformed record")
+		envelope.Kayros.Evidence.ChainRecord.UUIDHex = "xyz"
+		if result := c.VerifyWithOptions(ctx, envelope, anchoredOffline(envelope)); result.Valid || result.Code != VerifyErrorMalformedRemoteRecord {
+			t.Errorf("VerifyWithOptions() = %+v, want malformed record", result)
+		}
+
+		envelope = enrichedTestEnvelope(t, c, "tampered proof")
+		envelope.Kayros.Evidence.MerkleProof.ProofHashesHex[0] = strings.Repeat("22", 32)
+		if result := c.VerifyWithOptions(ctx, envelope, VerifyOptions{}); result.Valid || result.Details.MerkleMatch || result.Code != VerifyErrorMerkleMismatch {
+			t.Errorf("VerifyWithOptions() = %+v, want Merkle mismatch from Kayros", result)
+		}
+
+		envelope = enrichedTestEnvelope(t, c, "proof for another record")
+		envelope.Kayros.Evidence.MerkleProof.TargetHashHex = strings.Repeat("33", 32)
+		if result := c.VerifyWithOptions(ctx, envelope, anchoredOffline(envelope)); result.Valid || !strings.Contains(result.Error, "different record") {
+			t.Errorf("VerifyWithOptions() = %+v, want proof for a different record", result)
+		}
+	})
+
+	t.Run("reject tampered proofs offline", func(t *testing.T) {
+		envelope := enrichedTestEnvelope(t, c, "tampered proof")
+		offline := anchoredOffline(envelope)
+		envelope.Kayros.Evidence.MerkleProof.ProofHashesHex[0] = strings.Repeat("22", 32)
+		result := c.VerifyWithOptions(ctx, envelope, offline)
+		if result.Valid || result.Code != VerifyErrorMerkleMismatch {
+			t.Errorf("VerifyWithOptions() = %+v, want invalid with %s", result, VerifyErrorMerkleMismatch)
+		}
+	})
+
+	t.Run("reject malformed proofs offline", func(t *testing.T) {
+		envelope := enrichedTestEnvelope(t, c, "malformed proof")
+		offline := anchoredOffline(envelope)
+		envelope.Kayros.Evidence.MerkleProof.ProofHashesHex[0] = "zz"
+		result := c.VerifyWithOptions(ctx, envelope, offline)
+		if result.Valid || result.Code != VerifyErrorMerkleMismatch {
+			t.Errorf("VerifyWithOptions() = %+v, want invalid with %s", result, VerifyErrorMerkleMismatch)
+		}
+	})
+
+	t.Run("check embedded proof online too", func(t *testing.T) {
+		envelope := enrichedTestEnvelope(t, c, "online")
+		if result := c.VerifyWithOptions(ctx, envelope, VerifyOptions{}); !result.Valid || !result.Details.MerkleMatch {
+			t.Errorf("VerifyWithOptions() = %+v, want valid with Merkle match", result)
+		}
+	})
+
+	t.Run("report proofs for roots Kayros doesn't serve as unanchored online", func(t *testing.T) {
+		// A zero-level proof leads to its own target, so any verifier that
+		// only recomputes the root accepts it
+		envelope := enrichedTestEnvelope(t, c, "forged online")
+		rh := envelope.Kayros.Evidence.MerkleProof.TargetHashHex
+		envelope.Kayros.Evidence.MerkleProof = &MerkleProof{TargetHashHex: rh, RootHashHex: rh, Levels: 0}
+		result := c.VerifyWithOptions(ctx, envelope, VerifyOptions{})
+		if !result.Valid || result.Details.MerkleMatch || !strings.Contains(result.Details.MerkleNote, "not anchored") {
+			t.Errorf("VerifyWithOptions() = %+v, want valid without a Merkle match, noting the root is not anchored", result)
+		}
+		if result.Details.MerkleRoot != rh {
+			t.Errorf("MerkleRoot = %s, want %s", result.Details.MerkleRoot, rh)
+		}
+	})
+}
+
+func TestEnrichEnvelope(t *testing.T) {
+	c, _ := newEvidenceTestClient(t)
+
+	t.Run("require a timestamp", func(t *testing.T) {
+		envelope, _ := NewEnvelope("unsealed")
+		if err := c.EnrichEnvelope(envelope); err == nil {
+			t.Error("EnrichEnvelope() error = nil, want error")
+		}
+	})
+
+	t.Run("return fetch errors", func(t *testing.T) {
+		envelope, _ := NewEnvelope("unknown")
+		envelope.Kayros.Timestamp = &KayrosTimestamp{Response: ProveSingleHashResponse{Data: ProveSingleHashResponseData{ComputedHashHex: "ab"}}}
+		if err := c.EnrichEnvelope(envelope); err == nil || envelope.Kayros.Evidence != nil {
+			t.Errorf("EnrichEnvelope() error = %v, want error without evidence", err)
+		}
+	})
+
+	t.Run("refuse evidence that doesn't verify locally", func(t *testing.T) {
+		record := DatabaseRecord{DataType: DataType, DataItemHex: Keccak256Str("data"), UUIDHex: strings.Repeat("01", 16)}
+		hash, _ := VerifyHashLocal(RecordHashRequest(record))
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case GetRecordByHashRoute:
+				json.NewEncoder(w).Encode(map[string]interface{}{"data": GetRecordResponseData{UUIDHex: record.UUIDHex}})
+			case "/api/merkle/generate-proof":
+				json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": MerkleProof{
+					RootHashHex:    strings.Repeat("00", 32),
+					ProofHashesHex: []string{evidenceTestSibling},
+					Levels:         1,
+				}})
+			default:
+				json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": record})
+			}
+		}))
+		defer server.Close()
+		bad, _ := NewClient(WithBaseURL(server.URL), WithRetryPolicy(NoRetry))
+		enrich := func(recordHash string) (*KayrosEnvelope, error) {
+			envelope, _ := NewEnvelope("bad evidence")
+			envelope.Kayros.Timestamp = &KayrosTimestamp{Response: ProveSingleHashResponse{Data: ProveSingleHashResponseData{ComputedHashHex: recordHash}}}
+			return envelope, bad.EnrichEnvelope(envelope)
+		}
+
+		if envelope, err := enrich(hash.ComputedHash); !errors.Is(err, ErrInvalidMerkleProof) || envelope.Kayros.Evidence != nil {
+			t.Errorf("EnrichEnvelope() error = %v, want ErrInvalidMerkleProof without evidence", err)
+		}
+	})
+}
@@ -5,12 +5,15 @@ go 1.23
 toolchain go1.24.4
 
 require (
+	github.com/zeebo/blake3 v0.2.4
+	github.com/zeebo/xxh3 v1.0.2
 	golang.org/x/crypto v0.28.0
 	google.golang.org/grpc v1.69.4
 	google.golang.org/protobuf v1.36.9
 )
 
 require (
+	github.com/klauspost/cpuid/v2 v2.0.12 // indirect
 	golang.org/x/net v0.30.0 // indirect
 	golang.org/x/sys v0.26.0 // indirect
 	golang.org/x/text v0.19.0 // indirect
@@ -0,0 +1,151 @@
+package provable
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"slices"
+	"sync"
+
+	"github.com/zeebo/blake3"
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/sha3"
+)
+
+// Built-in hash algorithm names, as recorded in KayrosMetadata.HashAlgorithm
+const (
+	HashAlgorithmKeccak256 = "keccak256"
+	HashAlgorithmSHA256    = "sha256"
+	HashAlgorithmSHA3_256  = "sha3-256"
+	HashAlgorithmBLAKE3    = "blake3"
+	HashAlgorithmBLAKE2b   = "blake2b" // BLAKE2b-256
+)
+
+// HashFunc hashes data and returns the digest as lowercase hex
+// Digests that are proved via Kayros must be 32 bytes.
+type HashFunc func(data []byte) string
+
+// StreamingHashFunc returns a new hash.Hash for hashing data incrementally,
+// e.g. in ProveReader
+type StreamingHashFunc func() hash.Hash
+
+var (
+	hashAlgorithmsMu sync.RWMutex
+	hashAlgorithms   = map[string]HashFunc{
+		HashAlgorithmKeccak256: Keccak256,
+		HashAlgorithmSHA256:    SHA256,
+		HashAlgorithmSHA3_256: func(data []byte) string {
+			sum := sha3.Sum256(data)
+			return hex.EncodeToString(sum[:])
+		},
+		HashAlgorithmBLAKE3: func(data []byte) string {
+			sum := blake3.Sum256(data)
+			return hex.EncodeToString(sum[:])
+		},
+		HashAlgorithmBLAKE2b: func(data []byte) string {
+			sum := blake2b.Sum256(data)
+			return hex.EncodeToString(sum[:])
+		},
+	}
+	streamingHashAlgorithms = map[string]StreamingHashFunc{
+		HashAlgorithmKeccak256: sha3.NewLegacyKeccak256,
+		HashAlgorithmSHA256:    sha256.New,
+		HashAlgorithmSHA3_256:  sha3.New256,
+		HashAlgorithmBLAKE3:    func() hash.Hash { return blake3.New() },
+		HashAlgorithmBLAKE2b: func() hash.Hash {
+			h, _ := blake2b.New256(nil)
+			return h
+		},
+	}
+)
+
+// RegisterHashAlgorithm makes a hash algorithm available to NewEnvelope, Seal
+// and Verify under name
+// It returns an error if name is empty, fn is nil or name is already taken.
+func RegisterHashAlgorithm(name string, fn HashFunc) error {
+	if fn == nil {
+		return fmt.Errorf("hash algorithm %q: nil HashFunc", name)
+	}
+	return registerHashAlgorithm(name, fn, nil)
+}
+
+// RegisterStreamingHashAlgorithm is like RegisterHashAlgorithm but also makes
+// the algorithm available to ProveReader and ProveFile
+func RegisterStreamingHashAlgorithm(name string, newHash StreamingHashFunc) error {
+	if newHash == nil {
+		return fmt.Errorf("hash algorithm %q: nil StreamingHashFunc", name)
+	}
+	fn := func(data []byte) string {
+		h := newHash()
+		h.Write(data)
+		return hex.EncodeToString(h.Sum(nil))
+	}
+	return registerHashAlgorithm(name, fn, newHash)
+}
+
+func registerHashAlgorithm(name string, fn HashFunc, newHash StreamingHashFunc) error {
+	if name == "" {
+		return fmt.Errorf("hash algorithm name must not be empty")
+	}
+
+	hashAlgorithmsMu.Lock()
+	defer hashAlgorithmsMu.Unlock()
+	if _, ok := hashAlgorithms[name]; ok {
+		return fmt.Errorf("hash algorithm %q is already registered", name)
+	}
+	hashAlgorithms[name] = fn
+	if newHash != nil {
+		streamingHashAlgorithms[name] = newHash
+	}
+	return nil
+}
+
+// LookupHashAlgorithm returns the hash function registered under name
+// An empty name means HashAlgorithmKeccak256, the algorithm envelopes used
+// before HashAlgorithm was honored.
+func LookupHashAlgorithm(name string) (HashFunc, error) {
+	if name == "" {
+		name = HashAlgorithmKeccak256
+	}
+
+	hashAlgorithmsMu.RLock()
+	defer hashAlgorithmsMu.RUnlock()
+	fn, ok := hashAlgorithms[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedHashAlgorithm, name)
+	}
+	return fn, nil
+}
+
+// LookupStreamingHashAlgorithm returns the streaming hash registered under
+// name, with an empty name meaning HashAlgorithmKeccak256
+// Algorithms registered with RegisterHashAlgorithm cannot stream.
+func LookupStreamingHashAlgorithm(name string) (StreamingHashFunc, error) {
+	if name == "" {
+		name = HashAlgorithmKeccak256
+	}
+
+	hashAlgorithmsMu.RLock()
+	defer hashAlgorithmsMu.RUnlock()
+	newHash, ok := streamingHashAlgorithms[name]
+	if !ok {
+		if _, registered := hashAlgorithms[name]; registered {
+			return nil, fmt.Errorf("%w: %q does not support streaming", ErrUnsupportedHashAlgorithm, name)
+		}
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedHashAlgorithm, name)
+	}
+	return newHash, nil
+}
+
+// HashAlgorithms returns the names of all registered hash algorithms, sorted
+func HashAlgorithms() []string {
+	hashAlgorithmsMu.RLock()
+	defer hashAlgorithmsMu.RUnlock()
+	names := make([]string, 0, len(hashAlgorithms))
+	for name := range hashAlgorithms {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
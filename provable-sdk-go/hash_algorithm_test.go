@@ -0,0 +1,168 @@
+package provable
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLookupHashAlgorithm(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{HashAlgorithmKeccak256, "4e03657aea45a94fc7d47ba826c8d667c0d1e6e33a64a036ec44f58fa12d6c45"},
+		{HashAlgorithmSHA256, "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+		{HashAlgorithmSHA3_256, "3a985da74fe225b2045c172d6bd390bd855f086e3e9d525b46bfe24511431532"},
+		{HashAlgorithmBLAKE3, "6437b3ac38465133ffb63b75273a8db548c558465d79db03fd359c6cd5bd9d85"},
+		{HashAlgorithmBLAKE2b, "bddd813c634239723171ef3fee98579b94964e3bb1cb3e427262c8c068d52319"},
+		{"", "4e03657aea45a94fc7d47ba826c8d667c0d1e6e33a64a036ec44f58fa12d6c45"},
+	}
+	for _, tt := range tests {
+		t.Run("hash abc with "+tt.name, func(t *testing.T) {
+			fn, err := LookupHashAlgorithm(tt.name)
+			if err != nil {
+				t.Fatalf("LookupHashAlgorithm(%q) error = %v", tt.name, err)
+			}
+			if got := fn([]byte("abc")); got != tt.want {
+				t.Errorf("%s(abc) = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+
+	t.Run("reject unknown algorithm", func(t *testing.T) {
+		if _, err := LookupHashAlgorithm("md5"); !errors.Is(err, ErrUnsupportedHashAlgorithm) {
+			t.Errorf("LookupHashAlgorithm() error = %v, want ErrUnsupportedHashAlgorithm", err)
+		}
+	})
+}
+
+// registerTestHashAlgorithm registers fn under name for the duration of the test
+func registerTestHashAlgorithm(t *testing.T, name string, fn HashFunc) {
+	t.Helper()
+	if err := RegisterHashAlgorithm(name, fn); err != nil {
+		t.Fatalf("RegisterHashAlgorithm() error = %v", err)
+	}
+	t.Cleanup(func() {
+		hashAlgorithmsMu.Lock()
+		defer hashAlgorithmsMu.Unlock()
+		delete(hashAlgorithms, name)
+		delete(streamingHashAlgorithms, name)
+	})
+}
+
+func TestRegisterHashAlgorithm(t *testing.T) {
+	upperKeccak := func(data []byte) string { return Keccak256([]byte(strings.ToUpper(string(data)))) }
+
+	t.Run("register and list custom algorithm", func(t *testing.T) {
+		registerTestHashAlgorithm(t, "test-upper-keccak", upperKeccak)
+		if !slices.Contains(HashAlgorithms(), "test-upper-keccak") {
+			t.Errorf("HashAlgorithms() = %v, want test-upper-keccak", HashAlgorithms())
+		}
+	})
+
+	t.Run("reject duplicates and invalid registrations", func(t *testing.T) {
+		if err := RegisterHashAlgorithm(HashAlgorithmKeccak256, upperKeccak); err == nil {
+			t.Error("RegisterHashAlgorithm(keccak256) error = nil, want error")
+		}
+		if err := RegisterHashAlgorithm("", upperKeccak); err == nil {
+			t.Error("RegisterHashAlgorithm(\"\") error = nil, want error")
+		}
+		if err := RegisterHashAlgorithm("test-nil", nil); err == nil {
+			t.Error("RegisterHashAlgorithm(nil) error = nil, want error")
+		}
+		if err := RegisterStreamingHashAlgorithm("test-nil", nil); err == nil {
+			t.Error("RegisterStreamingHashAlgorithm(nil) error = nil, want error")
+		}
+	})
+
+	t.Run("register streaming algorithm", func(t *testing.T) {
+		t.Cleanup(func() {
+			hashAlgorithmsMu.Lock()
+			defer hashAlgorithmsMu.Unlock()
+			delete(hashAlgorithms, "test-stream-sha256")
+			delete(streamingHashAlgorithms, "test-stream-sha256")
+		})
+		if err := RegisterStreamingHashAlgorithm("test-stream-sha256", sha256.New); err != nil {
+			t.Fatalf("RegisterStreamingHashAlgorithm() error = %v", err)
+		}
+		fn, err := LookupHashAlgorithm("test-stream-sha256")
+		if err != nil || fn([]byte("abc")) != SHA256Str("abc") {
+			t.Errorf("LookupHashAlgorithm() = %v, want SHA-256", err)
+		}
+		if _, err := LookupStreamingHashAlgorithm("test-stream-sha256"); err != nil {
+			t.Errorf("LookupStreamingHashAlgorithm() error = %v", err)
+		}
+	})
+}
+
+func TestLookupStreamingHashAlgorithm(t *testing.T) {
+	t.Run("match whole-buffer hashes", func(t *testing.T) {
+		for _, name := range []string{"", HashAlgorithmKeccak256, HashAlgorithmSHA256, HashAlgorithmSHA3_256, HashAlgorithmBLAKE3, HashAlgorithmBLAKE2b} {
+			newHash, err := LookupStreamingHashAlgorithm(name)
+			if err != nil {
+				t.Fatalf("LookupStreamingHashAlgorithm(%q) error = %v", name, err)
+			}
+			h := newHash()
+			h.Write([]byte("a"))
+			h.Write([]byte("bc"))
+			fn, _ := LookupHashAlgorithm(name)
+			if got, want := hex.EncodeToString(h.Sum(nil)), fn([]byte("abc")); got != want {
+				t.Errorf("%s streamed = %v, want %v", name, got, want)
+			}
+		}
+	})
+
+	t.Run("reject algorithms that cannot stream", func(t *testing.T) {
+		registerTestHashAlgorithm(t, "test-buffer-only", Keccak256)
+		_, err := LookupStreamingHashAlgorithm("test-buffer-only")
+		if !errors.Is(err, ErrUnsupportedHashAlgorithm) || !strings.Contains(err.Error(), "streaming") {
+			t.Errorf("LookupStreamingHashAlgorithm() error = %v, want no streaming support", err)
+		}
+		if _, err := LookupStreamingHashAlgorithm("md5"); !errors.Is(err, ErrUnsupportedHashAlgorithm) {
+			t.Errorf("LookupStreamingHashAlgorithm() error = %v, want ErrUnsupportedHashAlgorithm", err)
+		}
+	})
+}
+
+func TestVerifyHashAlgorithm(t *testing.T) {
+	t.Run("dispatch on recorded algorithm", func(t *testing.T) {
+		for _, name := range []string{HashAlgorithmSHA256, HashAlgorithmSHA3_256, HashAlgorithmBLAKE3, HashAlgorithmBLAKE2b} {
+			envelope, err := NewEnvelope(map[string]int{"a": 1}, WithHashAlgorithm(name))
+			if err != nil {
+				t.Fatalf("NewEnvelope(%s) error = %v", name, err)
+			}
+			if result := Verify(envelope); !result.Valid {
+				t.Errorf("Verify() with %s = %+v, want valid", name, result)
+			}
+
+			envelope.Kayros.HashAlgorithm = HashAlgorithmKeccak256
+			if result := Verify(envelope); result.Valid {
+				t.Errorf("Verify() of %s hash as keccak256 = valid, want hash mismatch", name)
+			}
+		}
+	})
+
+	t.Run("use custom algorithm", func(t *testing.T) {
+		name := "test-verify-custom"
+		registerTestHashAlgorithm(t, name, func(data []byte) string { return SHA256(append([]byte("salt:"), data...)) })
+		envelope := &KayrosEnvelope{Data: "hello", Kayros: KayrosMetadata{Hash: SHA256Str("salt:hello"), HashAlgorithm: name}}
+		if result := Verify(envelope); !result.Valid {
+			t.Errorf("Verify() = %+v, want valid", result)
+		}
+	})
+
+	t.Run("report unknown algorithm", func(t *testing.T) {
+		envelope := &KayrosEnvelope{Data: "hello", Kayros: KayrosMetadata{Hash: Keccak256Str("hello"), HashAlgorithm: "md5"}}
+		result := Verify(envelope)
+		if result.Valid || !errors.Is(result.Err, ErrUnsupportedHashAlgorithm) {
+			t.Errorf("Verify() = %+v, want ErrUnsupportedHashAlgorithm", result)
+		}
+		if !strings.Contains(result.Error, "md5") {
+			t.Errorf("Error = %q, want algorithm name", result.Error)
+		}
+	})
+}
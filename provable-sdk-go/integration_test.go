@@ -1,12 +1,79 @@
 package provable
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
 	"regexp"
+	"strings"
 	"testing"
 	"time"
 )
 
+// captureFixturesEnv names the environment variable that makes the
+// conformance tests save the Lightnet responses they check under testdata,
+// for the fixture tests to replay without network access
+const captureFixturesEnv = "PROVABLE_CAPTURE_FIXTURES"
+
+// conformanceEnv names the environment variable that enables the
+// conformance tests, which need the live service
+const conformanceEnv = "PROVABLE_CONFORMANCE"
+
+// requireService skips the test unless conformanceEnv or captureFixturesEnv
+// is set
+func requireService(t *testing.T) {
+	t.Helper()
+	if os.Getenv(conformanceEnv) == "" && os.Getenv(captureFixturesEnv) == "" {
+		t.Skipf("talks to the live service; set %s=1 to run it", conformanceEnv)
+	}
+}
+
+// requireFixtures returns the fixtures captured in testdata/lightnet/kind,
+// failing the test when there are none
+func requireFixtures(t *testing.T, kind string) []string {
+	t.Helper()
+	paths, err := filepath.Glob(filepath.Join("testdata", "lightnet", kind, "*.json"))
+	if err != nil {
+		t.Fatalf("filepath.Glob() error = %v", err)
+	}
+	if len(paths) == 0 {
+		t.Fatalf("no Lightnet responses captured in testdata/lightnet/%s; run the conformance tests with %s=1 to capture them", kind, captureFixturesEnv)
+	}
+	return paths
+}
+
+func readFixture(t *testing.T, path string, v interface{}) {
+	t.Helper()
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("os.ReadFile() error = %v", err)
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		t.Fatalf("decode %s: %v", path, err)
+	}
+}
+
+// captureFixture saves v as testdata/lightnet/kind/name.json when
+// captureFixturesEnv is set
+func captureFixture(t *testing.T, kind, name string, v interface{}) {
+	t.Helper()
+	if os.Getenv(captureFixturesEnv) == "" {
+		return
+	}
+	dir := filepath.Join("testdata", "lightnet", kind)
+	raw, err := json.MarshalIndent(v, "", "  ")
+	if err == nil {
+		err = os.MkdirAll(dir, 0o755)
+	}
+	if err == nil {
+		err = os.WriteFile(filepath.Join(dir, name+".json"), append(raw, '\n'), 0o644)
+	}
+	if err != nil {
+		t.Fatalf("capture %s fixture: %v", kind, err)
+	}
+}
+
 // TestFullCycleIntegration tests the complete cycle:
 // data -> hash -> index with Kayros -> build proof -> verify
 func TestFullCycleIntegration(t *testing.T) {
@@ -101,3 +168,127 @@ func TestFullCycleIntegration(t *testing.T) {
 		t.Errorf("data_item_hex = %v, want %v", record.Data.DataItemHex, dataHash)
 	}
 }
+
+// TestMerkleConformanceIntegration checks the Merkle rules VerifyMerkleProofLocal
+// assumes against the live service: a proof Lightnet generates and accepts
+// must verify locally to the same root
+// With captureFixturesEnv set the responses are saved for TestMerkleFixtures.
+func TestMerkleConformanceIntegration(t *testing.T) {
+	requireService(t)
+	dataHash := Keccak256Str(fmt.Sprintf("Merkle conformance data %d", time.Now().UnixMilli()))
+	proved, err := ProveSingleHash(dataHash)
+	if err != nil {
+		t.Fatalf("ProveSingleHash failed: %v", err)
+	}
+	recordHash := proved.Data.ComputedHashHex
+
+	// A fresh record may take a moment to reach the tree
+	var proof *MerkleProof
+	for attempt := 0; ; attempt++ {
+		proof, err = GenerateMerkleProof(GenerateMerkleProofRequest{HashItem: recordHash})
+		if err == nil || attempt == 10 {
+			break
+		}
+		time.Sleep(time.Second)
+	}
+	if err != nil {
+		t.Fatalf("GenerateMerkleProof failed: %v", err)
+	}
+	raw, _ := json.Marshal(proof)
+	t.Logf("generate-proof response: %s", raw)
+
+	remote, err := VerifyMerkleProof(VerifyMerkleProofRequest{
+		TargetHashHex:  proof.TargetHashHex,
+		ProofHashesHex: proof.ProofHashesHex,
+		Levels:         proof.Levels,
+		Position:       proof.Position,
+		RootHashHex:    proof.RootHashHex,
+	})
+	if err != nil {
+		t.Fatalf("VerifyMerkleProof failed: %v", err)
+	}
+	if !remote.Valid {
+		t.Fatalf("VerifyMerkleProof = %+v, want the service to accept its own proof", remote)
+	}
+	captureFixture(t, "merkle", recordHash[:16], merkleFixture{RecordHash: recordHash, Proof: *proof, Result: *remote})
+
+	local, err := VerifyMerkleProofLocal(*proof)
+	if err != nil {
+		t.Fatalf("VerifyMerkleProofLocal failed: %v", err)
+	}
+	if !local.Valid || !strings.EqualFold(local.ComputedRootHex, remote.ComputedRootHex) {
+		t.Errorf("VerifyMerkleProofLocal = %+v, want valid with root %s", local, remote.ComputedRootHex)
+	}
+}
+
+// TestRecordHashConformanceIntegration checks the record hash layout
+// VerifyHashLocal assumes against the live service, for both hash types: a
+// fresh record must rehash to its hash_item_hex, and VerifyHash and
+// ComputeHashFromHex must agree with their local counterparts
+// The responses are logged, and with captureFixturesEnv set saved for
+// TestRecordHashFixtures.
+func TestRecordHashConformanceIntegration(t *testing.T) {
+	requireService(t)
+	dataHash := Keccak256Str(fmt.Sprintf("Record hash conformance data %d", time.Now().UnixMilli()))
+	proved, err := ProveSingleHash(dataHash)
+	if err != nil {
+		t.Fatalf("ProveSingleHash failed: %v", err)
+	}
+	uuid := proved.Data.TimeuuidHex
+	if uuid == "" {
+		record, err := GetRecordByHash(proved.Data.ComputedHashHex)
+		if err != nil {
+			t.Fatalf("GetRecordByHash failed: %v", err)
+		}
+		uuid = record.Data.UUIDHex
+	}
+	record, err := GetRecordWithPrevHash(uuid)
+	if err != nil {
+		t.Fatalf("GetRecordWithPrevHash failed: %v", err)
+	}
+	raw, _ := json.Marshal(record)
+	t.Logf("record-with-prev response: %s", raw)
+	fixture := recordHashFixture{
+		Record:      *record,
+		VerifyHash:  map[string]HashVerifyResult{},
+		ComputeHash: map[string]HashVerifyResult{},
+	}
+
+	if valid, result, err := VerifyRecordLocal(*record); err != nil || !valid {
+		t.Errorf("VerifyRecordLocal = %v, %+v, %v, want the record to rehash to %s", valid, result, err, record.HashItemHex)
+	}
+
+	for _, hashType := range []string{RecordHashBLAKE3, RecordHashXXH3} {
+		request := RecordHashRequest(*record)
+		request.HashType = hashType
+		remote, err := VerifyHash(request)
+		if err != nil {
+			t.Fatalf("VerifyHash(%s) failed: %v", hashType, err)
+		}
+		raw, _ := json.Marshal(remote)
+		t.Logf("verify-hash %s response: %s", hashType, raw)
+		fixture.VerifyHash[hashType] = *remote
+		local, err := VerifyHashLocal(request)
+		if err != nil {
+			t.Fatalf("VerifyHashLocal(%s) failed: %v", hashType, err)
+		}
+		if !strings.EqualFold(local.ComputedHash, remote.ComputedHash) || !strings.EqualFold(local.HashInputHex, remote.HashInputHex) {
+			t.Errorf("VerifyHashLocal(%s) = %+v, want %+v", hashType, local, remote)
+		}
+
+		computeRequest := ComputeHashRequest{HashInputHex: remote.HashInputHex, HashType: hashType}
+		remote, err = ComputeHashFromHex(computeRequest)
+		if err != nil {
+			t.Fatalf("ComputeHashFromHex(%s) failed: %v", hashType, err)
+		}
+		fixture.ComputeHash[hashType] = *remote
+		local, err = ComputeHashFromHexLocal(computeRequest)
+		if err != nil {
+			t.Fatalf("ComputeHashFromHexLocal(%s) failed: %v", hashType, err)
+		}
+		if !strings.EqualFold(local.ComputedHash, remote.ComputedHash) {
+			t.Errorf("ComputeHashFromHexLocal(%s) = %s, want %s", hashType, local.ComputedHash, remote.ComputedHash)
+		}
+	}
+	captureFixture(t, "record_hash", uuid, fixture)
+}
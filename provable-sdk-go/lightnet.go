@@ -1,11 +1,9 @@
 package provable
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
 	"net/url"
 )
 
@@ -30,11 +28,11 @@ type HashRecord struct {
 }
 
 type DatabaseStats struct {
-	TotalHashes    int64             `json:"total_hashes"`
-	CountByType    map[string]int64  `json:"count_by_type"`
-	MinTimestamp   string            `json:"min_timestamp"`
-	MaxTimestamp   string            `json:"max_timestamp"`
-	TimestampRange string            `json:"timestamp_range"`
+	TotalHashes    int64            `json:"total_hashes"`
+	CountByType    map[string]int64 `json:"count_by_type"`
+	MinTimestamp   string           `json:"min_timestamp"`
+	MaxTimestamp   string           `json:"max_timestamp"`
+	TimestampRange string           `json:"timestamp_range"`
 }
 
 type ColumnInfo struct {
@@ -89,14 +87,14 @@ type SingleHashRequest struct {
 }
 
 type SingleHashResponse struct {
-	Success        bool   `json:"success"`
-	Message        string `json:"message"`
-	DataType       string `json:"data_type"`
-	DataItem       string `json:"data_item"`
+	Success         bool   `json:"success"`
+	Message         string `json:"message"`
+	DataType        string `json:"data_type"`
+	DataItem        string `json:"data_item"`
 	ComputedHashHex string `json:"computed_hash_hex"`
-	TimeuuidHex    string `json:"timeuuid_hex"`
-	DataTypeHex    string `json:"data_type_hex"`
-	DataItemHex    string `json:"data_item_hex"`
+	TimeuuidHex     string `json:"timeuuid_hex"`
+	DataTypeHex     string `json:"data_type_hex"`
+	DataItemHex     string `json:"data_item_hex"`
 }
 
 // Merkle proof types
@@ -147,344 +145,346 @@ type APIResponse struct {
 	Error   string      `json:"error,omitempty"`
 }
 
-// Database Operations
-
-// QueryHashes queries hash records from the database
-func QueryHashes(query DatabaseQuery) (*APIResponse, error) {
-	url := GetKayrosURL("/api/database/query")
-
-	jsonData, err := json.Marshal(query)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal query: %w", err)
+// DecodeData converts the Data of an APIResponse into T, e.g. a
+// map[string]interface{} decoded from JSON into a *MerkleProof
+func DecodeData[T any](resp *APIResponse) (T, error) {
+	var data T
+	if resp == nil || resp.Data == nil {
+		return data, nil
 	}
-
-	resp, err := http.Post(url, "application/json", bytes.NewBuffer(jsonData))
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+	if typed, ok := resp.Data.(T); ok {
+		return typed, nil
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API error: %d %s - %s", resp.StatusCode, resp.Status, string(body))
+	raw, err := json.Marshal(resp.Data)
+	if err != nil {
+		return data, fmt.Errorf("%w: %w", ErrDecodeResponse, err)
 	}
-
-	var result APIResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return data, fmt.Errorf("%w: %w", ErrDecodeResponse, err)
 	}
+	return data, nil
+}
 
-	return &result, nil
+// getData GETs route and decodes the data field of the APIResponse into T
+func getData[T any](ctx context.Context, c *Client, route string) (T, error) {
+	var data T
+	err := c.get(ctx, route, &APIResponse{Data: &data})
+	return data, err
 }
 
-// GetDatabaseStats gets database statistics
-func GetDatabaseStats() (*APIResponse, error) {
-	url := GetKayrosURL("/api/database/stats")
+// postData is like getData for read-only POST routes
+func postData[T any](ctx context.Context, c *Client, route string, body interface{}) (T, error) {
+	var data T
+	err := c.post(ctx, route, body, &APIResponse{Data: &data})
+	return data, err
+}
 
-	resp, err := http.Get(url)
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
-	}
-	defer resp.Body.Close()
+// submitData is like postData for routes that create records
+func submitData[T any](ctx context.Context, c *Client, route string, body interface{}) (T, error) {
+	var data T
+	err := c.submit(ctx, route, body, &APIResponse{Data: &data})
+	return data, err
+}
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API error: %d %s - %s", resp.StatusCode, resp.Status, string(body))
-	}
+// Database Operations
 
-	var result APIResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
-	}
+// QueryHashes queries hash records from the database
+func (c *Client) QueryHashes(query DatabaseQuery) ([]HashRecord, error) {
+	return c.QueryHashesContext(context.Background(), query)
+}
 
-	return &result, nil
+// QueryHashesContext is like QueryHashes but honors ctx cancellation and deadlines
+func (c *Client) QueryHashesContext(ctx context.Context, query DatabaseQuery) ([]HashRecord, error) {
+	return postData[[]HashRecord](ctx, c, "/api/database/query", query)
 }
 
-// GetLatestHashes gets the most recent hash records
-func GetLatestHashes(limit int) (*APIResponse, error) {
-	url := GetKayrosURL(fmt.Sprintf("/api/database/latest?limit=%d", limit))
+// GetDatabaseStats gets database statistics
+func (c *Client) GetDatabaseStats() (*DatabaseStats, error) {
+	return c.GetDatabaseStatsContext(context.Background())
+}
 
-	resp, err := http.Get(url)
+// GetDatabaseStatsContext is like GetDatabaseStats but honors ctx cancellation and deadlines
+func (c *Client) GetDatabaseStatsContext(ctx context.Context) (*DatabaseStats, error) {
+	data, err := getData[DatabaseStats](ctx, c, "/api/database/stats")
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API error: %d %s - %s", resp.StatusCode, resp.Status, string(body))
+		return nil, err
 	}
+	return &data, nil
+}
 
-	var result APIResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
-	}
+// GetLatestHashes gets the most recent hash records
+func (c *Client) GetLatestHashes(limit int) ([]HashRecord, error) {
+	return c.GetLatestHashesContext(context.Background(), limit)
+}
 
-	return &result, nil
+// GetLatestHashesContext is like GetLatestHashes but honors ctx cancellation and deadlines
+func (c *Client) GetLatestHashesContext(ctx context.Context, limit int) ([]HashRecord, error) {
+	return getData[[]HashRecord](ctx, c, fmt.Sprintf("/api/database/latest?limit=%d", limit))
 }
 
 // GetTables gets all database tables
-func GetTables() (*APIResponse, error) {
-	url := GetKayrosURL("/api/database/tables")
-
-	resp, err := http.Get(url)
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API error: %d %s - %s", resp.StatusCode, resp.Status, string(body))
-	}
-
-	var result APIResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
-	}
+func (c *Client) GetTables() ([]string, error) {
+	return c.GetTablesContext(context.Background())
+}
 
-	return &result, nil
+// GetTablesContext is like GetTables but honors ctx cancellation and deadlines
+func (c *Client) GetTablesContext(ctx context.Context) ([]string, error) {
+	return getData[[]string](ctx, c, "/api/database/tables")
 }
 
 // GetTableSchema gets schema for a specific table
-func GetTableSchema(tableName string) (*APIResponse, error) {
-	url := GetKayrosURL(fmt.Sprintf("/api/database/schema?table=%s", url.QueryEscape(tableName)))
-
-	resp, err := http.Get(url)
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API error: %d %s - %s", resp.StatusCode, resp.Status, string(body))
-	}
-
-	var result APIResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
-	}
+func (c *Client) GetTableSchema(tableName string) ([]ColumnInfo, error) {
+	return c.GetTableSchemaContext(context.Background(), tableName)
+}
 
-	return &result, nil
+// GetTableSchemaContext is like GetTableSchema but honors ctx cancellation and deadlines
+func (c *Client) GetTableSchemaContext(ctx context.Context, tableName string) ([]ColumnInfo, error) {
+	return getData[[]ColumnInfo](ctx, c, "/api/database/schema?table="+url.QueryEscape(tableName))
 }
 
 // BrowseTable browses table data with pagination
-func BrowseTable(request TableBrowseRequest) (*APIResponse, error) {
-	url := GetKayrosURL("/api/database/browse")
-
-	jsonData, err := json.Marshal(request)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	resp, err := http.Post(url, "application/json", bytes.NewBuffer(jsonData))
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API error: %d %s - %s", resp.StatusCode, resp.Status, string(body))
-	}
-
-	var result APIResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
-	}
+func (c *Client) BrowseTable(request TableBrowseRequest) ([]map[string]interface{}, error) {
+	return c.BrowseTableContext(context.Background(), request)
+}
 
-	return &result, nil
+// BrowseTableContext is like BrowseTable but honors ctx cancellation and deadlines
+func (c *Client) BrowseTableContext(ctx context.Context, request TableBrowseRequest) ([]map[string]interface{}, error) {
+	return postData[[]map[string]interface{}](ctx, c, "/api/database/browse", request)
 }
 
 // GetRecord gets a record by UUID
-func GetRecord(uuid string) (*APIResponse, error) {
-	url := GetKayrosURL(fmt.Sprintf("/api/database/record?uuid=%s", url.QueryEscape(uuid)))
+func (c *Client) GetRecord(uuid string) (*DatabaseRecord, error) {
+	return c.GetRecordContext(context.Background(), uuid)
+}
 
-	resp, err := http.Get(url)
+// GetRecordContext is like GetRecord but honors ctx cancellation and deadlines
+func (c *Client) GetRecordContext(ctx context.Context, uuid string) (*DatabaseRecord, error) {
+	data, err := getData[DatabaseRecord](ctx, c, "/api/database/record?uuid="+url.QueryEscape(uuid))
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API error: %d %s - %s", resp.StatusCode, resp.Status, string(body))
-	}
-
-	var result APIResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
-	}
-
-	return &result, nil
+	return &data, nil
 }
 
 // GetRecordWithPrevHash gets a record by UUID with previous hash
-func GetRecordWithPrevHash(uuid string) (*APIResponse, error) {
-	url := GetKayrosURL(fmt.Sprintf("/api/database/record-with-prev?uuid=%s", url.QueryEscape(uuid)))
+func (c *Client) GetRecordWithPrevHash(uuid string) (*DatabaseRecord, error) {
+	return c.GetRecordWithPrevHashContext(context.Background(), uuid)
+}
 
-	resp, err := http.Get(url)
+// GetRecordWithPrevHashContext is like GetRecordWithPrevHash but honors ctx cancellation and deadlines
+func (c *Client) GetRecordWithPrevHashContext(ctx context.Context, uuid string) (*DatabaseRecord, error) {
+	data, err := getData[DatabaseRecord](ctx, c, "/api/database/record-with-prev?uuid="+url.QueryEscape(uuid))
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API error: %d %s - %s", resp.StatusCode, resp.Status, string(body))
-	}
-
-	var result APIResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
-	}
-
-	return &result, nil
+	return &data, nil
 }
 
 // Hash Operations
 
 // VerifyHash verifies a hash computation
-func VerifyHash(request HashVerifyRequest) (*APIResponse, error) {
-	url := GetKayrosURL("/api/verify-hash")
+func (c *Client) VerifyHash(request HashVerifyRequest) (*HashVerifyResult, error) {
+	return c.VerifyHashContext(context.Background(), request)
+}
 
-	jsonData, err := json.Marshal(request)
+// VerifyHashContext is like VerifyHash but honors ctx cancellation and deadlines
+func (c *Client) VerifyHashContext(ctx context.Context, request HashVerifyRequest) (*HashVerifyResult, error) {
+	data, err := postData[HashVerifyResult](ctx, c, "/api/verify-hash", request)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
+		return nil, err
 	}
+	return &data, nil
+}
+
+// ComputeHashFromHex computes hash from hex input
+func (c *Client) ComputeHashFromHex(request ComputeHashRequest) (*HashVerifyResult, error) {
+	return c.ComputeHashFromHexContext(context.Background(), request)
+}
 
-	resp, err := http.Post(url, "application/json", bytes.NewBuffer(jsonData))
+// ComputeHashFromHexContext is like ComputeHashFromHex but honors ctx cancellation and deadlines
+func (c *Client) ComputeHashFromHexContext(ctx context.Context, request ComputeHashRequest) (*HashVerifyResult, error) {
+	data, err := postData[HashVerifyResult](ctx, c, "/api/compute-hash-from-hex", request)
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
+	return &data, nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API error: %d %s - %s", resp.StatusCode, resp.Status, string(body))
-	}
+// gRPC Operations
 
-	var result APIResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
-	}
+// SendSingleGRPCRequest sends a single gRPC request to Lightnet
+func (c *Client) SendSingleGRPCRequest(request SingleHashRequest) (*SingleHashResponse, error) {
+	return c.SendSingleGRPCRequestContext(context.Background(), request)
+}
 
-	return &result, nil
+// SendSingleGRPCRequestContext is like SendSingleGRPCRequest but honors ctx cancellation and deadlines
+func (c *Client) SendSingleGRPCRequestContext(ctx context.Context, request SingleHashRequest) (*SingleHashResponse, error) {
+	data, err := submitData[SingleHashResponse](ctx, c, ProveSingleHashRoute, request)
+	if err != nil {
+		return nil, err
+	}
+	return &data, nil
 }
 
-// ComputeHashFromHex computes hash from hex input
-func ComputeHashFromHex(request ComputeHashRequest) (*APIResponse, error) {
-	url := GetKayrosURL("/api/compute-hash-from-hex")
+// Merkle Proof Operations
 
-	jsonData, err := json.Marshal(request)
+// GenerateMerkleProof generates a Merkle proof for a specific hash
+func (c *Client) GenerateMerkleProof(request GenerateMerkleProofRequest) (*MerkleProof, error) {
+	return c.GenerateMerkleProofContext(context.Background(), request)
+}
+
+// GenerateMerkleProofContext is like GenerateMerkleProof but honors ctx cancellation and deadlines
+func (c *Client) GenerateMerkleProofContext(ctx context.Context, request GenerateMerkleProofRequest) (*MerkleProof, error) {
+	data, err := postData[MerkleProof](ctx, c, "/api/merkle/generate-proof", request)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
+		return nil, err
 	}
+	return &data, nil
+}
 
-	resp, err := http.Post(url, "application/json", bytes.NewBuffer(jsonData))
+// VerifyMerkleProof verifies a Merkle proof
+func (c *Client) VerifyMerkleProof(request VerifyMerkleProofRequest) (*MerkleProofVerificationResult, error) {
+	return c.VerifyMerkleProofContext(context.Background(), request)
+}
+
+// VerifyMerkleProofContext is like VerifyMerkleProof but honors ctx cancellation and deadlines
+func (c *Client) VerifyMerkleProofContext(ctx context.Context, request VerifyMerkleProofRequest) (*MerkleProofVerificationResult, error) {
+	data, err := postData[MerkleProofVerificationResult](ctx, c, "/api/merkle/verify-proof", request)
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
+	return &data, nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API error: %d %s - %s", resp.StatusCode, resp.Status, string(body))
-	}
+// Package-level wrappers over DefaultClient
 
-	var result APIResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
-	}
+// QueryHashes queries hash records from the database
+func QueryHashes(query DatabaseQuery) ([]HashRecord, error) {
+	return DefaultClient.QueryHashes(query)
+}
 
-	return &result, nil
+// QueryHashesContext is like QueryHashes but honors ctx cancellation and deadlines
+func QueryHashesContext(ctx context.Context, query DatabaseQuery) ([]HashRecord, error) {
+	return DefaultClient.QueryHashesContext(ctx, query)
 }
 
-// gRPC Operations
+// GetDatabaseStats gets database statistics
+func GetDatabaseStats() (*DatabaseStats, error) {
+	return DefaultClient.GetDatabaseStats()
+}
 
-// SendSingleGRPCRequest sends a single gRPC request to Lightnet
-func SendSingleGRPCRequest(request SingleHashRequest) (*APIResponse, error) {
-	url := GetKayrosURL("/api/grpc/single-hash")
+// GetDatabaseStatsContext is like GetDatabaseStats but honors ctx cancellation and deadlines
+func GetDatabaseStatsContext(ctx context.Context) (*DatabaseStats, error) {
+	return DefaultClient.GetDatabaseStatsContext(ctx)
+}
 
-	jsonData, err := json.Marshal(request)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
-	}
+// GetLatestHashes gets the most recent hash records
+func GetLatestHashes(limit int) ([]HashRecord, error) {
+	return DefaultClient.GetLatestHashes(limit)
+}
 
-	resp, err := http.Post(url, "application/json", bytes.NewBuffer(jsonData))
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
-	}
-	defer resp.Body.Close()
+// GetLatestHashesContext is like GetLatestHashes but honors ctx cancellation and deadlines
+func GetLatestHashesContext(ctx context.Context, limit int) ([]HashRecord, error) {
+	return DefaultClient.GetLatestHashesContext(ctx, limit)
+}
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API error: %d %s - %s", resp.StatusCode, resp.Status, string(body))
-	}
+// GetTables gets all database tables
+func GetTables() ([]string, error) {
+	return DefaultClient.GetTables()
+}
 
-	var result APIResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
-	}
+// GetTablesContext is like GetTables but honors ctx cancellation and deadlines
+func GetTablesContext(ctx context.Context) ([]string, error) {
+	return DefaultClient.GetTablesContext(ctx)
+}
 
-	return &result, nil
+// GetTableSchema gets schema for a specific table
+func GetTableSchema(tableName string) ([]ColumnInfo, error) {
+	return DefaultClient.GetTableSchema(tableName)
 }
 
-// Merkle Proof Operations
+// GetTableSchemaContext is like GetTableSchema but honors ctx cancellation and deadlines
+func GetTableSchemaContext(ctx context.Context, tableName string) ([]ColumnInfo, error) {
+	return DefaultClient.GetTableSchemaContext(ctx, tableName)
+}
 
-// GenerateMerkleProof generates a Merkle proof for a specific hash
-func GenerateMerkleProof(request GenerateMerkleProofRequest) (*APIResponse, error) {
-	url := GetKayrosURL("/api/merkle/generate-proof")
+// BrowseTable browses table data with pagination
+func BrowseTable(request TableBrowseRequest) ([]map[string]interface{}, error) {
+	return DefaultClient.BrowseTable(request)
+}
 
-	jsonData, err := json.Marshal(request)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
-	}
+// BrowseTableContext is like BrowseTable but honors ctx cancellation and deadlines
+func BrowseTableContext(ctx context.Context, request TableBrowseRequest) ([]map[string]interface{}, error) {
+	return DefaultClient.BrowseTableContext(ctx, request)
+}
 
-	resp, err := http.Post(url, "application/json", bytes.NewBuffer(jsonData))
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
-	}
-	defer resp.Body.Close()
+// GetRecord gets a record by UUID
+func GetRecord(uuid string) (*DatabaseRecord, error) {
+	return DefaultClient.GetRecord(uuid)
+}
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API error: %d %s - %s", resp.StatusCode, resp.Status, string(body))
-	}
+// GetRecordContext is like GetRecord but honors ctx cancellation and deadlines
+func GetRecordContext(ctx context.Context, uuid string) (*DatabaseRecord, error) {
+	return DefaultClient.GetRecordContext(ctx, uuid)
+}
 
-	var result APIResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
-	}
+// GetRecordWithPrevHash gets a record by UUID with previous hash
+func GetRecordWithPrevHash(uuid string) (*DatabaseRecord, error) {
+	return DefaultClient.GetRecordWithPrevHash(uuid)
+}
 
-	return &result, nil
+// GetRecordWithPrevHashContext is like GetRecordWithPrevHash but honors ctx cancellation and deadlines
+func GetRecordWithPrevHashContext(ctx context.Context, uuid string) (*DatabaseRecord, error) {
+	return DefaultClient.GetRecordWithPrevHashContext(ctx, uuid)
 }
 
-// VerifyMerkleProof verifies a Merkle proof
-func VerifyMerkleProof(request VerifyMerkleProofRequest) (*APIResponse, error) {
-	url := GetKayrosURL("/api/merkle/verify-proof")
+// VerifyHash verifies a hash computation
+func VerifyHash(request HashVerifyRequest) (*HashVerifyResult, error) {
+	return DefaultClient.VerifyHash(request)
+}
 
-	jsonData, err := json.Marshal(request)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
-	}
+// VerifyHashContext is like VerifyHash but honors ctx cancellation and deadlines
+func VerifyHashContext(ctx context.Context, request HashVerifyRequest) (*HashVerifyResult, error) {
+	return DefaultClient.VerifyHashContext(ctx, request)
+}
 
-	resp, err := http.Post(url, "application/json", bytes.NewBuffer(jsonData))
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
-	}
-	defer resp.Body.Close()
+// ComputeHashFromHex computes hash from hex input
+func ComputeHashFromHex(request ComputeHashRequest) (*HashVerifyResult, error) {
+	return DefaultClient.ComputeHashFromHex(request)
+}
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API error: %d %s - %s", resp.StatusCode, resp.Status, string(body))
-	}
+// ComputeHashFromHexContext is like ComputeHashFromHex but honors ctx cancellation and deadlines
+func ComputeHashFromHexContext(ctx context.Context, request ComputeHashRequest) (*HashVerifyResult, error) {
+	return DefaultClient.ComputeHashFromHexContext(ctx, request)
+}
 
-	var result APIResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
-	}
+// SendSingleGRPCRequest sends a single gRPC request to Lightnet
+func SendSingleGRPCRequest(request SingleHashRequest) (*SingleHashResponse, error) {
+	return DefaultClient.SendSingleGRPCRequest(request)
+}
+
+// SendSingleGRPCRequestContext is like SendSingleGRPCRequest but honors ctx cancellation and deadlines
+func SendSingleGRPCRequestContext(ctx context.Context, request SingleHashRequest) (*SingleHashResponse, error) {
+	return DefaultClient.SendSingleGRPCRequestContext(ctx, request)
+}
+
+// GenerateMerkleProof generates a Merkle proof for a specific hash
+func GenerateMerkleProof(request GenerateMerkleProofRequest) (*MerkleProof, error) {
+	return DefaultClient.GenerateMerkleProof(request)
+}
+
+// GenerateMerkleProofContext is like GenerateMerkleProof but honors ctx cancellation and deadlines
+func GenerateMerkleProofContext(ctx context.Context, request GenerateMerkleProofRequest) (*MerkleProof, error) {
+	return DefaultClient.GenerateMerkleProofContext(ctx, request)
+}
+
+// VerifyMerkleProof verifies a Merkle proof
+func VerifyMerkleProof(request VerifyMerkleProofRequest) (*MerkleProofVerificationResult, error) {
+	return DefaultClient.VerifyMerkleProof(request)
+}
 
-	return &result, nil
+// VerifyMerkleProofContext is like VerifyMerkleProof but honors ctx cancellation and deadlines
+func VerifyMerkleProofContext(ctx context.Context, request VerifyMerkleProofRequest) (*MerkleProofVerificationResult, error) {
+	return DefaultClient.VerifyMerkleProofContext(ctx, request)
 }
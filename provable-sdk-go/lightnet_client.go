@@ -0,0 +1,340 @@
+package provable
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+
+	lightnetpb "github.com/provable/provable-sdk-go/proto/lightnet"
+)
+
+// Lightnet gRPC types
+
+type DebugHashResult struct {
+	HashInputHex    string `json:"hash_input_hex"`
+	ComputedHashHex string `json:"computed_hash_hex"`
+	PrevHashHex     string `json:"prev_hash_hex"`
+	UUIDHex         string `json:"uuid_hex"`
+}
+
+type LightnetStats struct {
+	TotalRecords int64 `json:"total_records"`
+}
+
+type MerkleRoot struct {
+	RootHashHex  string `json:"root_hash_hex"`
+	TotalRecords int64  `json:"total_records"`
+}
+
+// LightnetClient talks to Lightnet directly over gRPC, converting between hex
+// strings and the 32-byte fields of the wire protocol
+type LightnetClient struct {
+	conn        *grpc.ClientConn
+	client      lightnetpb.HashServiceClient
+	dataType    []byte
+	dialOptions []grpc.DialOption
+	insecure    bool
+	err         error
+}
+
+// LightnetOption configures a LightnetClient
+type LightnetOption func(*LightnetClient)
+
+// WithLightnetDataType sets the data type used when a call doesn't pass one
+// explicitly (defaults to DataType)
+func WithLightnetDataType(dataType string) LightnetOption {
+	return func(c *LightnetClient) {
+		b, err := decodeDataType(dataType)
+		if err != nil {
+			c.err = err
+			return
+		}
+		c.dataType = b
+	}
+}
+
+// WithDialOptions adds gRPC dial options, e.g. transport credentials
+// Without them DialLightnet connects over TLS with the system roots.
+func WithDialOptions(opts ...grpc.DialOption) LightnetOption {
+	return func(c *LightnetClient) {
+		c.dialOptions = append(c.dialOptions, opts...)
+	}
+}
+
+// WithInsecure makes DialLightnet connect without TLS, e.g. to a local
+// lightnetd
+func WithInsecure() LightnetOption {
+	return func(c *LightnetClient) {
+		c.insecure = true
+	}
+}
+
+// DialLightnet creates a LightnetClient connected to target (host:port)
+// It uses TLS unless WithInsecure is given.
+func DialLightnet(target string, opts ...LightnetOption) (*LightnetClient, error) {
+	c, err := newLightnetClient(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	creds := credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
+	if c.insecure {
+		creds = insecure.NewCredentials()
+	}
+	dialOptions := append([]grpc.DialOption{grpc.WithTransportCredentials(creds)}, c.dialOptions...)
+	conn, err := grpc.NewClient(target, dialOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial lightnet: %w", err)
+	}
+
+	c.conn = conn
+	c.client = lightnetpb.NewHashServiceClient(conn)
+	return c, nil
+}
+
+// NewLightnetClient wraps an existing HashServiceClient, e.g. one built on a
+// connection the caller manages
+func NewLightnetClient(client lightnetpb.HashServiceClient, opts ...LightnetOption) (*LightnetClient, error) {
+	c, err := newLightnetClient(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	c.client = client
+	return c, nil
+}
+
+func newLightnetClient(opts []LightnetOption) (*LightnetClient, error) {
+	dataType, _ := hex.DecodeString(DataType)
+	c := &LightnetClient{dataType: dataType}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c, nil
+}
+
+// Close closes the connection opened by DialLightnet
+// It does nothing for clients built with NewLightnetClient
+func (c *LightnetClient) Close() error {
+	if c.conn == nil {
+		return nil
+	}
+	return c.conn.Close()
+}
+
+// SubmitHash submits a 32-byte hash to Lightnet
+// dataType is optional and defaults to the client's data type
+func (c *LightnetClient) SubmitHash(ctx context.Context, dataItemHex string, dataType ...string) (*SingleHashResponse, error) {
+	req, err := c.hashRequest(dataItemHex, dataType)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.client.SubmitHash(ctx, req)
+	if err != nil {
+		return nil, newLightnetError("SubmitHash", err)
+	}
+	if !resp.GetSuccess() {
+		return nil, &LightnetError{Method: "SubmitHash", Code: codes.Unknown, Message: resp.GetMessage()}
+	}
+
+	return singleHashResponseFromProto(resp), nil
+}
+
+// GetRecord gets the record for a data item
+// dataType is optional and defaults to the client's data type
+func (c *LightnetClient) GetRecord(ctx context.Context, dataItemHex string, dataType ...string) (*DatabaseRecord, error) {
+	dt, item, err := c.dataTypeAndItem(dataItemHex, dataType)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.client.GetRecord(ctx, &lightnetpb.GetRecordRequest{DataType: dt, DataItem: item})
+	if err != nil {
+		return nil, newLightnetError("GetRecord", err)
+	}
+	if !resp.GetSuccess() {
+		return nil, &LightnetError{Method: "GetRecord", Code: codes.Unknown, Message: resp.GetMessage()}
+	}
+
+	return &DatabaseRecord{
+		DataType:    resp.GetDataTypeHex(),
+		DataItemHex: resp.GetDataItemHex(),
+		UUIDHex:     resp.GetUuidHex(),
+		HashItemHex: resp.GetHashItemHex(),
+		Timestamp:   resp.GetTimestamp(),
+	}, nil
+}
+
+// DebugHash returns how Lightnet would compute the hash for a data item,
+// without storing it
+// dataType is optional and defaults to the client's data type
+func (c *LightnetClient) DebugHash(ctx context.Context, dataItemHex string, dataType ...string) (*DebugHashResult, error) {
+	dt, item, err := c.dataTypeAndItem(dataItemHex, dataType)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.client.DebugHash(ctx, &lightnetpb.DebugHashRequest{DataType: dt, DataItem: item})
+	if err != nil {
+		return nil, newLightnetError("DebugHash", err)
+	}
+	if !resp.GetSuccess() {
+		return nil, &LightnetError{Method: "DebugHash", Code: codes.Unknown, Message: resp.GetMessage()}
+	}
+
+	return &DebugHashResult{
+		HashInputHex:    resp.GetHashInputHex(),
+		ComputedHashHex: resp.GetComputedHashHex(),
+		PrevHashHex:     resp.GetPrevHashHex(),
+		UUIDHex:         resp.GetUuidHex(),
+	}, nil
+}
+
+// GetDatabaseStats gets the Lightnet record count
+func (c *LightnetClient) GetDatabaseStats(ctx context.Context) (*LightnetStats, error) {
+	resp, err := c.client.GetDatabaseStats(ctx, &lightnetpb.DatabaseStatsRequest{})
+	if err != nil {
+		return nil, newLightnetError("GetDatabaseStats", err)
+	}
+	if !resp.GetSuccess() {
+		return nil, &LightnetError{Method: "GetDatabaseStats", Code: codes.Unknown, Message: resp.GetMessage()}
+	}
+
+	return &LightnetStats{TotalRecords: resp.GetTotalRecords()}, nil
+}
+
+// GetMerkleProof gets the Merkle proof for the hash at position
+func (c *LightnetClient) GetMerkleProof(ctx context.Context, position int64) (*MerkleProof, error) {
+	resp, err := c.client.GetMerkleProof(ctx, &lightnetpb.MerkleProofRequest{HashPosition: position})
+	if err != nil {
+		return nil, newLightnetError("GetMerkleProof", err)
+	}
+	if !resp.GetSuccess() {
+		return nil, &LightnetError{Method: "GetMerkleProof", Code: codes.Unknown, Message: resp.GetMessage()}
+	}
+
+	return &MerkleProof{
+		Position:       resp.GetPosition(),
+		RootHashHex:    resp.GetRootHashHex(),
+		ProofHashesHex: resp.GetProofHashesHex(),
+		Levels:         int(resp.GetLevels()),
+	}, nil
+}
+
+// GetMerkleRoot gets the current Merkle root
+func (c *LightnetClient) GetMerkleRoot(ctx context.Context) (*MerkleRoot, error) {
+	resp, err := c.client.GetMerkleRoot(ctx, &lightnetpb.MerkleRootRequest{})
+	if err != nil {
+		return nil, newLightnetError("GetMerkleRoot", err)
+	}
+	if !resp.GetSuccess() {
+		return nil, &LightnetError{Method: "GetMerkleRoot", Code: codes.Unknown, Message: resp.GetMessage()}
+	}
+
+	return &MerkleRoot{
+		RootHashHex:  resp.GetRootHashHex(),
+		TotalRecords: resp.GetTotalRecords(),
+	}, nil
+}
+
+// VerifyMerkleProof asks Lightnet to verify a Merkle proof
+func (c *LightnetClient) VerifyMerkleProof(ctx context.Context, request VerifyMerkleProofRequest) (*MerkleProofVerificationResult, error) {
+	if _, err := decodeHash32("target_hash_hex", request.TargetHashHex); err != nil {
+		return nil, err
+	}
+	if _, err := decodeHash32("root_hash_hex", request.RootHashHex); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.client.VerifyMerkleProof(ctx, &lightnetpb.VerifyMerkleProofRequest{
+		TargetHashHex:  request.TargetHashHex,
+		ProofHashesHex: request.ProofHashesHex,
+		Levels:         int32(request.Levels),
+		Position:       request.Position,
+		RootHashHex:    request.RootHashHex,
+	})
+	if err != nil {
+		return nil, newLightnetError("VerifyMerkleProof", err)
+	}
+	if !resp.GetSuccess() {
+		return nil, &LightnetError{Method: "VerifyMerkleProof", Code: codes.Unknown, Message: resp.GetMessage()}
+	}
+
+	return &MerkleProofVerificationResult{
+		Valid:         resp.GetIsValid(),
+		Message:       resp.GetMessage(),
+		TargetHashHex: request.TargetHashHex,
+		Position:      request.Position,
+	}, nil
+}
+
+// hashRequest builds a HashRequest for a data item
+func (c *LightnetClient) hashRequest(dataItemHex string, dataType []string) (*lightnetpb.HashRequest, error) {
+	dt, item, err := c.dataTypeAndItem(dataItemHex, dataType)
+	if err != nil {
+		return nil, err
+	}
+	return &lightnetpb.HashRequest{DataType: dt, DataItem: item}, nil
+}
+
+// dataTypeAndItem decodes a data item and the optional data type override
+func (c *LightnetClient) dataTypeAndItem(dataItemHex string, dataType []string) ([]byte, []byte, error) {
+	dt := c.dataType
+	if len(dataType) > 0 && dataType[0] != "" {
+		var err error
+		dt, err = decodeDataType(dataType[0])
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	item, err := decodeHash32("data_item", dataItemHex)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return dt, item, nil
+}
+
+func singleHashResponseFromProto(resp *lightnetpb.HashResponse) *SingleHashResponse {
+	return &SingleHashResponse{
+		Success:         resp.GetSuccess(),
+		Message:         resp.GetMessage(),
+		DataType:        resp.GetDataTypeHex(),
+		DataItem:        resp.GetDataItemHex(),
+		ComputedHashHex: resp.GetComputedHashHex(),
+		TimeuuidHex:     resp.GetTimeuuidHex(),
+		DataTypeHex:     resp.GetDataTypeHex(),
+		DataItemHex:     resp.GetDataItemHex(),
+	}
+}
+
+// decodeDataType validates and decodes a 64 hex character data type
+func decodeDataType(dataType string) ([]byte, error) {
+	if err := ValidateDataType(dataType); err != nil {
+		return nil, err
+	}
+	return hex.DecodeString(dataType)
+}
+
+// decodeHash32 decodes a 64 hex character value into 32 bytes
+func decodeHash32(name, value string) ([]byte, error) {
+	if len(value) != 64 {
+		return nil, fmt.Errorf("%w: %s must be exactly 64 hex characters (32 bytes), got %d characters", ErrInvalidHash, name, len(value))
+	}
+	b, err := hex.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s must contain only valid hex characters (0-9, a-f, A-F)", ErrInvalidHash, name)
+	}
+	return b, nil
+}
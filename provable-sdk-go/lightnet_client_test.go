@@ -0,0 +1,286 @@
+package provable
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	lightnetpb "github.com/provable/provable-sdk-go/proto/lightnet"
+)
+
+// stubHashServer records the last request and answers with canned responses
+type stubHashServer struct {
+	lightnetpb.UnimplementedHashServiceServer
+	lastHashRequest *lightnetpb.HashRequest
+	fail            string
+	code            codes.Code // status returned by GetRecord, if not OK
+}
+
+func (s *stubHashServer) SubmitHash(ctx context.Context, req *lightnetpb.HashRequest) (*lightnetpb.HashResponse, error) {
+	s.lastHashRequest = req
+	if s.fail != "" {
+		return &lightnetpb.HashResponse{Success: false, Message: s.fail}, nil
+	}
+	return &lightnetpb.HashResponse{
+		Success:         true,
+		ComputedHashHex: strings.Repeat("cd", 32),
+		TimeuuidHex:     strings.Repeat("01", 16),
+		DataTypeHex:     hex.EncodeToString(req.DataType),
+		DataItemHex:     hex.EncodeToString(req.DataItem),
+	}, nil
+}
+
+func (s *stubHashServer) GetRecord(ctx context.Context, req *lightnetpb.GetRecordRequest) (*lightnetpb.GetRecordResponse, error) {
+	if s.code != codes.OK {
+		return nil, status.Error(s.code, "record not found")
+	}
+	if s.fail != "" {
+		return &lightnetpb.GetRecordResponse{Success: false, Message: s.fail}, nil
+	}
+	return &lightnetpb.GetRecordResponse{
+		Success:     true,
+		UuidHex:     strings.Repeat("01", 16),
+		DataTypeHex: hex.EncodeToString(req.DataType),
+		DataItemHex: hex.EncodeToString(req.DataItem),
+		HashItemHex: strings.Repeat("cd", 32),
+		Timestamp:   "2025-01-01T00:00:00Z",
+	}, nil
+}
+
+func (s *stubHashServer) GetMerkleRoot(ctx context.Context, req *lightnetpb.MerkleRootRequest) (*lightnetpb.MerkleRootResponse, error) {
+	return &lightnetpb.MerkleRootResponse{Success: true, RootHashHex: strings.Repeat("ee", 32), TotalRecords: 7}, nil
+}
+
+func (s *stubHashServer) GetMerkleProof(ctx context.Context, req *lightnetpb.MerkleProofRequest) (*lightnetpb.MerkleProofResponse, error) {
+	return nil, status.Error(codes.Internal, "tree unavailable")
+}
+
+// startBufconnServer serves srv over an in-memory listener
+func startBufconnServer(t *testing.T, srv lightnetpb.HashServiceServer) *bufconn.Listener {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	lightnetpb.RegisterHashServiceServer(server, srv)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+	return lis
+}
+
+// dialBufconn returns a LightnetClient connected to lis
+func dialBufconn(t *testing.T, lis *bufconn.Listener, opts ...LightnetOption) *LightnetClient {
+	t.Helper()
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("grpc.NewClient() error = %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	c, err := NewLightnetClient(lightnetpb.NewHashServiceClient(conn), opts...)
+	if err != nil {
+		t.Fatalf("NewLightnetClient() error = %v", err)
+	}
+	return c
+}
+
+func newStubLightnetClient(t *testing.T, srv *stubHashServer, opts ...LightnetOption) *LightnetClient {
+	t.Helper()
+	return dialBufconn(t, startBufconnServer(t, srv), opts...)
+}
+
+func TestDialLightnet(t *testing.T) {
+	ctx := context.Background()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() error = %v", err)
+	}
+	server := grpc.NewServer()
+	lightnetpb.RegisterHashServiceServer(server, &stubHashServer{})
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	t.Run("use TLS by default", func(t *testing.T) {
+		c, err := DialLightnet(lis.Addr().String())
+		if err != nil {
+			t.Fatalf("DialLightnet() error = %v", err)
+		}
+		defer c.Close()
+		if _, err := c.GetMerkleRoot(ctx); status.Code(errors.Unwrap(err)) != codes.Unavailable {
+			t.Errorf("GetMerkleRoot() error = %v, want codes.Unavailable from a plaintext server", err)
+		}
+	})
+
+	t.Run("connect without TLS when asked", func(t *testing.T) {
+		c, err := DialLightnet(lis.Addr().String(), WithInsecure())
+		if err != nil {
+			t.Fatalf("DialLightnet() error = %v", err)
+		}
+		defer c.Close()
+		if _, err := c.GetMerkleRoot(ctx); err != nil {
+			t.Errorf("GetMerkleRoot() error = %v", err)
+		}
+	})
+}
+
+func TestLightnetClientSubmitHash(t *testing.T) {
+	ctx := context.Background()
+	dataItem := Keccak256Str("hello")
+
+	t.Run("convert hex to 32-byte fields", func(t *testing.T) {
+		srv := &stubHashServer{}
+		c := newStubLightnetClient(t, srv)
+
+		resp, err := c.SubmitHash(ctx, dataItem)
+		if err != nil {
+			t.Fatalf("SubmitHash() error = %v", err)
+		}
+		wantType, _ := hex.DecodeString(DataType)
+		if !bytes.Equal(srv.lastHashRequest.DataType, wantType) {
+			t.Errorf("data_type = %x, want %s", srv.lastHashRequest.DataType, DataType)
+		}
+		if len(srv.lastHashRequest.DataItem) != 32 {
+			t.Errorf("data_item length = %d, want 32", len(srv.lastHashRequest.DataItem))
+		}
+		if resp.DataItemHex != dataItem {
+			t.Errorf("DataItemHex = %v, want %v", resp.DataItemHex, dataItem)
+		}
+		if resp.ComputedHashHex != strings.Repeat("cd", 32) {
+			t.Errorf("ComputedHashHex = %v", resp.ComputedHashHex)
+		}
+	})
+
+	t.Run("use configured data type", func(t *testing.T) {
+		srv := &stubHashServer{}
+		customDataType := strings.Repeat("ab", 32)
+		c := newStubLightnetClient(t, srv, WithLightnetDataType(customDataType))
+
+		if _, err := c.SubmitHash(ctx, dataItem); err != nil {
+			t.Fatalf("SubmitHash() error = %v", err)
+		}
+		if hex.EncodeToString(srv.lastHashRequest.DataType) != customDataType {
+			t.Errorf("data_type = %x, want %s", srv.lastHashRequest.DataType, customDataType)
+		}
+	})
+
+	t.Run("reject invalid data item", func(t *testing.T) {
+		c := newStubLightnetClient(t, &stubHashServer{})
+		if _, err := c.SubmitHash(ctx, "abc"); !errors.Is(err, ErrInvalidHash) {
+			t.Errorf("SubmitHash() error = %v, want ErrInvalidHash", err)
+		}
+		if _, err := c.SubmitHash(ctx, strings.Repeat("zz", 32)); !errors.Is(err, ErrInvalidHash) {
+			t.Errorf("SubmitHash() error = %v, want ErrInvalidHash", err)
+		}
+	})
+
+	t.Run("reject invalid data type", func(t *testing.T) {
+		c := newStubLightnetClient(t, &stubHashServer{})
+		if _, err := c.SubmitHash(ctx, dataItem, "short"); !errors.Is(err, ErrInvalidDataType) {
+			t.Errorf("SubmitHash() error = %v, want ErrInvalidDataType", err)
+		}
+		if _, err := NewLightnetClient(nil, WithLightnetDataType("short")); !errors.Is(err, ErrInvalidDataType) {
+			t.Errorf("NewLightnetClient() error = %v, want ErrInvalidDataType", err)
+		}
+	})
+
+	t.Run("map success=false to LightnetError", func(t *testing.T) {
+		c := newStubLightnetClient(t, &stubHashServer{fail: "database unavailable"})
+		_, err := c.SubmitHash(ctx, dataItem)
+		var lnErr *LightnetError
+		if !errors.As(err, &lnErr) {
+			t.Fatalf("SubmitHash() error = %v, want *LightnetError", err)
+		}
+		if lnErr.Method != "SubmitHash" || lnErr.Message != "database unavailable" {
+			t.Errorf("LightnetError = %+v", lnErr)
+		}
+	})
+}
+
+func TestLightnetClientGetRecord(t *testing.T) {
+	ctx := context.Background()
+	dataItem := Keccak256Str("hello")
+
+	t.Run("return record", func(t *testing.T) {
+		c := newStubLightnetClient(t, &stubHashServer{})
+		record, err := c.GetRecord(ctx, dataItem)
+		if err != nil {
+			t.Fatalf("GetRecord() error = %v", err)
+		}
+		if record.DataItemHex != dataItem || record.DataType != DataType {
+			t.Errorf("record = %+v", record)
+		}
+		if record.HashItemHex != strings.Repeat("cd", 32) {
+			t.Errorf("HashItemHex = %v", record.HashItemHex)
+		}
+	})
+
+	t.Run("match ErrNotFound", func(t *testing.T) {
+		c := newStubLightnetClient(t, &stubHashServer{code: codes.NotFound})
+		_, err := c.GetRecord(ctx, dataItem)
+		if !errors.Is(err, ErrNotFound) {
+			t.Errorf("GetRecord() error = %v, want ErrNotFound", err)
+		}
+		var lnErr *LightnetError
+		if !errors.As(err, &lnErr) || lnErr.Method != "GetRecord" || lnErr.Code != codes.NotFound {
+			t.Errorf("GetRecord() error = %#v, want *LightnetError with codes.NotFound", err)
+		}
+	})
+
+	t.Run("match ErrNotFound on the status code only", func(t *testing.T) {
+		c := newStubLightnetClient(t, &stubHashServer{fail: "Record not found"})
+		if _, err := c.GetRecord(ctx, dataItem); err == nil || errors.Is(err, ErrNotFound) {
+			t.Errorf("GetRecord() error = %v, want an error not matching ErrNotFound", err)
+		}
+		c = newStubLightnetClient(t, &stubHashServer{code: codes.PermissionDenied})
+		if _, err := c.GetRecord(ctx, dataItem); err == nil || errors.Is(err, ErrNotFound) {
+			t.Errorf("GetRecord() error = %v, want an error not matching ErrNotFound", err)
+		}
+	})
+}
+
+func TestLightnetClientMerkle(t *testing.T) {
+	ctx := context.Background()
+	c := newStubLightnetClient(t, &stubHashServer{})
+
+	t.Run("get root", func(t *testing.T) {
+		root, err := c.GetMerkleRoot(ctx)
+		if err != nil {
+			t.Fatalf("GetMerkleRoot() error = %v", err)
+		}
+		if root.TotalRecords != 7 || root.RootHashHex != strings.Repeat("ee", 32) {
+			t.Errorf("root = %+v", root)
+		}
+	})
+
+	t.Run("keep gRPC status on transport errors", func(t *testing.T) {
+		_, err := c.GetMerkleProof(ctx, 3)
+		if status.Code(errors.Unwrap(err)) != codes.Internal {
+			t.Errorf("GetMerkleProof() error = %v, want codes.Internal", err)
+		}
+	})
+
+	t.Run("report unimplemented RPCs", func(t *testing.T) {
+		_, err := c.GetDatabaseStats(ctx)
+		if status.Code(errors.Unwrap(err)) != codes.Unimplemented {
+			t.Errorf("GetDatabaseStats() error = %v, want codes.Unimplemented", err)
+		}
+	})
+
+	t.Run("validate proof hashes before calling", func(t *testing.T) {
+		_, err := c.VerifyMerkleProof(ctx, VerifyMerkleProofRequest{TargetHashHex: "abc"})
+		if !errors.Is(err, ErrInvalidHash) {
+			t.Errorf("VerifyMerkleProof() error = %v, want ErrInvalidHash", err)
+		}
+	})
+}
@@ -0,0 +1,157 @@
+package provable
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newLightnetTestClient(t *testing.T, responses map[string]string) *Client {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := responses[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := NewClient(WithBaseURL(server.URL), WithRetryPolicy(NoRetry))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	return c
+}
+
+func TestLightnetTypedResponses(t *testing.T) {
+	c := newLightnetTestClient(t, map[string]string{
+		"/api/database/query":   `{"success":true,"data":[{"timestamp":"2025-01-01T00:00:00Z","data_type":"aa","data_item":"bb","hash_type":"blake3","hash_item":"cc"}]}`,
+		"/api/database/stats":   `{"success":true,"data":{"total_hashes":42,"count_by_type":{"aa":40,"bb":2},"min_timestamp":"t0","max_timestamp":"t1"}}`,
+		"/api/database/tables":  `{"success":true,"data":["hashes","merkle"]}`,
+		"/api/database/schema":  `{"success":true,"data":[{"name":"uuid","type":"timeuuid"}]}`,
+		"/api/database/browse":  `{"success":true,"data":[{"uuid":"u1","n":1}]}`,
+		"/api/database/record":  `{"success":true,"data":{"data_type":"aa","data_item_hex":"bb","uuid_hex":"u1","hash_item_hex":"cc","hash_type":"blake3","timestamp":"t0"}}`,
+		"/api/verify-hash":      `{"success":true,"data":{"computed_hash":"dd","hash_input_hex":"ee"}}`,
+		"/api/grpc/single-hash": `{"success":true,"data":{"success":true,"computed_hash_hex":"cc","timeuuid_hex":"u1"}}`,
+		"/api/merkle/generate-proof": `{"success":true,"data":{"target_hash_hex":"cc","position":5,"root_hash_hex":"ff",` +
+			`"proof_hashes_hex":["01","02"],"levels":2,"proof_format":"sparse"}}`,
+		"/api/merkle/verify-proof": `{"success":true,"data":{"valid":true,"computed_root_hex":"ff","position":5}}`,
+	})
+
+	t.Run("decode hash records", func(t *testing.T) {
+		records, err := c.QueryHashes(DatabaseQuery{Limit: 10})
+		if err != nil {
+			t.Fatalf("QueryHashes() error = %v", err)
+		}
+		if len(records) != 1 || records[0].HashItem != "cc" || records[0].HashType != "blake3" {
+			t.Errorf("QueryHashes() = %+v", records)
+		}
+	})
+
+	t.Run("decode stats", func(t *testing.T) {
+		stats, err := c.GetDatabaseStats()
+		if err != nil {
+			t.Fatalf("GetDatabaseStats() error = %v", err)
+		}
+		if stats.TotalHashes != 42 || stats.CountByType["aa"] != 40 {
+			t.Errorf("GetDatabaseStats() = %+v", stats)
+		}
+	})
+
+	t.Run("decode tables, schema and rows", func(t *testing.T) {
+		tables, err := c.GetTables()
+		if err != nil || len(tables) != 2 || tables[1] != "merkle" {
+			t.Errorf("GetTables() = %v, %v", tables, err)
+		}
+		columns, err := c.GetTableSchema("hashes")
+		if err != nil || len(columns) != 1 || columns[0].Type != "timeuuid" {
+			t.Errorf("GetTableSchema() = %v, %v", columns, err)
+		}
+		rows, err := c.BrowseTable(TableBrowseRequest{TableName: "hashes"})
+		if err != nil || len(rows) != 1 || rows[0]["uuid"] != "u1" {
+			t.Errorf("BrowseTable() = %v, %v", rows, err)
+		}
+	})
+
+	t.Run("decode records and hash results", func(t *testing.T) {
+		record, err := c.GetRecord("u1")
+		if err != nil || record.UUIDHex != "u1" || record.HashItemHex != "cc" {
+			t.Errorf("GetRecord() = %+v, %v", record, err)
+		}
+		result, err := c.VerifyHash(HashVerifyRequest{})
+		if err != nil || result.ComputedHash != "dd" {
+			t.Errorf("VerifyHash() = %+v, %v", result, err)
+		}
+		resp, err := c.SendSingleGRPCRequest(SingleHashRequest{})
+		if err != nil || resp.TimeuuidHex != "u1" {
+			t.Errorf("SendSingleGRPCRequest() = %+v, %v", resp, err)
+		}
+	})
+
+	t.Run("decode Merkle proofs", func(t *testing.T) {
+		proof, err := c.GenerateMerkleProof(GenerateMerkleProofRequest{HashItem: "cc"})
+		if err != nil {
+			t.Fatalf("GenerateMerkleProof() error = %v", err)
+		}
+		if proof.Position != 5 || proof.Levels != 2 || len(proof.ProofHashesHex) != 2 {
+			t.Errorf("GenerateMerkleProof() = %+v", proof)
+		}
+		result, err := c.VerifyMerkleProof(VerifyMerkleProofRequest{})
+		if err != nil || !result.Valid || result.ComputedRootHex != "ff" {
+			t.Errorf("VerifyMerkleProof() = %+v, %v", result, err)
+		}
+	})
+
+	t.Run("report mismatched payloads as decode errors", func(t *testing.T) {
+		c := newLightnetTestClient(t, map[string]string{"/api/database/stats": `{"success":true,"data":["not","stats"]}`})
+		if _, err := c.GetDatabaseStats(); !errors.Is(err, ErrDecodeResponse) {
+			t.Errorf("GetDatabaseStats() error = %v, want ErrDecodeResponse", err)
+		}
+	})
+
+	t.Run("return APIError for success=false", func(t *testing.T) {
+		c := newLightnetTestClient(t, map[string]string{"/api/database/record": `{"success":false,"error":"Record not found"}`})
+		var apiErr *APIError
+		if _, err := c.GetRecord("u1"); !errors.As(err, &apiErr) || apiErr.Message != "Record not found" {
+			t.Errorf("GetRecord() error = %v, want APIError", err)
+		}
+	})
+}
+
+func TestDecodeData(t *testing.T) {
+	t.Run("convert generic data", func(t *testing.T) {
+		resp := &APIResponse{Success: true, Data: map[string]interface{}{"total_hashes": float64(7)}}
+		stats, err := DecodeData[DatabaseStats](resp)
+		if err != nil {
+			t.Fatalf("DecodeData() error = %v", err)
+		}
+		if stats.TotalHashes != 7 {
+			t.Errorf("TotalHashes = %v, want 7", stats.TotalHashes)
+		}
+	})
+
+	t.Run("return typed data as-is", func(t *testing.T) {
+		want := &MerkleProof{Levels: 3}
+		got, err := DecodeData[*MerkleProof](&APIResponse{Data: want})
+		if err != nil || got != want {
+			t.Errorf("DecodeData() = %v, %v, want %v", got, err, want)
+		}
+	})
+
+	t.Run("return zero value without data", func(t *testing.T) {
+		records, err := DecodeData[[]HashRecord](&APIResponse{Success: true})
+		if err != nil || records != nil {
+			t.Errorf("DecodeData() = %v, %v, want nil", records, err)
+		}
+	})
+
+	t.Run("wrap decode failures", func(t *testing.T) {
+		_, err := DecodeData[[]HashRecord](&APIResponse{Data: map[string]interface{}{"a": 1}})
+		if !errors.Is(err, ErrDecodeResponse) {
+			t.Errorf("DecodeData() error = %v, want ErrDecodeResponse", err)
+		}
+	})
+}
@@ -0,0 +1,105 @@
+package provable
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/zeebo/blake3"
+)
+
+// MerkleProofSize is the number of sibling hashes in a Lightnet Merkle proof
+// Only the first Levels entries are used; the rest are padding.
+const MerkleProofSize = 256
+
+// merkleParent hashes two sibling nodes as BLAKE3(left || right)
+// This, the zero-hash empty sibling and the position bit order documented on
+// ComputeMerkleRoot are what the SDK assumes Lightnet does; no published
+// spec states them. TestMerkleFixtures checks them against Lightnet proofs
+// captured in testdata/lightnet/merkle, and fails until they are captured.
+func merkleParent(left, right []byte) []byte {
+	h := blake3.New()
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
+
+// ComputeMerkleRoot recomputes the root reached from targetHashHex by walking
+// up levels of proofHashesHex
+//
+// At level i the sibling is proofHashesHex[i], and bit i of position says
+// whether the current node is the left (0) or right (1) child. An empty
+// sibling stands for an empty subtree and hashes as 32 zero bytes, which is
+// how the 256-entry sparse layout encodes missing nodes.
+func ComputeMerkleRoot(targetHashHex string, proofHashesHex []string, levels int, position int64) (string, error) {
+	if levels < 0 || levels > MerkleProofSize {
+		return "", fmt.Errorf("%w: levels must be between 0 and %d, got %d", ErrInvalidMerkleProof, MerkleProofSize, levels)
+	}
+	if levels > len(proofHashesHex) {
+		return "", fmt.Errorf("%w: %d levels but only %d proof hashes", ErrInvalidMerkleProof, levels, len(proofHashesHex))
+	}
+	if position < 0 || (levels < 63 && position >= int64(1)<<levels) {
+		return "", fmt.Errorf("%w: position %d out of range for %d levels", ErrInvalidMerkleProof, position, levels)
+	}
+
+	node, err := decodeHash32("target_hash_hex", targetHashHex)
+	if err != nil {
+		return "", err
+	}
+
+	zero := make([]byte, 32)
+	for i := 0; i < levels; i++ {
+		sibling := zero
+		if proofHashesHex[i] != "" {
+			sibling, err = decodeHash32(fmt.Sprintf("proof_hashes_hex[%d]", i), proofHashesHex[i])
+			if err != nil {
+				return "", err
+			}
+		}
+
+		if i < 63 && position>>i&1 == 1 {
+			node = merkleParent(sibling, node)
+		} else {
+			node = merkleParent(node, sibling)
+		}
+	}
+
+	return hex.EncodeToString(node), nil
+}
+
+// VerifyMerkleProofLocal checks a Merkle proof without any network access by
+// recomputing the root and comparing it with proof.RootHashHex
+// (or proof.StoredRootHex when RootHashHex is empty)
+//
+// An error is returned only for a malformed proof; a well-formed proof that
+// doesn't lead to the expected root yields a result with Valid false.
+func VerifyMerkleProofLocal(proof MerkleProof) (*MerkleProofVerificationResult, error) {
+	expected := proof.RootHashHex
+	if expected == "" {
+		expected = proof.StoredRootHex
+	}
+	expectedRoot, err := decodeHash32("root_hash_hex", expected)
+	if err != nil {
+		return nil, err
+	}
+
+	computed, err := ComputeMerkleRoot(proof.TargetHashHex, proof.ProofHashesHex, proof.Levels, proof.Position)
+	if err != nil {
+		return nil, err
+	}
+	computedRoot, _ := hex.DecodeString(computed)
+
+	result := &MerkleProofVerificationResult{
+		Valid:           bytes.Equal(computedRoot, expectedRoot),
+		ComputedRootHex: computed,
+		StoredRootHex:   expected,
+		TargetHashHex:   proof.TargetHashHex,
+		Position:        proof.Position,
+	}
+	if result.Valid {
+		result.Message = "Merkle proof is valid"
+	} else {
+		result.Message = "computed root does not match expected root"
+	}
+	return result, nil
+}
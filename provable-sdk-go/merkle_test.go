@@ -0,0 +1,361 @@
+package provable
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// Regression vectors for a tree over Keccak256Str("leaf-0") …
+// Keccak256Str("leaf-4"), padded with zero leaves to 8 (3 levels), proofs in
+// the 256-entry layout
+// They were generated by merkleTestTree, not captured from Lightnet, so they pin
+// the rules documented on merkleParent and ComputeMerkleRoot but can't show
+// the service follows them; TestMerkleFixtures does that.
+const merkleTestRoot = "a90a0d39770d48bee0ca343708023572142f1a57985219d3ffa4a793e3d83a89"
+
+var merkleTestVectors = []struct {
+	name     string
+	position int64
+	target   string
+	siblings []string
+}{
+	{
+		name:     "leftmost leaf",
+		position: 0,
+		target:   "da88faf89b518eb4774583fa174f46d7714a1097c24c6bd5357a594d62eec21e",
+		siblings: []string{
+			"350bb3dca2efdb96db44fe0ad0417cf25bfe6be8ef4c46499b2585bd7001b9f2",
+			"f0c045a89cae7ff08d74ca3c33340d1d582c81be9ad56af8f533ad261698fc71",
+			"01786bcfc02126b6078b598635b0aa0e3613d01d4882fccfc3f6166fc81a513e",
+		},
+	},
+	{
+		name:     "right child at every level below the root",
+		position: 3,
+		target:   "a0bf632ceb4a2deaac20013613dbf0f70379230f7abcabae85fad54388560d0c",
+		siblings: []string{
+			"10a9efebd232336dd0f7ce1952e6b764c03ab6fc7f81abd938fe95db2a31aaae",
+			"60304ec0e42de8b13bb86afd107f3afe571055d312164842c78677de373f10dd",
+			"01786bcfc02126b6078b598635b0aa0e3613d01d4882fccfc3f6166fc81a513e",
+		},
+	},
+	{
+		name:     "empty sibling in sparse layout",
+		position: 4,
+		target:   "0c165b804a4294c8f1b189940bb8b69b41a807ec46741112fd60df7dd62c8ea1",
+		siblings: []string{
+			"",
+			"4d006976636a8696d909a630a4081aad4d7c50f81afdee04020bf05086ab6a55",
+			"e553f27a6557e207ff6207828469f89fddc499448bd45f5edc1d856f8c4c8067",
+		},
+	},
+}
+
+// sparseProof pads siblings to the 256-entry layout
+func sparseProof(siblings []string) []string {
+	proof := make([]string, MerkleProofSize)
+	copy(proof, siblings)
+	return proof
+}
+
+func TestVerifyMerkleProofLocal(t *testing.T) {
+	for _, v := range merkleTestVectors {
+		t.Run(v.name, func(t *testing.T) {
+			result, err := VerifyMerkleProofLocal(MerkleProof{
+				TargetHashHex:  v.target,
+				Position:       v.position,
+				RootHashHex:    merkleTestRoot,
+				ProofHashesHex: sparseProof(v.siblings),
+				Levels:         len(v.siblings),
+			})
+			if err != nil {
+				t.Fatalf("VerifyMerkleProofLocal() error = %v", err)
+			}
+			if !result.Valid {
+				t.Errorf("VerifyMerkleProofLocal() Valid = false, computed root %s, want %s", result.ComputedRootHex, merkleTestRoot)
+			}
+			if result.TargetHashHex != v.target || result.Position != v.position {
+				t.Errorf("result = %+v", result)
+			}
+		})
+	}
+
+	v := merkleTestVectors[1]
+
+	t.Run("reject wrong position", func(t *testing.T) {
+		result, err := VerifyMerkleProofLocal(MerkleProof{
+			TargetHashHex:  v.target,
+			Position:       2,
+			RootHashHex:    merkleTestRoot,
+			ProofHashesHex: sparseProof(v.siblings),
+			Levels:         len(v.siblings),
+		})
+		if err != nil {
+			t.Fatalf("VerifyMerkleProofLocal() error = %v", err)
+		}
+		if result.Valid {
+			t.Error("VerifyMerkleProofLocal() Valid = true, want false")
+		}
+		if result.ComputedRootHex == merkleTestRoot {
+			t.Error("ComputedRootHex should differ from the root")
+		}
+	})
+
+	t.Run("reject tampered sibling", func(t *testing.T) {
+		siblings := append([]string(nil), v.siblings...)
+		siblings[1] = strings.Repeat("00", 32)
+		result, err := VerifyMerkleProofLocal(MerkleProof{
+			TargetHashHex:  v.target,
+			Position:       v.position,
+			RootHashHex:    merkleTestRoot,
+			ProofHashesHex: sparseProof(siblings),
+			Levels:         len(siblings),
+		})
+		if err != nil {
+			t.Fatalf("VerifyMerkleProofLocal() error = %v", err)
+		}
+		if result.Valid {
+			t.Error("VerifyMerkleProofLocal() Valid = true, want false")
+		}
+	})
+
+	t.Run("fall back to stored root", func(t *testing.T) {
+		result, err := VerifyMerkleProofLocal(MerkleProof{
+			TargetHashHex:  v.target,
+			Position:       v.position,
+			StoredRootHex:  merkleTestRoot,
+			ProofHashesHex: v.siblings,
+			Levels:         len(v.siblings),
+		})
+		if err != nil {
+			t.Fatalf("VerifyMerkleProofLocal() error = %v", err)
+		}
+		if !result.Valid {
+			t.Error("VerifyMerkleProofLocal() Valid = false, want true")
+		}
+	})
+
+	t.Run("reject malformed proofs", func(t *testing.T) {
+		tests := []struct {
+			name  string
+			proof MerkleProof
+			want  error
+		}{
+			{"levels beyond proof", MerkleProof{TargetHashHex: v.target, RootHashHex: merkleTestRoot, ProofHashesHex: v.siblings, Levels: 4}, ErrInvalidMerkleProof},
+			{"levels beyond 256", MerkleProof{TargetHashHex: v.target, RootHashHex: merkleTestRoot, ProofHashesHex: sparseProof(nil), Levels: 257}, ErrInvalidMerkleProof},
+			{"position beyond tree", MerkleProof{TargetHashHex: v.target, RootHashHex: merkleTestRoot, ProofHashesHex: v.siblings, Levels: 3, Position: 8}, ErrInvalidMerkleProof},
+			{"invalid target", MerkleProof{TargetHashHex: "abc", RootHashHex: merkleTestRoot, ProofHashesHex: v.siblings, Levels: 3}, ErrInvalidHash},
+			{"invalid sibling", MerkleProof{TargetHashHex: v.target, RootHashHex: merkleTestRoot, ProofHashesHex: []string{"zz"}, Levels: 1}, ErrInvalidHash},
+			{"missing root", MerkleProof{TargetHashHex: v.target, ProofHashesHex: v.siblings, Levels: 3}, ErrInvalidHash},
+		}
+		for _, tt := range tests {
+			if _, err := VerifyMerkleProofLocal(tt.proof); !errors.Is(err, tt.want) {
+				t.Errorf("%s: VerifyMerkleProofLocal() error = %v, want %v", tt.name, err, tt.want)
+			}
+		}
+	})
+}
+
+func TestComputeMerkleRoot(t *testing.T) {
+	t.Run("return target with zero levels", func(t *testing.T) {
+		target := Keccak256Str("leaf-0")
+		root, err := ComputeMerkleRoot(target, nil, 0, 0)
+		if err != nil {
+			t.Fatalf("ComputeMerkleRoot() error = %v", err)
+		}
+		if root != target {
+			t.Errorf("ComputeMerkleRoot() = %v, want %v", root, target)
+		}
+	})
+
+	t.Run("ignore entries past levels", func(t *testing.T) {
+		v := merkleTestVectors[0]
+		proof := sparseProof(v.siblings)
+		proof[3] = "not-a-hash"
+		root, err := ComputeMerkleRoot(v.target, proof, 3, v.position)
+		if err != nil {
+			t.Fatalf("ComputeMerkleRoot() error = %v", err)
+		}
+		if root != merkleTestRoot {
+			t.Errorf("ComputeMerkleRoot() = %v, want %v", root, merkleTestRoot)
+		}
+	})
+}
+
+func TestMerkleTree(t *testing.T) {
+	var leaves []string
+	for i := range 5 {
+		leaves = append(leaves, Keccak256Str(fmt.Sprintf("leaf-%d", i)))
+	}
+	tree, err := newMerkleTestTree(leaves)
+	if err != nil {
+		t.Fatalf("newMerkleTestTree() error = %v", err)
+	}
+
+	t.Run("match the regression vectors", func(t *testing.T) {
+		if tree.Root() != merkleTestRoot || tree.Levels() != 3 || tree.Len() != 5 {
+			t.Errorf("tree = %s with %d levels and %d leaves, want %s with 3 levels and 5 leaves", tree.Root(), tree.Levels(), tree.Len(), merkleTestRoot)
+		}
+		for _, v := range merkleTestVectors {
+			proof, err := tree.Proof(v.position)
+			if err != nil {
+				t.Fatalf("%s: Proof() error = %v", v.name, err)
+			}
+			if proof.TargetHashHex != v.target || !slices.Equal(proof.ProofHashesHex, sparseProof(v.siblings)) {
+				t.Errorf("%s: Proof() = %v, want siblings %v", v.name, proof.ProofHashesHex[:3], v.siblings)
+			}
+		}
+	})
+
+	t.Run("produce proofs that verify", func(t *testing.T) {
+		for i := range leaves {
+			proof, _ := tree.Proof(int64(i))
+			if result, err := VerifyMerkleProofLocal(*proof); err != nil || !result.Valid {
+				t.Errorf("VerifyMerkleProofLocal(Proof(%d)) = %+v, %v, want valid", i, result, err)
+			}
+		}
+	})
+
+	t.Run("use a single leaf as the root", func(t *testing.T) {
+		single, _ := newMerkleTestTree(leaves[:1])
+		if single.Root() != leaves[0] || single.Levels() != 0 {
+			t.Errorf("Root() = %s, want %s", single.Root(), leaves[0])
+		}
+	})
+
+	t.Run("reject bad leaves and positions", func(t *testing.T) {
+		if _, err := newMerkleTestTree(nil); !errors.Is(err, ErrInvalidMerkleProof) {
+			t.Errorf("newMerkleTestTree(nil) error = %v, want ErrInvalidMerkleProof", err)
+		}
+		if _, err := newMerkleTestTree([]string{"ab"}); !errors.Is(err, ErrInvalidHash) {
+			t.Errorf("newMerkleTestTree() error = %v, want ErrInvalidHash", err)
+		}
+		if _, err := tree.Proof(5); !errors.Is(err, ErrInvalidMerkleProof) {
+			t.Errorf("Proof(5) error = %v, want ErrInvalidMerkleProof", err)
+		}
+	})
+}
+
+// merkleFixture is a Lightnet generate-proof response with the verify-proof
+// response for it, as captured by TestMerkleConformanceIntegration
+type merkleFixture struct {
+	RecordHash string                        `json:"record_hash"`
+	Proof      MerkleProof                   `json:"generate_proof"`
+	Result     MerkleProofVerificationResult `json:"verify_proof"`
+}
+
+// TestMerkleFixtures checks VerifyMerkleProofLocal against proofs captured
+// from Lightnet in testdata/lightnet/merkle
+func TestMerkleFixtures(t *testing.T) {
+	paths := requireFixtures(t, "merkle")
+	for _, path := range paths {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			var fixture merkleFixture
+			readFixture(t, path, &fixture)
+			if !fixture.Result.Valid {
+				t.Fatalf("captured verify-proof = %+v, want Lightnet to accept its own proof", fixture.Result)
+			}
+			proof := fixture.Proof
+			if proof.TargetHashHex == "" {
+				proof.TargetHashHex = fixture.RecordHash
+			}
+			local, err := VerifyMerkleProofLocal(proof)
+			if err != nil {
+				t.Fatalf("VerifyMerkleProofLocal() error = %v", err)
+			}
+			if !local.Valid {
+				t.Errorf("VerifyMerkleProofLocal() = %+v, want valid", local)
+			}
+			if want := fixture.Result.ComputedRootHex; want != "" && !strings.EqualFold(local.ComputedRootHex, want) {
+				t.Errorf("ComputedRootHex = %s, want %s", local.ComputedRootHex, want)
+			}
+		})
+	}
+}
+
+// merkleTestTree is a Merkle tree over 32-byte leaves, hashed with
+// merkleParent and padded with 32 zero bytes up to a power of two, so proofs
+// from Proof verify with ComputeMerkleRoot and VerifyMerkleProofLocal
+type merkleTestTree struct {
+	// nodes[0] holds the padded leaves and nodes[len(nodes)-1] the root
+	nodes  [][][]byte
+	leaves int
+}
+
+// newMerkleTestTree builds a tree over leavesHex, in order
+func newMerkleTestTree(leavesHex []string) (*merkleTestTree, error) {
+	if len(leavesHex) == 0 {
+		return nil, fmt.Errorf("%w: no leaves", ErrInvalidMerkleProof)
+	}
+	width := 1
+	for width < len(leavesHex) {
+		width *= 2
+	}
+	level := make([][]byte, width)
+	for i := range level {
+		level[i] = make([]byte, 32)
+	}
+	for i, leaf := range leavesHex {
+		node, err := decodeHash32(fmt.Sprintf("leaves[%d]", i), leaf)
+		if err != nil {
+			return nil, err
+		}
+		level[i] = node
+	}
+
+	t := &merkleTestTree{nodes: [][][]byte{level}, leaves: len(leavesHex)}
+	for len(level) > 1 {
+		parents := make([][]byte, len(level)/2)
+		for i := range parents {
+			parents[i] = merkleParent(level[2*i], level[2*i+1])
+		}
+		t.nodes = append(t.nodes, parents)
+		level = parents
+	}
+	return t, nil
+}
+
+// Len returns the number of leaves
+func (t *merkleTestTree) Len() int {
+	return t.leaves
+}
+
+// Levels returns the number of levels between the leaves and the root
+func (t *merkleTestTree) Levels() int {
+	return len(t.nodes) - 1
+}
+
+// Root returns the root hash as hex
+func (t *merkleTestTree) Root() string {
+	return hex.EncodeToString(t.nodes[len(t.nodes)-1][0])
+}
+
+// Proof returns the proof for the leaf at position, with ProofHashesHex padded
+// to MerkleProofSize entries and padding leaves left empty
+func (t *merkleTestTree) Proof(position int64) (*MerkleProof, error) {
+	if position < 0 || position >= int64(t.Len()) {
+		return nil, fmt.Errorf("%w: position %d out of range for %d leaves", ErrInvalidMerkleProof, position, t.Len())
+	}
+
+	siblings := make([]string, MerkleProofSize)
+	index := position
+	for level := 0; level < t.Levels(); level++ {
+		if sibling := index ^ 1; level > 0 || sibling < int64(t.leaves) {
+			siblings[level] = hex.EncodeToString(t.nodes[level][sibling])
+		}
+		index /= 2
+	}
+	return &MerkleProof{
+		TargetHashHex:  hex.EncodeToString(t.nodes[0][position]),
+		Position:       position,
+		RootHashHex:    t.Root(),
+		ProofHashesHex: siblings,
+		Levels:         t.Levels(),
+		StoredRootHex:  t.Root(),
+	}, nil
+}
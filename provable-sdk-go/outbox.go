@@ -0,0 +1,536 @@
+package provable
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultOutboxConcurrency is how many hashes an Outbox proves at once unless
+// WithOutboxConcurrency is used
+const DefaultOutboxConcurrency = 4
+
+// DefaultOutboxBackoff is how an Outbox backs off while Kayros keeps failing,
+// unless WithOutboxBackoff is used
+var DefaultOutboxBackoff = RetryPolicy{
+	InitialBackoff: time.Second,
+	MaxBackoff:     5 * time.Minute,
+	Multiplier:     2,
+	Jitter:         0.2,
+}
+
+// OutboxEntry is a hash waiting in an Outbox
+type OutboxEntry struct {
+	DataHash   string    `json:"dataHash"`
+	DataType   string    `json:"dataType"`
+	EnqueuedAt time.Time `json:"enqueuedAt"`
+
+	// Attempts and LastError describe the failed attempts since the outbox
+	// was opened; they are not persisted
+	Attempts  int    `json:"-"`
+	LastError string `json:"-"`
+}
+
+// OutboxEvent reports that an Outbox is done with a hash
+type OutboxEvent struct {
+	Entry OutboxEntry
+
+	// Response is set when the hash was proved
+	Response *ProveSingleHashResponse
+
+	// Err is set when the outbox gave up on the hash, or when it was proved
+	// but its receipt could not be stored
+	Err error
+}
+
+// OutboxOption configures an Outbox
+type OutboxOption func(*Outbox)
+
+// WithOutboxCallback sets a function called once for every hash the outbox
+// is done with
+// It is called from the outbox's goroutines, possibly concurrently.
+func WithOutboxCallback(fn func(OutboxEvent)) OutboxOption {
+	return func(o *Outbox) {
+		o.callback = fn
+	}
+}
+
+// WithOutboxReceipts stores a receipt for every hash the outbox proves
+func WithOutboxReceipts(store ReceiptStore) OutboxOption {
+	return func(o *Outbox) {
+		o.receipts = store
+	}
+}
+
+// WithOutboxConcurrency sets how many hashes are proved at once (defaults to
+// DefaultOutboxConcurrency)
+func WithOutboxConcurrency(n int) OutboxOption {
+	return func(o *Outbox) {
+		o.concurrency = max(n, 1)
+	}
+}
+
+// WithOutboxBackoff sets how long the outbox waits after a failed attempt
+// Only InitialBackoff, MaxBackoff, Multiplier and Jitter are used.
+func WithOutboxBackoff(policy RetryPolicy) OutboxOption {
+	return func(o *Outbox) {
+		o.backoff = policy
+	}
+}
+
+// WithOutboxMaxAttempts makes the outbox give up on a hash after n failed
+// attempts (by default it retries until the hash is proved)
+func WithOutboxMaxAttempts(n int) OutboxOption {
+	return func(o *Outbox) {
+		o.maxAttempts = n
+	}
+}
+
+// outboxJournalLine is one line of the outbox file
+type outboxJournalLine struct {
+	Op    string       `json:"op"` // "add" or "done"
+	Entry *OutboxEntry `json:"entry,omitempty"`
+	Key   string       `json:"key,omitempty"`
+}
+
+// outboxEntry is a pending hash and its attempt state
+type outboxEntry struct {
+	OutboxEntry
+	key      string
+	inFlight bool
+}
+
+// Outbox proves hashes in the background, keeping them in a file until they
+// are proved so that none are lost while Kayros is unreachable or the
+// process restarts
+//
+// Hashes are proved in the order they were enqueued. After an attempt fails
+// with a transport error, a 5xx, 408 or 429 status, the outbox pauses with
+// exponential backoff and then retries; other 4xx statuses are permanent
+// failures. A hash whose response is lost may be recorded by Kayros twice,
+// and its callback may run twice if the process stops right after it was
+// proved.
+type Outbox struct {
+	client      *Client
+	path        string
+	callback    func(OutboxEvent)
+	receipts    ReceiptStore
+	concurrency int
+	backoff     RetryPolicy
+	maxAttempts int
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wake   chan struct{}
+	done   chan struct{}
+	wg     sync.WaitGroup
+
+	mu         sync.Mutex
+	file       *os.File
+	pending    []*outboxEntry
+	keys       map[string]*outboxEntry
+	inFlight   int
+	failures   int
+	pauseUntil time.Time
+	idle       chan struct{}
+	closed     bool
+}
+
+// OpenOutbox opens the outbox file at path, creating it if needed, and starts
+// proving the hashes left in it with DefaultClient
+func OpenOutbox(path string, opts ...OutboxOption) (*Outbox, error) {
+	return DefaultClient.OpenOutbox(path, opts...)
+}
+
+// OpenOutbox opens the outbox file at path, creating it if needed, and starts
+// proving the hashes left in it
+// The file must not be opened by more than one Outbox at a time.
+func (c *Client) OpenOutbox(path string, opts ...OutboxOption) (*Outbox, error) {
+	o := &Outbox{
+		client:      c,
+		path:        path,
+		concurrency: DefaultOutboxConcurrency,
+		backoff:     DefaultOutboxBackoff,
+		wake:        make(chan struct{}, 1),
+		done:        make(chan struct{}),
+		keys:        make(map[string]*outboxEntry),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if err := o.load(); err != nil {
+		return nil, fmt.Errorf("open outbox %s: %w", path, err)
+	}
+	if err := o.compact(); err != nil {
+		return nil, fmt.Errorf("open outbox %s: %w", path, err)
+	}
+
+	o.ctx, o.cancel = context.WithCancel(context.Background())
+	go o.run()
+	return o, nil
+}
+
+// load replays the outbox file, ignoring a final line left incomplete by a
+// crash
+func (o *Outbox) load() error {
+	file, err := os.Open(o.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	for lineNo := 1; ; lineNo++ {
+		raw, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var line outboxJournalLine
+		if err := json.Unmarshal(raw, &line); err != nil {
+			return fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		switch {
+		case line.Op == "add" && line.Entry != nil:
+			o.add(*line.Entry)
+		case line.Op == "done":
+			o.remove(line.Key)
+		default:
+			return fmt.Errorf("line %d: unknown outbox operation %q", lineNo, line.Op)
+		}
+	}
+}
+
+// compact rewrites the outbox file with only the pending hashes and opens it
+// for appending
+func (o *Outbox) compact() error {
+	tmp, err := os.CreateTemp(filepath.Dir(o.path), filepath.Base(o.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	w := bufio.NewWriter(tmp)
+	for _, e := range o.pending {
+		if err := writeJournalLine(w, outboxJournalLine{Op: "add", Entry: &e.OutboxEntry}); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), o.path); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(o.path, os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	o.file = file
+	return nil
+}
+
+// Enqueue adds a hash to the outbox and returns once it is saved to disk
+// dataType is optional and defaults to the client's data type. A hash that
+// is already pending under the same data type is not added again, but one
+// that has been proved is: Enqueue doesn't consult the journal's history or
+// the receipt store, and Kayros records a hash each time it is proved. Check
+// ReceiptStore.ByDataHash first to prove a hash only once.
+func (o *Outbox) Enqueue(dataHash string, dataType ...string) error {
+	dt := o.client.dataType
+	if len(dataType) > 0 && dataType[0] != "" {
+		dt = dataType[0]
+		if err := ValidateDataType(dt); err != nil {
+			return err
+		}
+	}
+	if _, err := decodeHash32("data_item", dataHash); err != nil {
+		return err
+	}
+	entry := OutboxEntry{DataHash: strings.ToLower(dataHash), DataType: strings.ToLower(dt), EnqueuedAt: time.Now().UTC()}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.closed {
+		return ErrOutboxClosed
+	}
+	if _, ok := o.keys[outboxKey(entry)]; ok {
+		return nil
+	}
+	if err := o.journal(outboxJournalLine{Op: "add", Entry: &entry}); err != nil {
+		return fmt.Errorf("enqueue: %w", err)
+	}
+	o.add(entry)
+	o.signal()
+	return nil
+}
+
+// Pending returns the hashes not yet proved, in the order they are proved
+func (o *Outbox) Pending() []OutboxEntry {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	entries := make([]OutboxEntry, len(o.pending))
+	for i, e := range o.pending {
+		entries[i] = e.OutboxEntry
+	}
+	return entries
+}
+
+// Len returns the number of hashes not yet proved
+func (o *Outbox) Len() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return len(o.pending)
+}
+
+// Flush waits until every pending hash has been proved or given up on, or
+// until ctx is done
+func (o *Outbox) Flush(ctx context.Context) error {
+	o.mu.Lock()
+	if len(o.pending) == 0 {
+		o.mu.Unlock()
+		return nil
+	}
+	if o.closed {
+		o.mu.Unlock()
+		return ErrOutboxClosed
+	}
+	if o.idle == nil {
+		o.idle = make(chan struct{})
+	}
+	idle := o.idle
+	o.mu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-o.done:
+		return ErrOutboxClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close stops proving and closes the file
+// Hashes still pending, including those whose proof Close interrupted, stay
+// in the file and are picked up by the next OpenOutbox. A proof that
+// succeeds as Close is called is recorded, and reported, as usual.
+func (o *Outbox) Close() error {
+	o.mu.Lock()
+	if o.closed {
+		o.mu.Unlock()
+		return nil
+	}
+	o.closed = true
+	o.mu.Unlock()
+
+	o.cancel()
+	<-o.done
+	o.wg.Wait()
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.file.Close()
+}
+
+// run starts attempts whenever the outbox isn't paused, until Close
+func (o *Outbox) run() {
+	defer close(o.done)
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		o.mu.Lock()
+		wait := time.Until(o.pauseUntil)
+		if wait <= 0 {
+			for _, e := range o.pending {
+				if o.inFlight >= o.concurrency {
+					break
+				}
+				if e.inFlight {
+					continue
+				}
+				e.inFlight = true
+				o.inFlight++
+				o.wg.Add(1)
+				go o.attempt(e)
+			}
+			wait = time.Hour
+		}
+		o.mu.Unlock()
+
+		timer.Reset(wait)
+		select {
+		case <-o.wake:
+		case <-timer.C:
+		case <-o.ctx.Done():
+			return
+		}
+	}
+}
+
+// attempt proves one pending hash
+func (o *Outbox) attempt(e *outboxEntry) {
+	defer o.wg.Done()
+	defer o.signal()
+
+	resp, err := o.client.ProveSingleHashContext(o.ctx, e.DataHash, e.DataType)
+	if err != nil && o.ctx.Err() != nil {
+		// Interrupted by Close: the hash stays pending for the next Open. A
+		// proof that landed as Close was called is journaled below instead,
+		// or it would be proved again.
+		o.mu.Lock()
+		e.inFlight = false
+		o.inFlight--
+		o.mu.Unlock()
+		return
+	}
+
+	event := OutboxEvent{Response: resp}
+	if err == nil && o.receipts != nil {
+		receipt := Receipt{DataHash: e.DataHash, DataType: e.DataType, Proof: resp}
+		if putErr := o.receipts.Put(context.WithoutCancel(o.ctx), receipt); putErr != nil {
+			event.Err = fmt.Errorf("store receipt: %w", putErr)
+		}
+	}
+
+	o.mu.Lock()
+	e.inFlight = false
+	o.inFlight--
+	if err != nil {
+		e.Attempts++
+		e.LastError = err.Error()
+		if !permanentProveError(err) && (o.maxAttempts <= 0 || e.Attempts < o.maxAttempts) {
+			o.failures++
+			o.pauseUntil = time.Now().Add(o.backoff.backoff(o.failures))
+			o.mu.Unlock()
+			return
+		}
+		event.Err = err
+	} else {
+		o.failures = 0
+		o.pauseUntil = time.Time{}
+	}
+
+	if jErr := o.journal(outboxJournalLine{Op: "done", Key: e.key}); jErr != nil && event.Err == nil {
+		event.Err = fmt.Errorf("record completion: %w", jErr)
+	}
+	o.remove(e.key)
+	event.Entry = e.OutboxEntry
+	o.mu.Unlock()
+
+	if o.callback != nil {
+		o.callback(event)
+	}
+}
+
+// add appends entry to the pending hashes
+func (o *Outbox) add(entry OutboxEntry) {
+	key := outboxKey(entry)
+	if _, ok := o.keys[key]; ok {
+		return
+	}
+	e := &outboxEntry{OutboxEntry: entry, key: key}
+	o.pending = append(o.pending, e)
+	o.keys[key] = e
+}
+
+// remove drops the pending hash with key, truncating the file once nothing
+// is pending
+func (o *Outbox) remove(key string) {
+	e, ok := o.keys[key]
+	if !ok {
+		return
+	}
+	delete(o.keys, key)
+	for i, p := range o.pending {
+		if p == e {
+			o.pending = append(o.pending[:i], o.pending[i+1:]...)
+			break
+		}
+	}
+
+	if len(o.pending) > 0 {
+		return
+	}
+	if o.file != nil {
+		o.file.Truncate(0)
+	}
+	if o.idle != nil {
+		close(o.idle)
+		o.idle = nil
+	}
+}
+
+// journal appends line to the outbox file and syncs it
+func (o *Outbox) journal(line outboxJournalLine) error {
+	if err := writeJournalLine(o.file, line); err != nil {
+		return err
+	}
+	return o.file.Sync()
+}
+
+// signal wakes run without blocking
+func (o *Outbox) signal() {
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+}
+
+func writeJournalLine(w io.Writer, line outboxJournalLine) error {
+	raw, err := json.Marshal(line)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(raw, '\n'))
+	return err
+}
+
+// outboxKey identifies a pending hash
+func outboxKey(entry OutboxEntry) string {
+	return entry.DataType + "/" + entry.DataHash
+}
+
+// permanentProveError reports whether retrying a failed proof is pointless
+// A response with success=false (ErrRequestFailed) means Kayros rejected the
+// hash, so it is permanent like a 4xx status.
+func permanentProveError(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return errors.Is(err, ErrInvalidDataType) || errors.Is(err, ErrInvalidHash)
+	}
+	switch apiErr.StatusCode {
+	case 0:
+		return true
+	case http.StatusRequestTimeout, http.StatusTooManyRequests:
+		return false
+	}
+	return apiErr.StatusCode >= 400 && apiErr.StatusCode < 500
+}
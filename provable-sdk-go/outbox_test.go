@@ -0,0 +1,282 @@
+package provable
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// outboxTestKayros is a fake Kayros whose prove route answers with status
+// (200 when zero), or rejects hashes in the body when reject is set,
+// counting the requests it received
+type outboxTestKayros struct {
+	status   atomic.Int32
+	reject   atomic.Bool
+	requests atomic.Int32
+	client   *Client
+}
+
+func newOutboxTestKayros(t *testing.T) *outboxTestKayros {
+	t.Helper()
+	k := &outboxTestKayros{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		k.requests.Add(1)
+		if status := int(k.status.Load()); status != 0 {
+			w.WriteHeader(status)
+			return
+		}
+		if k.reject.Load() {
+			w.Write([]byte(`{"success":false,"error":"hash rejected"}`))
+			return
+		}
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]string{"computed_hash_hex": Keccak256Str("computed:" + body["data_item"])}})
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := NewClient(WithBaseURL(server.URL), WithRetryPolicy(NoRetry))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	k.client = c
+	return k
+}
+
+// roundTripFunc is an http.RoundTripper calling itself
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+// outboxTestBackoff retries almost at once
+var outboxTestBackoff = RetryPolicy{InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond, Multiplier: 2}
+
+func openTestOutbox(t *testing.T, c *Client, path string, opts ...OutboxOption) *Outbox {
+	t.Helper()
+	o, err := c.OpenOutbox(path, append([]OutboxOption{WithOutboxBackoff(outboxTestBackoff)}, opts...)...)
+	if err != nil {
+		t.Fatalf("OpenOutbox() error = %v", err)
+	}
+	t.Cleanup(func() { o.Close() })
+	return o
+}
+
+// collectEvents returns an option recording every event and a function
+// returning them
+func collectEvents() (OutboxOption, func() []OutboxEvent) {
+	var mu sync.Mutex
+	var events []OutboxEvent
+	record := WithOutboxCallback(func(e OutboxEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, e)
+	})
+	return record, func() []OutboxEvent {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]OutboxEvent(nil), events...)
+	}
+}
+
+func flushOutbox(t *testing.T, o *Outbox) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := o.Flush(ctx); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+}
+
+func TestOutbox(t *testing.T) {
+	ctx := context.Background()
+	hashes := []string{Keccak256Str("a"), Keccak256Str("b"), Keccak256Str("c")}
+
+	t.Run("prove hashes and report them", func(t *testing.T) {
+		k := newOutboxTestKayros(t)
+		dir := t.TempDir()
+		receipts := openTestReceiptStore(t, filepath.Join(dir, "receipts.jsonl"))
+		onEvent, events := collectEvents()
+		o := openTestOutbox(t, k.client, filepath.Join(dir, "outbox.jsonl"), onEvent, WithOutboxReceipts(receipts))
+
+		for _, h := range hashes {
+			if err := o.Enqueue(h); err != nil {
+				t.Fatalf("Enqueue() error = %v", err)
+			}
+		}
+		flushOutbox(t, o)
+
+		got := events()
+		if len(got) != 3 || got[0].Err != nil || got[0].Response.Data.ComputedHashHex == "" {
+			t.Fatalf("events = %+v, want 3 proofs", got)
+		}
+		if r, err := receipts.ByDataHash(ctx, hashes[1]); err != nil || r[0].ComputedHash != Keccak256Str("computed:"+hashes[1]) {
+			t.Errorf("ByDataHash() = %+v, %v, want the stored receipt", r, err)
+		}
+		if info, _ := os.Stat(filepath.Join(dir, "outbox.jsonl")); info.Size() != 0 {
+			t.Errorf("outbox file size = %d, want 0 once everything is proved", info.Size())
+		}
+	})
+
+	t.Run("retry while Kayros is down", func(t *testing.T) {
+		k := newOutboxTestKayros(t)
+		k.status.Store(http.StatusServiceUnavailable)
+		onEvent, events := collectEvents()
+		o := openTestOutbox(t, k.client, filepath.Join(t.TempDir(), "outbox.jsonl"), onEvent, WithOutboxConcurrency(1))
+
+		o.Enqueue(hashes[0])
+		o.Enqueue(hashes[0])
+		for k.requests.Load() < 3 {
+			time.Sleep(time.Millisecond)
+		}
+		if pending := o.Pending(); len(pending) != 1 || pending[0].Attempts < 2 || pending[0].LastError == "" {
+			t.Errorf("Pending() = %+v, want 1 hash with failed attempts", pending)
+		}
+
+		k.status.Store(0)
+		flushOutbox(t, o)
+		if got := events(); len(got) != 1 || got[0].Err != nil {
+			t.Errorf("events = %+v, want 1 proof", got)
+		}
+	})
+
+	t.Run("give up on permanent failures", func(t *testing.T) {
+		k := newOutboxTestKayros(t)
+		k.status.Store(http.StatusBadRequest)
+		onEvent, events := collectEvents()
+		o := openTestOutbox(t, k.client, filepath.Join(t.TempDir(), "outbox.jsonl"), onEvent)
+
+		o.Enqueue(hashes[0])
+		flushOutbox(t, o)
+		var apiErr *APIError
+		if got := events(); len(got) != 1 || !errors.As(got[0].Err, &apiErr) || got[0].Response != nil {
+			t.Errorf("events = %+v, want 1 failure with an *APIError", got)
+		}
+	})
+
+	t.Run("give up on hashes Kayros rejects", func(t *testing.T) {
+		k := newOutboxTestKayros(t)
+		k.reject.Store(true)
+		onEvent, events := collectEvents()
+		o := openTestOutbox(t, k.client, filepath.Join(t.TempDir(), "outbox.jsonl"), onEvent)
+
+		o.Enqueue(hashes[0])
+		flushOutbox(t, o)
+		if got := events(); len(got) != 1 || !errors.Is(got[0].Err, ErrRequestFailed) || got[0].Entry.Attempts != 1 {
+			t.Errorf("events = %+v, want 1 failure with ErrRequestFailed after 1 attempt", got)
+		}
+
+		// The rejection doesn't hold back the hashes after it
+		k.reject.Store(false)
+		o.Enqueue(hashes[1])
+		flushOutbox(t, o)
+		if got := events(); len(got) != 2 || got[1].Err != nil || k.requests.Load() != 2 {
+			t.Errorf("events = %+v after %d requests, want a proof after 2", got, k.requests.Load())
+		}
+	})
+
+	t.Run("give up after max attempts", func(t *testing.T) {
+		k := newOutboxTestKayros(t)
+		k.status.Store(http.StatusBadGateway)
+		onEvent, events := collectEvents()
+		o := openTestOutbox(t, k.client, filepath.Join(t.TempDir(), "outbox.jsonl"), onEvent, WithOutboxMaxAttempts(3))
+
+		o.Enqueue(hashes[0])
+		flushOutbox(t, o)
+		if got := events(); len(got) != 1 || got[0].Err == nil || got[0].Entry.Attempts != 3 {
+			t.Errorf("events = %+v, want 1 failure after 3 attempts", got)
+		}
+	})
+
+	t.Run("survive restarts", func(t *testing.T) {
+		k := newOutboxTestKayros(t)
+		k.status.Store(http.StatusServiceUnavailable)
+		path := filepath.Join(t.TempDir(), "outbox.jsonl")
+		o := openTestOutbox(t, k.client, path, WithOutboxBackoff(RetryPolicy{InitialBackoff: time.Hour}))
+		for _, h := range hashes {
+			o.Enqueue(h)
+		}
+		if err := o.Close(); err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+		if err := o.Enqueue(hashes[0]); !errors.Is(err, ErrOutboxClosed) {
+			t.Errorf("Enqueue() error = %v, want ErrOutboxClosed", err)
+		}
+
+		// Simulate a crash in the middle of a write
+		f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+		f.WriteString(`{"op":"add","entry":{"dataHash":"`)
+		f.Close()
+
+		k.status.Store(0)
+		onEvent, events := collectEvents()
+		o = openTestOutbox(t, k.client, path, onEvent)
+		flushOutbox(t, o)
+		got := events()
+		if len(got) != 3 {
+			t.Fatalf("events = %+v, want the 3 hashes from before the restart", got)
+		}
+		for _, e := range got {
+			if e.Err != nil || e.Entry.EnqueuedAt.IsZero() {
+				t.Errorf("event = %+v, want a proof of the original entry", e)
+			}
+		}
+	})
+
+	t.Run("journal a proof that lands while closing", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "outbox.jsonl")
+		var o *Outbox
+		closed := make(chan error, 1)
+		// Kayros proves the hash, but the response arrives after Close
+		// cancelled the request
+		transport := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			go func() { closed <- o.Close() }()
+			<-r.Context().Done()
+			body := `{"data":{"computed_hash_hex":"` + Keccak256Str("computed") + `"}}`
+			return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(body)), Request: r}, nil
+		})
+		c, err := NewClient(WithBaseURL("http://kayros.test"), WithHTTPClient(&http.Client{Transport: transport}), WithRetryPolicy(NoRetry))
+		if err != nil {
+			t.Fatalf("NewClient() error = %v", err)
+		}
+		onEvent, events := collectEvents()
+		o = openTestOutbox(t, c, path, onEvent)
+
+		o.Enqueue(hashes[0])
+		if err := <-closed; err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+		if got := events(); len(got) != 1 || got[0].Err != nil || got[0].Response == nil {
+			t.Errorf("events = %+v, want the proof reported", got)
+		}
+
+		k := newOutboxTestKayros(t)
+		o = openTestOutbox(t, k.client, path)
+		flushOutbox(t, o)
+		if pending, n := o.Pending(), k.requests.Load(); len(pending) != 0 || n != 0 {
+			t.Errorf("Pending() = %+v after %d requests, want the hash not proved again", pending, n)
+		}
+	})
+
+	t.Run("reject invalid hashes", func(t *testing.T) {
+		k := newOutboxTestKayros(t)
+		o := openTestOutbox(t, k.client, filepath.Join(t.TempDir(), "outbox.jsonl"))
+		if err := o.Enqueue("abc"); !errors.Is(err, ErrInvalidHash) {
+			t.Errorf("Enqueue() error = %v, want ErrInvalidHash", err)
+		}
+		if err := o.Enqueue(hashes[0], "nope"); !errors.Is(err, ErrInvalidDataType) {
+			t.Errorf("Enqueue() error = %v, want ErrInvalidDataType", err)
+		}
+	})
+}
@@ -0,0 +1,159 @@
+package provable
+
+import (
+	"context"
+	"fmt"
+	"iter"
+)
+
+// DefaultPageSize is the page size iterators use when the request's Limit is 0
+const DefaultPageSize = 100
+
+// Orderings accepted by DatabaseQuery.OrderBy
+const (
+	OrderTimestampAsc  = "ts_asc"
+	OrderTimestampDesc = "ts_desc"
+)
+
+type pageOptions struct {
+	timestampCursor bool
+}
+
+// PageOption configures AllHashes
+type PageOption func(*pageOptions)
+
+// WithTimestampCursor pages by timestamp instead of offset
+//
+// Each page starts at the timestamp of the last record seen (MinTimestamp for
+// ts_asc, MaxTimestamp for ts_desc), skipping the records at that timestamp
+// already returned. Records inserted while iterating then can't shift later
+// pages the way they shift offsets. The timestamp bounds are assumed to be
+// inclusive, and OrderBy defaults to ts_asc.
+func WithTimestampCursor() PageOption {
+	return func(o *pageOptions) {
+		o.timestampCursor = true
+	}
+}
+
+// AllHashes iterates over every record matching query, fetching pages of
+// query.Limit records starting at query.Offset
+// Iteration stops at the first error, which is yielded with a zero record,
+// or when ctx is done.
+func (c *Client) AllHashes(ctx context.Context, query DatabaseQuery, opts ...PageOption) iter.Seq2[HashRecord, error] {
+	var o pageOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if query.Limit <= 0 {
+		query.Limit = DefaultPageSize
+	}
+
+	return func(yield func(HashRecord, error) bool) {
+		query := query
+		if o.timestampCursor {
+			if query.OrderBy == "" {
+				query.OrderBy = OrderTimestampAsc
+			}
+			if query.OrderBy != OrderTimestampAsc && query.OrderBy != OrderTimestampDesc {
+				yield(HashRecord{}, fmt.Errorf("timestamp cursor requires order_by %s or %s, got %q", OrderTimestampAsc, OrderTimestampDesc, query.OrderBy))
+				return
+			}
+		}
+
+		for {
+			if err := ctx.Err(); err != nil {
+				yield(HashRecord{}, err)
+				return
+			}
+
+			page, err := c.QueryHashesContext(ctx, query)
+			if err != nil {
+				yield(HashRecord{}, err)
+				return
+			}
+			for _, record := range page {
+				if !yield(record, nil) {
+					return
+				}
+			}
+			if len(page) < query.Limit {
+				return
+			}
+
+			if o.timestampCursor {
+				advanceTimestampCursor(&query, page)
+			} else {
+				query.Offset += len(page)
+			}
+		}
+	}
+}
+
+// advanceTimestampCursor moves query past page: the bound becomes the last
+// timestamp in page and Offset skips the records at that timestamp already
+// returned
+func advanceTimestampCursor(query *DatabaseQuery, page []HashRecord) {
+	bound := &query.MinTimestamp
+	if query.OrderBy == OrderTimestampDesc {
+		bound = &query.MaxTimestamp
+	}
+
+	last := page[len(page)-1].Timestamp
+	atLast := 0
+	for i := len(page) - 1; i >= 0 && page[i].Timestamp == last; i-- {
+		atLast++
+	}
+
+	if *bound != nil && **bound == last {
+		// The whole page shares the cursor timestamp, so keep skipping
+		query.Offset += atLast
+		return
+	}
+	*bound = &last
+	query.Offset = atLast
+}
+
+// BrowseAll iterates over every row of a table, fetching pages of req.Limit
+// rows starting at req.Offset
+// Iteration stops at the first error, which is yielded with a nil row, or
+// when ctx is done.
+func (c *Client) BrowseAll(ctx context.Context, req TableBrowseRequest) iter.Seq2[map[string]interface{}, error] {
+	if req.Limit <= 0 {
+		req.Limit = DefaultPageSize
+	}
+
+	return func(yield func(map[string]interface{}, error) bool) {
+		req := req
+		for {
+			if err := ctx.Err(); err != nil {
+				yield(nil, err)
+				return
+			}
+
+			page, err := c.BrowseTableContext(ctx, req)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			for _, row := range page {
+				if !yield(row, nil) {
+					return
+				}
+			}
+			if len(page) < req.Limit {
+				return
+			}
+			req.Offset += len(page)
+		}
+	}
+}
+
+// AllHashes iterates over every record matching query using DefaultClient
+func AllHashes(ctx context.Context, query DatabaseQuery, opts ...PageOption) iter.Seq2[HashRecord, error] {
+	return DefaultClient.AllHashes(ctx, query, opts...)
+}
+
+// BrowseAll iterates over every row of a table using DefaultClient
+func BrowseAll(ctx context.Context, req TableBrowseRequest) iter.Seq2[map[string]interface{}, error] {
+	return DefaultClient.BrowseAll(ctx, req)
+}
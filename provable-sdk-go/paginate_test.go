@@ -0,0 +1,276 @@
+package provable
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// fakeHashDB serves /api/database/query and /api/database/browse from an
+// in-memory list, with inclusive timestamp bounds
+type fakeHashDB struct {
+	mu      sync.Mutex
+	records []HashRecord
+	queries []DatabaseQuery
+	// onQuery runs after each query is answered, e.g. to insert records
+	onQuery func(db *fakeHashDB)
+}
+
+func (db *fakeHashDB) add(timestamp string) {
+	db.records = append(db.records, HashRecord{Timestamp: timestamp, HashItem: fmt.Sprintf("h%03d", len(db.records))})
+}
+
+func (db *fakeHashDB) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var query DatabaseQuery
+	json.NewDecoder(r.Body).Decode(&query)
+	if r.URL.Path == "/api/database/browse" {
+		// Browse requests share limit and offset; rows come back in insertion order
+		query = DatabaseQuery{Limit: query.Limit, Offset: query.Offset, OrderBy: OrderTimestampAsc}
+	}
+	db.queries = append(db.queries, query)
+
+	var matched []HashRecord
+	for _, rec := range db.records {
+		if query.MinTimestamp != nil && rec.Timestamp < *query.MinTimestamp {
+			continue
+		}
+		if query.MaxTimestamp != nil && rec.Timestamp > *query.MaxTimestamp {
+			continue
+		}
+		matched = append(matched, rec)
+	}
+	slices.SortStableFunc(matched, func(a, b HashRecord) int {
+		if query.OrderBy == OrderTimestampDesc {
+			a, b = b, a
+		}
+		if a.Timestamp < b.Timestamp {
+			return -1
+		}
+		if a.Timestamp > b.Timestamp {
+			return 1
+		}
+		return 0
+	})
+
+	page := []HashRecord{}
+	if query.Offset < len(matched) {
+		page = matched[query.Offset:min(query.Offset+query.Limit, len(matched))]
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "data": page})
+
+	if db.onQuery != nil {
+		db.onQuery(db)
+	}
+}
+
+func newPaginateTestClient(t *testing.T, db *fakeHashDB) *Client {
+	t.Helper()
+	server := httptest.NewServer(db)
+	t.Cleanup(server.Close)
+	c, err := NewClient(WithBaseURL(server.URL), WithRetryPolicy(NoRetry))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	return c
+}
+
+func collectHashes(t *testing.T, seq func(func(HashRecord, error) bool)) []string {
+	t.Helper()
+	var items []string
+	for rec, err := range seq {
+		if err != nil {
+			t.Fatalf("iteration error = %v", err)
+		}
+		items = append(items, rec.HashItem)
+	}
+	return items
+}
+
+func timestamps(seconds ...int) []string {
+	ts := make([]string, len(seconds))
+	for i, s := range seconds {
+		ts[i] = fmt.Sprintf("2025-01-01T00:00:%02dZ", s)
+	}
+	return ts
+}
+
+func newFakeHashDB(seconds ...int) *fakeHashDB {
+	db := &fakeHashDB{}
+	for _, ts := range timestamps(seconds...) {
+		db.add(ts)
+	}
+	return db
+}
+
+func TestAllHashes(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("page by offset", func(t *testing.T) {
+		db := newFakeHashDB(1, 2, 3, 4, 5, 6, 7)
+		c := newPaginateTestClient(t, db)
+
+		got := collectHashes(t, c.AllHashes(ctx, DatabaseQuery{Limit: 3, OrderBy: OrderTimestampAsc}))
+		want := []string{"h000", "h001", "h002", "h003", "h004", "h005", "h006"}
+		if !slices.Equal(got, want) {
+			t.Errorf("AllHashes() = %v, want %v", got, want)
+		}
+		if len(db.queries) != 3 || db.queries[2].Offset != 6 {
+			t.Errorf("queries = %+v, want offsets 0, 3, 6", db.queries)
+		}
+	})
+
+	t.Run("respect descending order", func(t *testing.T) {
+		c := newPaginateTestClient(t, newFakeHashDB(1, 2, 3, 4))
+		got := collectHashes(t, c.AllHashes(ctx, DatabaseQuery{Limit: 2, OrderBy: OrderTimestampDesc}))
+		want := []string{"h003", "h002", "h001", "h000"}
+		if !slices.Equal(got, want) {
+			t.Errorf("AllHashes() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("stop fetching when the caller breaks", func(t *testing.T) {
+		db := newFakeHashDB(1, 2, 3, 4, 5, 6)
+		c := newPaginateTestClient(t, db)
+		for range c.AllHashes(ctx, DatabaseQuery{Limit: 2}) {
+			break
+		}
+		if len(db.queries) != 1 {
+			t.Errorf("queries = %d, want 1", len(db.queries))
+		}
+	})
+
+	t.Run("stop on context cancellation", func(t *testing.T) {
+		db := newFakeHashDB(1, 2, 3, 4, 5, 6)
+		c := newPaginateTestClient(t, db)
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		var gotErr error
+		n := 0
+		for _, err := range c.AllHashes(ctx, DatabaseQuery{Limit: 2}) {
+			if err != nil {
+				gotErr = err
+				break
+			}
+			n++
+			cancel()
+		}
+		if !errors.Is(gotErr, context.Canceled) {
+			t.Errorf("iteration error = %v, want context.Canceled", gotErr)
+		}
+		if n != 2 {
+			t.Errorf("records before cancellation = %d, want 2", n)
+		}
+	})
+
+	t.Run("yield request errors", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "boom", http.StatusInternalServerError)
+		}))
+		defer server.Close()
+		c, _ := NewClient(WithBaseURL(server.URL), WithRetryPolicy(NoRetry))
+
+		for _, err := range c.AllHashes(ctx, DatabaseQuery{}) {
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Errorf("iteration error = %v, want *APIError", err)
+			}
+		}
+	})
+
+	t.Run("drift with offsets when records are inserted", func(t *testing.T) {
+		db := newFakeHashDB(1, 2, 3, 4)
+		db.onQuery = func(db *fakeHashDB) {
+			if len(db.queries) == 1 {
+				db.add(timestamps(5)[0])
+				db.add(timestamps(6)[0])
+			}
+		}
+		c := newPaginateTestClient(t, db)
+
+		got := collectHashes(t, c.AllHashes(ctx, DatabaseQuery{Limit: 2, OrderBy: OrderTimestampDesc}))
+		// The new records push h001/h000 down, so h003/h002 come back again
+		if slices.Equal(got, []string{"h003", "h002", "h001", "h000"}) {
+			t.Errorf("AllHashes() = %v, expected offset drift", got)
+		}
+	})
+
+	t.Run("avoid drift with timestamp cursor", func(t *testing.T) {
+		db := newFakeHashDB(1, 2, 3, 4)
+		db.onQuery = func(db *fakeHashDB) {
+			if len(db.queries) == 1 {
+				db.add(timestamps(5)[0])
+				db.add(timestamps(6)[0])
+			}
+		}
+		c := newPaginateTestClient(t, db)
+
+		got := collectHashes(t, c.AllHashes(ctx, DatabaseQuery{Limit: 2, OrderBy: OrderTimestampDesc}, WithTimestampCursor()))
+		want := []string{"h003", "h002", "h001", "h000"}
+		if !slices.Equal(got, want) {
+			t.Errorf("AllHashes() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("skip records sharing the cursor timestamp", func(t *testing.T) {
+		// Runs of equal timestamps longer than a page, and straddling pages
+		db := newFakeHashDB(1, 2, 2, 2, 2, 2, 3, 3, 4)
+		c := newPaginateTestClient(t, db)
+
+		got := collectHashes(t, c.AllHashes(ctx, DatabaseQuery{Limit: 2}, WithTimestampCursor()))
+		want := []string{"h000", "h001", "h002", "h003", "h004", "h005", "h006", "h007", "h008"}
+		if !slices.Equal(got, want) {
+			t.Errorf("AllHashes() = %v, want %v", got, want)
+		}
+		if db.queries[0].OrderBy != OrderTimestampAsc {
+			t.Errorf("OrderBy = %q, want %q by default", db.queries[0].OrderBy, OrderTimestampAsc)
+		}
+	})
+
+	t.Run("reject cursor with unknown order", func(t *testing.T) {
+		c := newPaginateTestClient(t, newFakeHashDB(1))
+		for _, err := range c.AllHashes(ctx, DatabaseQuery{OrderBy: "hash"}, WithTimestampCursor()) {
+			if err == nil {
+				t.Error("iteration error = nil, want error for unknown order")
+			}
+		}
+	})
+
+	t.Run("restart from the beginning on each range", func(t *testing.T) {
+		c := newPaginateTestClient(t, newFakeHashDB(1, 2, 3))
+		seq := c.AllHashes(ctx, DatabaseQuery{Limit: 2})
+		first := collectHashes(t, seq)
+		second := collectHashes(t, seq)
+		if !slices.Equal(first, second) {
+			t.Errorf("second iteration = %v, want %v", second, first)
+		}
+	})
+}
+
+func TestBrowseAll(t *testing.T) {
+	db := newFakeHashDB(1, 2, 3, 4, 5)
+	c := newPaginateTestClient(t, db)
+
+	var got []interface{}
+	for row, err := range c.BrowseAll(context.Background(), TableBrowseRequest{TableName: "hashes", Limit: 2}) {
+		if err != nil {
+			t.Fatalf("BrowseAll() error = %v", err)
+		}
+		got = append(got, row["hash_item"])
+	}
+	if len(got) != 5 || got[4] != "h004" {
+		t.Errorf("BrowseAll() = %v, want 5 rows", got)
+	}
+	if len(db.queries) != 3 {
+		t.Errorf("queries = %d, want 3", len(db.queries))
+	}
+}
@@ -0,0 +1,188 @@
+package provable
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// PolicyRule identifies a VerifyPolicy rule
+type PolicyRule string
+
+const (
+	PolicyRuleDataType          PolicyRule = "data_type"
+	PolicyRuleHashAlgorithm     PolicyRule = "hash_algorithm"
+	PolicyRuleMaxAge            PolicyRule = "max_age"
+	PolicyRuleMerkleInclusion   PolicyRule = "merkle_inclusion"
+	PolicyRuleTrustedService    PolicyRule = "trusted_service"
+	PolicyRuleTimestampRequired PolicyRule = "timestamp_required"
+)
+
+// VerifyPolicy adds rules an envelope must satisfy on top of the hash and
+// remote record checks; zero fields are not enforced
+type VerifyPolicy struct {
+	// AllowedDataTypes are the Kayros data types (64 hex characters) the
+	// hash may have been proved under, according to the Kayros record (or
+	// the evidence record offline). A record without a data type breaks the
+	// rule; Kayros.DataType is never used, since the envelope declares it
+	// itself.
+	AllowedDataTypes []string
+
+	// RequiredHashAlgorithm is the hash algorithm the envelope must use
+	RequiredHashAlgorithm string
+
+	// MaxAge is how old the Kayros record timestamp may be
+	// The timestamp may be RFC 3339 or ISO 8601 with a "T" or space between
+	// date and time; one without a zone is taken as UTC.
+	MaxAge time.Duration
+
+	// RequireMerkleInclusion requires a Merkle proof that checks out against
+	// an anchored root: one of VerifyOptions.TrustedRoots or, online, the
+	// root Kayros serves for the record (see EnrichEnvelope)
+	RequireMerkleInclusion bool
+
+	// TrustedServiceHosts are the hosts KayrosTimestamp.Service may point
+	// to, e.g. "kayros.example.com" or "kayros.example.com:8443"
+	TrustedServiceHosts []string
+}
+
+// PolicyViolation describes a VerifyPolicy rule an envelope broke
+type PolicyViolation struct {
+	Rule    PolicyRule `json:"rule"`
+	Message string     `json:"message"`
+}
+
+// requiresTimestamp reports whether any rule needs a proved envelope
+func (p *VerifyPolicy) requiresTimestamp() bool {
+	return len(p.AllowedDataTypes) > 0 || p.MaxAge > 0 || p.RequireMerkleInclusion || len(p.TrustedServiceHosts) > 0
+}
+
+// applyVerifyPolicy records the policy rules a verified envelope breaks in
+// result.Details, invalidating result if there are any
+// record is the Kayros record it was verified against, or nil.
+func applyVerifyPolicy(policy *VerifyPolicy, envelope *KayrosEnvelope, record *GetRecordResponse, result *VerifyResult) {
+	if policy == nil {
+		return
+	}
+	var violations []PolicyViolation
+	violate := func(rule PolicyRule, format string, args ...interface{}) {
+		violations = append(violations, PolicyViolation{Rule: rule, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if policy.RequiredHashAlgorithm != "" {
+		algorithm := envelope.Kayros.HashAlgorithm
+		if algorithm == "" {
+			algorithm = HashAlgorithmKeccak256
+		}
+		if algorithm != policy.RequiredHashAlgorithm {
+			violate(PolicyRuleHashAlgorithm, "hash algorithm %q is not %q", algorithm, policy.RequiredHashAlgorithm)
+		}
+	}
+
+	timestamp := envelope.Kayros.Timestamp
+	if timestamp == nil {
+		if policy.requiresTimestamp() {
+			violate(PolicyRuleTimestampRequired, "envelope has no Kayros timestamp")
+		}
+	} else {
+		if len(policy.AllowedDataTypes) > 0 {
+			var dataType string
+			if record != nil {
+				dataType = record.Data.DataType
+			}
+			switch {
+			case dataType == "":
+				violate(PolicyRuleDataType, "Kayros record has no data type")
+			case !slices.ContainsFunc(policy.AllowedDataTypes, func(allowed string) bool { return strings.EqualFold(allowed, dataType) }):
+				violate(PolicyRuleDataType, "data type %s is not allowed", dataType)
+			}
+		}
+
+		if policy.MaxAge > 0 {
+			var recorded string
+			if record != nil {
+				recorded = record.Data.Timestamp
+			}
+			provedAt, err := parseRecordTimestamp(recorded)
+			switch {
+			case recorded == "":
+				violate(PolicyRuleMaxAge, "record timestamp is unknown")
+			case err != nil:
+				violate(PolicyRuleMaxAge, "record timestamp is malformed: %v", err)
+			case time.Since(provedAt) > policy.MaxAge:
+				violate(PolicyRuleMaxAge, "record timestamp %s is older than %s", recorded, policy.MaxAge)
+			}
+		}
+
+		if policy.RequireMerkleInclusion {
+			switch {
+			case result.Details == nil || !result.Details.MerkleMatch && result.Details.MerkleNote == "":
+				violate(PolicyRuleMerkleInclusion, "no anchored Merkle inclusion proof")
+			case !result.Details.MerkleMatch:
+				violate(PolicyRuleMerkleInclusion, "no anchored Merkle inclusion proof: %s", result.Details.MerkleNote)
+			}
+		}
+
+		if len(policy.TrustedServiceHosts) > 0 {
+			u, err := url.Parse(timestamp.Service)
+			switch {
+			case err != nil || u.Host == "":
+				violate(PolicyRuleTrustedService, "timestamp service %q is not a URL", timestamp.Service)
+			case !slices.ContainsFunc(policy.TrustedServiceHosts, func(host string) bool {
+				return strings.EqualFold(host, u.Host) || strings.EqualFold(host, u.Hostname())
+			}):
+				violate(PolicyRuleTrustedService, "timestamp service host %s is not trusted", u.Host)
+			}
+		}
+	}
+
+	if len(violations) == 0 {
+		return
+	}
+	if result.Details == nil {
+		result.Details = &VerifyResultDetails{}
+	}
+	result.Details.PolicyViolations = violations
+	result.Valid = false
+	result.Code = VerifyErrorPolicyViolation
+	messages := make([]string, len(violations))
+	for i, v := range violations {
+		messages[i] = v.Message
+	}
+	result.Error = "Policy violation: " + strings.Join(messages, "; ")
+}
+
+// recordTimestampLayouts are the layouts parseRecordTimestamp accepts
+// Fractional seconds are accepted after the seconds of any of them.
+var recordTimestampLayouts = []string{
+	"2006-01-02T15:04:05Z07:00",
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04:05Z07",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05Z0700",
+	"2006-01-02 15:04:05Z07",
+}
+
+// recordTimestampLocalLayouts are the layouts without a zone, taken as UTC
+var recordTimestampLocalLayouts = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+}
+
+// parseRecordTimestamp parses a Kayros record timestamp: RFC 3339, or ISO
+// 8601 with a "T" or space separator and an optional zone (UTC if missing)
+func parseRecordTimestamp(s string) (time.Time, error) {
+	for _, layout := range recordTimestampLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	for _, layout := range recordTimestampLocalLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.UTC); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q is not an RFC 3339 or ISO 8601 timestamp, e.g. 2006-01-02T15:04:05.000000Z", s)
+}
@@ -0,0 +1,167 @@
+package provable
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func violatedRules(result *VerifyResult) []PolicyRule {
+	if result.Details == nil {
+		return nil
+	}
+	var rules []PolicyRule
+	for _, v := range result.Details.PolicyViolations {
+		rules = append(rules, v.Rule)
+	}
+	return rules
+}
+
+func TestVerifyPolicy(t *testing.T) {
+	ctx := context.Background()
+	c, _ := newEvidenceTestClient(t)
+	dataType := strings.Repeat("ab", 32)
+
+	// policyTestEnvelope is an enriched envelope whose record was proved
+	// under dataType at provedAt
+	policyTestEnvelope := func(t *testing.T, provedAt time.Time) *KayrosEnvelope {
+		envelope := enrichedTestEnvelope(t, c, "policy", WithEnvelopeDataType(dataType))
+		envelope.Kayros.Evidence.ChainRecord.Timestamp = provedAt.Format(time.RFC3339Nano)
+		return envelope
+	}
+	verify := func(envelope *KayrosEnvelope, policy VerifyPolicy) *VerifyResult {
+		opts := VerifyOptions{Offline: true, Policy: &policy}
+		if envelope.Kayros.Evidence != nil {
+			opts = anchoredOffline(envelope)
+			opts.Policy = &policy
+		}
+		return c.VerifyWithOptions(ctx, envelope, opts)
+	}
+
+	t.Run("accept an envelope satisfying every rule", func(t *testing.T) {
+		result := verify(policyTestEnvelope(t, time.Now()), VerifyPolicy{
+			AllowedDataTypes:       []string{strings.ToUpper(dataType)},
+			RequiredHashAlgorithm:  HashAlgorithmKeccak256,
+			MaxAge:                 time.Hour,
+			RequireMerkleInclusion: true,
+			TrustedServiceHosts:    []string{strings.TrimPrefix(c.URL(""), "http://")},
+		})
+		if !result.Valid {
+			t.Errorf("VerifyWithOptions() = %+v, want valid", result)
+		}
+	})
+
+	t.Run("report every violated rule", func(t *testing.T) {
+		// Verify online, since offline verification requires a proof
+		envelope := policyTestEnvelope(t, time.Now())
+		envelope.Kayros.Evidence = nil
+		record := GetRecordResponse{Data: GetRecordResponseData{
+			DataItemHex: envelope.Kayros.Hash,
+			DataType:    dataType,
+			Timestamp:   time.Now().Add(-2 * time.Hour).Format(time.RFC3339Nano),
+		}}
+		result := c.VerifyWithOptions(ctx, envelope, VerifyOptions{
+			Policy: &VerifyPolicy{
+				AllowedDataTypes:       []string{strings.Repeat("cd", 32)},
+				RequiredHashAlgorithm:  HashAlgorithmSHA256,
+				MaxAge:                 time.Hour,
+				RequireMerkleInclusion: true,
+				TrustedServiceHosts:    []string{"kayros.example.com"},
+			},
+			fetchRecord: func(context.Context, string) (*GetRecordResponse, error) {
+				return &record, nil
+			},
+		})
+		want := []PolicyRule{PolicyRuleHashAlgorithm, PolicyRuleDataType, PolicyRuleMaxAge, PolicyRuleMerkleInclusion, PolicyRuleTrustedService}
+		if result.Valid || !slices.Equal(violatedRules(result), want) {
+			t.Errorf("violations = %v, want %v", violatedRules(result), want)
+		}
+		if !strings.HasPrefix(result.Error, "Policy violation: ") {
+			t.Errorf("Error = %q, want policy violation", result.Error)
+		}
+	})
+
+	t.Run("require Merkle inclusion against an anchored root", func(t *testing.T) {
+		// Online without trusted roots, a zero-level proof leading to its own
+		// target passes Kayros's recomputation but not the root it serves
+		envelope := enrichedTestEnvelope(t, c, "forged inclusion")
+		policy := &VerifyPolicy{RequireMerkleInclusion: true}
+		if result := c.VerifyWithOptions(ctx, envelope, VerifyOptions{Policy: policy}); !result.Valid {
+			t.Errorf("VerifyWithOptions() = %+v, want valid with the proof Kayros served", result)
+		}
+
+		rh := envelope.Kayros.Evidence.MerkleProof.TargetHashHex
+		envelope.Kayros.Evidence.MerkleProof = &MerkleProof{TargetHashHex: rh, RootHashHex: rh, Levels: 0}
+		result := c.VerifyWithOptions(ctx, envelope, VerifyOptions{Policy: policy})
+		if result.Valid || result.Code != VerifyErrorPolicyViolation || !slices.Equal(violatedRules(result), []PolicyRule{PolicyRuleMerkleInclusion}) {
+			t.Fatalf("VerifyWithOptions() = %+v, want a Merkle inclusion violation", result)
+		}
+		if msg := result.Details.PolicyViolations[0].Message; !strings.Contains(msg, "not anchored") {
+			t.Errorf("Message = %q, want the root reported as not anchored", msg)
+		}
+	})
+
+	t.Run("ignore the envelope data type", func(t *testing.T) {
+		envelope := enrichedTestEnvelope(t, c, "policy")
+		record := *envelope.Kayros.Evidence.Record
+		record.Data.DataType = ""
+		opts := VerifyOptions{
+			Policy: &VerifyPolicy{AllowedDataTypes: []string{DataType}},
+			fetchRecord: func(context.Context, string) (*GetRecordResponse, error) {
+				return &record, nil
+			},
+		}
+		result := c.VerifyWithOptions(ctx, envelope, opts)
+		if rules := violatedRules(result); !slices.Equal(rules, []PolicyRule{PolicyRuleDataType}) || !strings.Contains(result.Error, "no data type") {
+			t.Errorf("VerifyWithOptions() = %+v, want data_type violation with Kayros.DataType %s", result, envelope.Kayros.DataType)
+		}
+	})
+
+	t.Run("accept Kayros timestamp formats", func(t *testing.T) {
+		at := time.Now().UTC().Add(-time.Minute)
+		for _, layout := range []string{
+			time.RFC3339,
+			"2006-01-02T15:04:05.000000Z07:00",
+			"2006-01-02T15:04:05.000000",
+			"2006-01-02 15:04:05.000000-0700",
+			"2006-01-02 15:04:05.000-07",
+			"2006-01-02 15:04:05",
+		} {
+			envelope := policyTestEnvelope(t, time.Now())
+			envelope.Kayros.Evidence.ChainRecord.Timestamp = at.Format(layout)
+			if result := verify(envelope, VerifyPolicy{MaxAge: time.Hour}); !result.Valid {
+				t.Errorf("VerifyWithOptions() with timestamp %s = %+v, want valid", at.Format(layout), result)
+			}
+		}
+	})
+
+	t.Run("reject unknown and malformed timestamps", func(t *testing.T) {
+		envelope := policyTestEnvelope(t, time.Now())
+		envelope.Kayros.Evidence.ChainRecord.Timestamp = "yesterday"
+		result := verify(envelope, VerifyPolicy{MaxAge: time.Hour})
+		if rules := violatedRules(result); !slices.Equal(rules, []PolicyRule{PolicyRuleMaxAge}) || !strings.Contains(result.Error, `"yesterday" is not an RFC 3339 or ISO 8601 timestamp`) {
+			t.Errorf("VerifyWithOptions() = %+v, want max_age violation naming the format", result)
+		}
+	})
+
+	t.Run("require a timestamp for timestamp rules", func(t *testing.T) {
+		envelope, _ := NewEnvelope("unsealed")
+		if rules := violatedRules(verify(envelope, VerifyPolicy{MaxAge: time.Hour})); !slices.Equal(rules, []PolicyRule{PolicyRuleTimestampRequired}) {
+			t.Errorf("violations = %v, want timestamp_required", rules)
+		}
+		if result := verify(envelope, VerifyPolicy{RequiredHashAlgorithm: HashAlgorithmKeccak256}); !result.Valid {
+			t.Errorf("VerifyWithOptions() = %+v, want valid", result)
+		}
+	})
+
+	t.Run("leave failed verifications alone", func(t *testing.T) {
+		envelope := policyTestEnvelope(t, time.Now())
+		envelope.Data = "changed"
+		result := verify(envelope, VerifyPolicy{RequiredHashAlgorithm: HashAlgorithmSHA256})
+		if result.Valid || len(violatedRules(result)) != 0 || !strings.HasPrefix(result.Error, "Hash mismatch") {
+			t.Errorf("VerifyWithOptions() = %+v, want hash mismatch only", result)
+		}
+	})
+}
@@ -0,0 +1,211 @@
+package provabletest
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	provable "github.com/provable/provable-sdk-go"
+)
+
+// DefaultStartTime is the timestamp of the first record a Server appends
+var DefaultStartTime = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// DefaultTick is the time between consecutive records
+const DefaultTick = time.Millisecond
+
+// TimestampLayout is how record timestamps are formatted
+// It is fixed-width, so timestamps sort as strings, and parses as RFC 3339.
+const TimestampLayout = "2006-01-02T15:04:05.000000Z07:00"
+
+// timeUUIDUnixOffset is the number of 100ns intervals between the start of
+// the UUID version 1 clock, 1582-10-15, and the Unix epoch
+const timeUUIDUnixOffset = 0x01b21dd213814000
+
+// timeUUIDNode is the node field of every generated timeuuid, with the
+// multicast bit set as RFC 4122 requires for node IDs that are not MAC
+// addresses
+var timeUUIDNode = [6]byte{0x03, 0x70, 0x72, 0x6f, 0x76, 0x65}
+
+// TimeUUID returns the version 1 UUID for t and clock sequence seq as hex
+// The same arguments always give the same UUID.
+func TimeUUID(t time.Time, seq uint16) string {
+	ticks := uint64(t.UnixNano()/100 + timeUUIDUnixOffset)
+
+	var u [16]byte
+	binary.BigEndian.PutUint32(u[0:4], uint32(ticks))
+	binary.BigEndian.PutUint16(u[4:6], uint16(ticks>>32))
+	binary.BigEndian.PutUint16(u[6:8], uint16(ticks>>48)&0x0fff|0x1000)
+	binary.BigEndian.PutUint16(u[8:10], seq&0x3fff|0x8000)
+	copy(u[10:], timeUUIDNode[:])
+	return hex.EncodeToString(u[:])
+}
+
+// chain is the in-memory hash chain behind Server and LightnetServer
+type chain struct {
+	mu       sync.Mutex
+	start    time.Time
+	tick     time.Duration
+	clock    func() time.Time
+	hashType string
+	records  []provable.DatabaseRecord
+	byHash   map[string]int
+	byUUID   map[string]int
+	byItem   map[string]int
+
+	// tree is the Merkle tree over every record, built on demand
+	tree *merkleTree
+	// roots holds every Merkle root a proof was served against
+	roots map[string]bool
+}
+
+func newChain(opts []Option) *chain {
+	c := &chain{
+		start:    DefaultStartTime,
+		tick:     DefaultTick,
+		hashType: provable.RecordHashBLAKE3,
+		byHash:   make(map[string]int),
+		byUUID:   make(map[string]int),
+		byItem:   make(map[string]int),
+		roots:    make(map[string]bool),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Append adds a record to the chain as if dataItemHex had been proved under
+// dataType, and returns it
+// Both must be hex; the record hash is computed with the server's hash type.
+func (c *chain) Append(dataType, dataItemHex string) (provable.DatabaseRecord, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.append(dataType, dataItemHex)
+}
+
+func (c *chain) append(dataType, dataItemHex string) (provable.DatabaseRecord, error) {
+	record, _, err := c.next(dataType, dataItemHex)
+	if err != nil {
+		return provable.DatabaseRecord{}, err
+	}
+
+	n := len(c.records)
+	c.records = append(c.records, record)
+	c.byHash[record.HashItemHex] = n
+	c.byUUID[record.UUIDHex] = n
+	c.byItem[itemKey(record.DataType, record.DataItemHex)] = n
+	c.tree = nil
+	return record, nil
+}
+
+// next returns the record append would add, with its hash input as hex
+func (c *chain) next(dataType, dataItemHex string) (provable.DatabaseRecord, string, error) {
+	n := len(c.records)
+	at := c.now()
+	record := provable.DatabaseRecord{
+		DataType:    dataType,
+		DataItemHex: dataItemHex,
+		UUIDHex:     TimeUUID(at, uint16(n)),
+		HashType:    c.hashType,
+		Timestamp:   at.Format(TimestampLayout),
+	}
+	if n > 0 {
+		record.PrevHashHex = c.records[n-1].HashItemHex
+	}
+
+	hash, err := provable.VerifyHashLocal(provable.RecordHashRequest(record))
+	if err != nil {
+		return provable.DatabaseRecord{}, "", err
+	}
+	record.HashItemHex = hash.ComputedHash
+	return record, hash.HashInputHex, nil
+}
+
+// now returns the timestamp of the next record
+func (c *chain) now() time.Time {
+	if c.clock == nil {
+		return c.start.Add(time.Duration(len(c.records)) * c.tick)
+	}
+	at := c.clock().UTC()
+	if n := len(c.records); n > 0 {
+		if prev, err := time.Parse(TimestampLayout, c.records[n-1].Timestamp); err == nil && at.Before(prev) {
+			at = prev
+		}
+	}
+	return at
+}
+
+// Records returns a copy of the chain, oldest first
+func (c *chain) Records() []provable.DatabaseRecord {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]provable.DatabaseRecord(nil), c.records...)
+}
+
+// Record returns the record whose hash is hashItemHex
+func (c *chain) Record(hashItemHex string) (provable.DatabaseRecord, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	i, ok := c.byHash[hashItemHex]
+	if !ok {
+		return provable.DatabaseRecord{}, false
+	}
+	return c.records[i], true
+}
+
+// merkleTree builds the tree over every record of dataType, oldest first, and
+// returns it with the position of hashItemHex
+func (c *chain) merkleTree(dataType, hashItemHex string) (*merkleTree, int64, error) {
+	var leaves []string
+	position := int64(-1)
+	for _, record := range c.records {
+		if record.DataType != dataType {
+			continue
+		}
+		if record.HashItemHex == hashItemHex {
+			position = int64(len(leaves))
+		}
+		leaves = append(leaves, record.HashItemHex)
+	}
+	tree, err := newMerkleTree(leaves)
+	if err != nil {
+		return nil, 0, err
+	}
+	c.roots[tree.root()] = true
+	return tree, position, nil
+}
+
+// fullTree returns the Merkle tree over every record, oldest first
+func (c *chain) fullTree() (*merkleTree, error) {
+	if c.tree != nil {
+		return c.tree, nil
+	}
+	leaves := make([]string, len(c.records))
+	for i, record := range c.records {
+		leaves[i] = record.HashItemHex
+	}
+	tree, err := newMerkleTree(leaves)
+	if err != nil {
+		return nil, err
+	}
+	c.tree = tree
+	c.roots[tree.root()] = true
+	return tree, nil
+}
+
+// itemKey is the byItem key of a data item proved under dataType
+func itemKey(dataType, dataItemHex string) string {
+	return dataType + "/" + dataItemHex
+}
+
+func hashRecord(record provable.DatabaseRecord) provable.HashRecord {
+	return provable.HashRecord{
+		Timestamp: record.Timestamp,
+		DataType:  record.DataType,
+		DataItem:  record.DataItemHex,
+		HashType:  record.HashType,
+		HashItem:  record.HashItemHex,
+	}
+}
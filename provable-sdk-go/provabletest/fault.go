@@ -0,0 +1,97 @@
+package provabletest
+
+import (
+	"net/http"
+	"time"
+)
+
+// Fault makes a Server misbehave for matching requests
+type Fault struct {
+	// Path limits the fault to one route, e.g. provable.ProveSingleHashRoute;
+	// empty matches every route
+	Path string
+
+	// Times is how many requests the fault applies to before it is removed;
+	// zero means every request until ClearFaults
+	Times int
+
+	// Delay is waited before answering, or until the request is cancelled
+	Delay time.Duration
+
+	// Status, if set, is returned instead of the normal answer, with Body or
+	// else a Kayros error body
+	Status int
+
+	// Header is added to the faulty response, e.g. Retry-After with Status 429
+	Header http.Header
+
+	// Body, if set, replaces the response body, e.g. with malformed JSON
+	Body string
+}
+
+// Inject adds a fault
+// When several faults match a request, the first one injected applies.
+func (s *Server) Inject(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &f)
+}
+
+// ClearFaults removes every fault
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = nil
+}
+
+// takeFault returns the fault for a request to path, if any, using up one of
+// its Times
+func (s *Server) takeFault(path string) *Fault {
+	for i, f := range s.faults {
+		if f.Path != "" && f.Path != path {
+			continue
+		}
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.faults = append(s.faults[:i:i], s.faults[i+1:]...)
+			}
+		}
+		return f
+	}
+	return nil
+}
+
+// apply carries out the fault and reports whether it answered the request
+func (f *Fault) apply(w http.ResponseWriter, r *http.Request) bool {
+	if f.Delay > 0 {
+		timer := time.NewTimer(f.Delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-r.Context().Done():
+			return true
+		}
+	}
+	if f.Status == 0 && f.Body == "" {
+		return false
+	}
+
+	for key, values := range f.Header {
+		for _, v := range values {
+			w.Header().Add(key, v)
+		}
+	}
+	status := f.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	if f.Body == "" {
+		writeError(w, status, http.StatusText(status))
+		return true
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write([]byte(f.Body))
+	return true
+}
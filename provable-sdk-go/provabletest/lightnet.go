@@ -0,0 +1,246 @@
+package provabletest
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	provable "github.com/provable/provable-sdk-go"
+	lightnetpb "github.com/provable/provable-sdk-go/proto/lightnet"
+)
+
+// lightnetBufferSize is the buffer of the in-memory connection used by
+// LightnetServer.Conn
+const lightnetBufferSize = 1 << 20
+
+// LightnetServer implements the Lightnet gRPC HashService over an in-memory
+// hash chain
+// Register it on a grpc.Server to serve it yourself, or use Conn or Client to
+// reach it over an in-memory connection. Records are appended as by Server,
+// and GetMerkleProof and GetMerkleRoot use the Merkle tree over every record,
+// with positions counting from the first record.
+type LightnetServer struct {
+	lightnetpb.UnimplementedHashServiceServer
+	*chain
+
+	connMu sync.Mutex
+	server *grpc.Server
+	conn   *grpc.ClientConn
+}
+
+// NewLightnetServer creates a LightnetServer
+func NewLightnetServer(opts ...Option) *LightnetServer {
+	return &LightnetServer{chain: newChain(opts)}
+}
+
+// Conn returns a connection to the server over an in-memory listener, started
+// on first use
+// The caller must not close it; Close does.
+func (l *LightnetServer) Conn() (*grpc.ClientConn, error) {
+	l.connMu.Lock()
+	defer l.connMu.Unlock()
+	if l.conn != nil {
+		return l.conn, nil
+	}
+
+	lis := bufconn.Listen(lightnetBufferSize)
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial lightnet: %w", err)
+	}
+
+	l.server = grpc.NewServer()
+	lightnetpb.RegisterHashServiceServer(l.server, l)
+	go l.server.Serve(lis)
+	l.conn = conn
+	return conn, nil
+}
+
+// Client returns a provable.LightnetClient connected to the server through Conn
+func (l *LightnetServer) Client(opts ...provable.LightnetOption) (*provable.LightnetClient, error) {
+	conn, err := l.Conn()
+	if err != nil {
+		return nil, err
+	}
+	return provable.NewLightnetClient(lightnetpb.NewHashServiceClient(conn), opts...)
+}
+
+// Close stops the in-memory listener started by Conn, if any
+func (l *LightnetServer) Close() {
+	l.connMu.Lock()
+	defer l.connMu.Unlock()
+	if l.conn == nil {
+		return
+	}
+	l.conn.Close()
+	l.server.Stop()
+	l.conn, l.server = nil, nil
+}
+
+// SubmitHash appends the data item to the chain
+func (l *LightnetServer) SubmitHash(ctx context.Context, req *lightnetpb.HashRequest) (*lightnetpb.HashResponse, error) {
+	return l.submit(req), nil
+}
+
+// SubmitHashStream appends each data item received, answering each in turn
+func (l *LightnetServer) SubmitHashStream(stream grpc.BidiStreamingServer[lightnetpb.HashRequest, lightnetpb.HashResponse]) error {
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(l.submit(req)); err != nil {
+			return err
+		}
+	}
+}
+
+func (l *LightnetServer) submit(req *lightnetpb.HashRequest) *lightnetpb.HashResponse {
+	dataType, dataItem, err := hashFields(req.GetDataType(), req.GetDataItem())
+	if err != nil {
+		return &lightnetpb.HashResponse{Success: false, Message: err.Error()}
+	}
+
+	record, err := l.Append(dataType, dataItem)
+	if err != nil {
+		return &lightnetpb.HashResponse{Success: false, Message: err.Error()}
+	}
+	return &lightnetpb.HashResponse{
+		Success:         true,
+		Message:         "hash recorded",
+		ComputedHashHex: record.HashItemHex,
+		TimeuuidHex:     record.UUIDHex,
+		DataTypeHex:     record.DataType,
+		DataItemHex:     record.DataItemHex,
+	}
+}
+
+// DebugHash returns the record SubmitHash would append, without appending it
+func (l *LightnetServer) DebugHash(ctx context.Context, req *lightnetpb.DebugHashRequest) (*lightnetpb.DebugHashResponse, error) {
+	dataType, dataItem, err := hashFields(req.GetDataType(), req.GetDataItem())
+	if err != nil {
+		return &lightnetpb.DebugHashResponse{Success: false, Message: err.Error()}, nil
+	}
+
+	l.chain.mu.Lock()
+	record, input, err := l.next(dataType, dataItem)
+	l.chain.mu.Unlock()
+	if err != nil {
+		return &lightnetpb.DebugHashResponse{Success: false, Message: err.Error()}, nil
+	}
+	return &lightnetpb.DebugHashResponse{
+		Success:         true,
+		HashInputHex:    input,
+		ComputedHashHex: record.HashItemHex,
+		PrevHashHex:     record.PrevHashHex,
+		UuidHex:         record.UUIDHex,
+	}, nil
+}
+
+// GetDatabaseStats returns the number of records
+func (l *LightnetServer) GetDatabaseStats(ctx context.Context, req *lightnetpb.DatabaseStatsRequest) (*lightnetpb.DatabaseStatsResponse, error) {
+	l.chain.mu.Lock()
+	defer l.chain.mu.Unlock()
+	return &lightnetpb.DatabaseStatsResponse{Success: true, TotalRecords: int64(len(l.records))}, nil
+}
+
+// GetRecord returns the latest record for the data item, or a NotFound
+// status when there is none
+func (l *LightnetServer) GetRecord(ctx context.Context, req *lightnetpb.GetRecordRequest) (*lightnetpb.GetRecordResponse, error) {
+	dataType, dataItem, err := hashFields(req.GetDataType(), req.GetDataItem())
+	if err != nil {
+		return &lightnetpb.GetRecordResponse{Success: false, Message: err.Error()}, nil
+	}
+
+	l.chain.mu.Lock()
+	defer l.chain.mu.Unlock()
+	i, ok := l.byItem[itemKey(dataType, dataItem)]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "record not found")
+	}
+	record := l.records[i]
+	return &lightnetpb.GetRecordResponse{
+		Success:     true,
+		UuidHex:     record.UUIDHex,
+		DataTypeHex: record.DataType,
+		DataItemHex: record.DataItemHex,
+		HashItemHex: record.HashItemHex,
+		Timestamp:   record.Timestamp,
+	}, nil
+}
+
+// GetMerkleProof returns the proof for the record at the requested position
+func (l *LightnetServer) GetMerkleProof(ctx context.Context, req *lightnetpb.MerkleProofRequest) (*lightnetpb.MerkleProofResponse, error) {
+	l.chain.mu.Lock()
+	defer l.chain.mu.Unlock()
+	tree, err := l.fullTree()
+	if err != nil {
+		return &lightnetpb.MerkleProofResponse{Success: false, Message: err.Error()}, nil
+	}
+	proof, err := tree.proof(req.GetHashPosition())
+	if err != nil {
+		return &lightnetpb.MerkleProofResponse{Success: false, Message: err.Error()}, nil
+	}
+	return &lightnetpb.MerkleProofResponse{
+		Success:        true,
+		ProofHashesHex: proof.ProofHashesHex,
+		Levels:         int32(proof.Levels),
+		Position:       proof.Position,
+		RootHashHex:    proof.RootHashHex,
+	}, nil
+}
+
+// GetMerkleRoot returns the root of the tree over every record
+func (l *LightnetServer) GetMerkleRoot(ctx context.Context, req *lightnetpb.MerkleRootRequest) (*lightnetpb.MerkleRootResponse, error) {
+	l.chain.mu.Lock()
+	defer l.chain.mu.Unlock()
+	tree, err := l.fullTree()
+	if err != nil {
+		return &lightnetpb.MerkleRootResponse{Success: false, Message: err.Error()}, nil
+	}
+	return &lightnetpb.MerkleRootResponse{Success: true, RootHashHex: tree.root(), TotalRecords: int64(tree.leaves)}, nil
+}
+
+// VerifyMerkleProof checks that a proof leads to its root and that the
+// root is one the server has proved against
+func (l *LightnetServer) VerifyMerkleProof(ctx context.Context, req *lightnetpb.VerifyMerkleProofRequest) (*lightnetpb.VerifyMerkleProofResponse, error) {
+	result, err := l.verifyProof(provable.VerifyMerkleProofRequest{
+		TargetHashHex:  req.GetTargetHashHex(),
+		Position:       req.GetPosition(),
+		RootHashHex:    req.GetRootHashHex(),
+		ProofHashesHex: req.GetProofHashesHex(),
+		Levels:         int(req.GetLevels()),
+	})
+	if err != nil {
+		return &lightnetpb.VerifyMerkleProofResponse{Success: false, Message: err.Error()}, nil
+	}
+	return &lightnetpb.VerifyMerkleProofResponse{Success: true, Message: result.Message, IsValid: result.Valid}, nil
+}
+
+// hashFields checks that a data type and data item are 32 bytes each and
+// returns them as hex
+func hashFields(dataType, dataItem []byte) (string, string, error) {
+	if len(dataType) != 32 {
+		return "", "", fmt.Errorf("data_type must be exactly 32 bytes, got %d", len(dataType))
+	}
+	if len(dataItem) != 32 {
+		return "", "", fmt.Errorf("data_item must be exactly 32 bytes, got %d", len(dataItem))
+	}
+	return hex.EncodeToString(dataType), hex.EncodeToString(dataItem), nil
+}
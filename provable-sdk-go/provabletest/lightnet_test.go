@@ -0,0 +1,146 @@
+package provabletest
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"io"
+	"testing"
+
+	provable "github.com/provable/provable-sdk-go"
+	lightnetpb "github.com/provable/provable-sdk-go/proto/lightnet"
+)
+
+func newTestLightnet(t *testing.T, opts ...Option) (*LightnetServer, *provable.LightnetClient) {
+	t.Helper()
+	l := NewLightnetServer(opts...)
+	t.Cleanup(l.Close)
+	c, err := l.Client()
+	if err != nil {
+		t.Fatalf("Client() error = %v", err)
+	}
+	return l, c
+}
+
+func TestLightnetServer(t *testing.T) {
+	ctx := context.Background()
+	items := []string{provable.Keccak256Str("a"), provable.Keccak256Str("b"), provable.Keccak256Str("c")}
+
+	t.Run("submit and get records", func(t *testing.T) {
+		l, c := newTestLightnet(t)
+		debug, err := c.DebugHash(ctx, items[0])
+		if err != nil {
+			t.Fatalf("DebugHash() error = %v", err)
+		}
+		resp, err := c.SubmitHash(ctx, items[0])
+		if err != nil {
+			t.Fatalf("SubmitHash() error = %v", err)
+		}
+		if resp.ComputedHashHex != debug.ComputedHashHex || resp.TimeuuidHex != debug.UUIDHex {
+			t.Errorf("SubmitHash() = %+v, want the record DebugHash predicted %+v", resp, debug)
+		}
+
+		record, err := c.GetRecord(ctx, items[0])
+		if err != nil || record.HashItemHex != resp.ComputedHashHex {
+			t.Errorf("GetRecord() = %+v, %v, want hash %s", record, err, resp.ComputedHashHex)
+		}
+		if stored := l.Records()[0]; stored.UUIDHex != record.UUIDHex || stored.Timestamp != record.Timestamp {
+			t.Errorf("Records() = %+v, want %+v", stored, record)
+		}
+		if stats, err := c.GetDatabaseStats(ctx); err != nil || stats.TotalRecords != 1 {
+			t.Errorf("GetDatabaseStats() = %+v, %v, want 1 record", stats, err)
+		}
+	})
+
+	t.Run("report missing records and bad requests", func(t *testing.T) {
+		l, c := newTestLightnet(t)
+		var lnErr *provable.LightnetError
+		if _, err := c.GetRecord(ctx, items[0]); !errors.As(err, &lnErr) || !errors.Is(err, provable.ErrNotFound) {
+			t.Errorf("GetRecord() error = %v, want a *LightnetError matching ErrNotFound", err)
+		}
+		if _, err := c.GetMerkleRoot(ctx); !errors.As(err, &lnErr) {
+			t.Errorf("GetMerkleRoot() error = %v, want *LightnetError for an empty chain", err)
+		}
+
+		conn, _ := l.Conn()
+		resp, err := lightnetpb.NewHashServiceClient(conn).SubmitHash(ctx, &lightnetpb.HashRequest{DataType: make([]byte, 32), DataItem: []byte("short")})
+		if err != nil || resp.GetSuccess() {
+			t.Errorf("SubmitHash() = %+v, %v, want success=false", resp, err)
+		}
+	})
+
+	t.Run("prove records against the Merkle root", func(t *testing.T) {
+		l, c := newTestLightnet(t)
+		for _, item := range items {
+			if _, err := c.SubmitHash(ctx, item); err != nil {
+				t.Fatalf("SubmitHash() error = %v", err)
+			}
+		}
+		root, err := c.GetMerkleRoot(ctx)
+		if err != nil || root.TotalRecords != 3 {
+			t.Fatalf("GetMerkleRoot() = %+v, %v, want 3 records", root, err)
+		}
+
+		proof, err := c.GetMerkleProof(ctx, 2)
+		if err != nil {
+			t.Fatalf("GetMerkleProof() error = %v", err)
+		}
+		proof.TargetHashHex = l.Records()[2].HashItemHex
+		if proof.RootHashHex != root.RootHashHex {
+			t.Errorf("GetMerkleProof() root = %s, want %s", proof.RootHashHex, root.RootHashHex)
+		}
+		if result, err := provable.VerifyMerkleProofLocal(*proof); err != nil || !result.Valid {
+			t.Errorf("VerifyMerkleProofLocal() = %+v, %v, want valid", result, err)
+		}
+
+		request := provable.VerifyMerkleProofRequest{
+			TargetHashHex:  proof.TargetHashHex,
+			ProofHashesHex: proof.ProofHashesHex,
+			Levels:         proof.Levels,
+			Position:       proof.Position,
+			RootHashHex:    proof.RootHashHex,
+		}
+		if result, err := c.VerifyMerkleProof(ctx, request); err != nil || !result.Valid {
+			t.Errorf("VerifyMerkleProof() = %+v, %v, want valid", result, err)
+		}
+		request.Position = 1
+		if result, err := c.VerifyMerkleProof(ctx, request); err != nil || result.Valid {
+			t.Errorf("VerifyMerkleProof() = %+v, %v, want invalid at the wrong position", result, err)
+		}
+		if _, err := c.GetMerkleProof(ctx, 3); err == nil {
+			t.Error("GetMerkleProof() error = nil, want error past the last record")
+		}
+	})
+
+	t.Run("stream submissions", func(t *testing.T) {
+		l, _ := newTestLightnet(t)
+		conn, _ := l.Conn()
+		stream, err := lightnetpb.NewHashServiceClient(conn).SubmitHashStream(ctx)
+		if err != nil {
+			t.Fatalf("SubmitHashStream() error = %v", err)
+		}
+		dataType, _ := hex.DecodeString(provable.DataType)
+		for _, item := range items {
+			dataItem, _ := hex.DecodeString(item)
+			if err := stream.Send(&lightnetpb.HashRequest{DataType: dataType, DataItem: dataItem}); err != nil {
+				t.Fatalf("Send() error = %v", err)
+			}
+		}
+		stream.CloseSend()
+
+		var got []string
+		for {
+			resp, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil || !resp.GetSuccess() {
+				t.Fatalf("Recv() = %+v, %v", resp, err)
+			}
+			got = append(got, resp.GetDataItemHex())
+		}
+		if len(got) != 3 || got[2] != items[2] || len(l.Records()) != 3 {
+			t.Errorf("SubmitHashStream() = %v, want the 3 items in order", got)
+		}
+	})
+}
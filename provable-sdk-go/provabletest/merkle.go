@@ -0,0 +1,114 @@
+package provabletest
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	provable "github.com/provable/provable-sdk-go"
+)
+
+// merkleTree is a Merkle tree over record hashes, padded with zero leaves to
+// a power of two, whose nodes are hashed with provable.ComputeMerkleRoot so
+// the fake follows the SDK's Merkle rules rather than repeating them
+type merkleTree struct {
+	// levels[0] holds the padded leaves and levels[len(levels)-1] the root,
+	// all as hex
+	levels [][]string
+	leaves int
+}
+
+func newMerkleTree(leavesHex []string) (*merkleTree, error) {
+	if len(leavesHex) == 0 {
+		return nil, errors.New("no records")
+	}
+	width := 1
+	for width < len(leavesHex) {
+		width *= 2
+	}
+	level := make([]string, width)
+	for i := range level {
+		level[i] = strings.Repeat("00", 32)
+	}
+	for i, leaf := range leavesHex {
+		if b, err := hex.DecodeString(leaf); err != nil || len(b) != 32 {
+			return nil, fmt.Errorf("leaf %d is not a 32-byte hash", i)
+		}
+		level[i] = strings.ToLower(leaf)
+	}
+
+	t := &merkleTree{levels: [][]string{level}, leaves: len(leavesHex)}
+	for len(level) > 1 {
+		parents := make([]string, len(level)/2)
+		for i := range parents {
+			parent, err := provable.ComputeMerkleRoot(level[2*i], []string{level[2*i+1]}, 1, 0)
+			if err != nil {
+				return nil, err
+			}
+			parents[i] = parent
+		}
+		t.levels = append(t.levels, parents)
+		level = parents
+	}
+	return t, nil
+}
+
+func (t *merkleTree) root() string {
+	return t.levels[len(t.levels)-1][0]
+}
+
+// proof returns the proof for the leaf at position in the 256-entry sparse
+// layout, leaving siblings that are padding leaves empty
+func (t *merkleTree) proof(position int64) (*provable.MerkleProof, error) {
+	if position < 0 || position >= int64(t.leaves) {
+		return nil, fmt.Errorf("position %d out of range for %d records", position, t.leaves)
+	}
+	siblings := make([]string, provable.MerkleProofSize)
+	index := position
+	for level := 0; level < len(t.levels)-1; level++ {
+		if sibling := index ^ 1; level > 0 || sibling < int64(t.leaves) {
+			siblings[level] = t.levels[level][sibling]
+		}
+		index /= 2
+	}
+	return &provable.MerkleProof{
+		TargetHashHex:  t.levels[0][position],
+		Position:       position,
+		RootHashHex:    t.root(),
+		ProofHashesHex: siblings,
+		Levels:         len(t.levels) - 1,
+		StoredRootHex:  t.root(),
+	}, nil
+}
+
+// verifyProof checks that a proof leads to its root and that the root is
+// one the chain has proved against, reported as StoredRootHex
+func (c *chain) verifyProof(req provable.VerifyMerkleProofRequest) (*provable.MerkleProofVerificationResult, error) {
+	computed, err := provable.ComputeMerkleRoot(req.TargetHashHex, req.ProofHashesHex, req.Levels, req.Position)
+	if err != nil {
+		return nil, err
+	}
+	root := strings.ToLower(req.RootHashHex)
+
+	c.mu.Lock()
+	stored := c.roots[root]
+	c.mu.Unlock()
+
+	result := &provable.MerkleProofVerificationResult{
+		ComputedRootHex: computed,
+		TargetHashHex:   req.TargetHashHex,
+		Position:        req.Position,
+	}
+	switch {
+	case computed != root:
+		result.Message = "computed root does not match expected root"
+	case !stored:
+		result.Message = "root is not a stored root"
+	default:
+		result.Valid = true
+		result.StoredRootHex = root
+		result.Message = "Merkle proof is valid"
+	}
+	return result, nil
+}
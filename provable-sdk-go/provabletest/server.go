@@ -0,0 +1,445 @@
+// Package provabletest provides an in-process fake Kayros and Lightnet for
+// tests
+//
+// A Server answers the Kayros HTTP API from an in-memory hash chain: every
+// proved hash is appended as a record linked to the one before it, with a
+// deterministic timestamp and timeuuid unless WithClock is given, so tests
+// see the same hashes on every run. Faults can be injected to exercise error
+// handling. A LightnetServer answers the Lightnet gRPC API from a chain of
+// its own.
+package provabletest
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	provable "github.com/provable/provable-sdk-go"
+)
+
+// TableName is the table the server exposes to the table browsing API
+const TableName = "hashes"
+
+// Option configures a Server or LightnetServer
+type Option func(*chain)
+
+// WithStartTime sets the timestamp of the first record (defaults to
+// DefaultStartTime)
+func WithStartTime(t time.Time) Option {
+	return func(c *chain) {
+		c.start = t.UTC()
+	}
+}
+
+// WithTick sets the time between consecutive records (defaults to
+// DefaultTick)
+func WithTick(d time.Duration) Option {
+	return func(c *chain) {
+		c.tick = d
+	}
+}
+
+// WithClock timestamps each record with clock() instead of the start time
+// plus one tick per record, e.g. time.Now for a long-running fake
+// A timestamp earlier than the previous record's is raised to it, so
+// records stay in timestamp order if the clock steps back.
+func WithClock(clock func() time.Time) Option {
+	return func(c *chain) {
+		c.clock = clock
+	}
+}
+
+// WithHashType sets how record hashes are computed, provable.RecordHashBLAKE3
+// (the default) or provable.RecordHashXXH3
+// XXH3 hashes are 8 bytes, so Merkle proofs are not available with them.
+func WithHashType(hashType string) Option {
+	return func(c *chain) {
+		c.hashType = hashType
+	}
+}
+
+// Server is a fake Kayros deployment backed by an in-memory hash chain
+type Server struct {
+	// URL is the base URL of the server, for provable.WithBaseURL
+	URL string
+
+	*chain
+	server *httptest.Server
+	mux    *http.ServeMux
+
+	// mu guards faults and requests; the chain has its own lock
+	mu       sync.Mutex
+	faults   []*Fault
+	requests map[string]int
+}
+
+// NewServer starts a Server, which the caller must Close
+func NewServer(opts ...Option) *Server {
+	s := &Server{
+		chain:    newChain(opts),
+		requests: make(map[string]int),
+	}
+
+	s.mux = http.NewServeMux()
+	s.mux.HandleFunc("POST "+provable.ProveSingleHashRoute, s.handleProve)
+	s.mux.HandleFunc("GET "+provable.GetRecordByHashRoute, s.handleRecordByHash)
+	s.mux.HandleFunc("POST /api/database/query", s.handleQuery)
+	s.mux.HandleFunc("GET /api/database/stats", s.handleStats)
+	s.mux.HandleFunc("GET /api/database/latest", s.handleLatest)
+	s.mux.HandleFunc("GET /api/database/tables", s.handleTables)
+	s.mux.HandleFunc("GET /api/database/schema", s.handleSchema)
+	s.mux.HandleFunc("POST /api/database/browse", s.handleBrowse)
+	s.mux.HandleFunc("GET /api/database/record", s.handleRecord)
+	s.mux.HandleFunc("GET /api/database/record-with-prev", s.handleRecord)
+	s.mux.HandleFunc("POST /api/verify-hash", s.handleVerifyHash)
+	s.mux.HandleFunc("POST /api/compute-hash-from-hex", s.handleComputeHash)
+	s.mux.HandleFunc("POST /api/merkle/generate-proof", s.handleGenerateProof)
+	s.mux.HandleFunc("POST /api/merkle/verify-proof", s.handleVerifyProof)
+
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.server.URL
+	return s
+}
+
+// Close shuts the server down
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// Client returns a provable.Client talking to the server, adjusted by opts
+// Retries are disabled unless opts set a retry policy.
+func (s *Server) Client(opts ...provable.Option) (*provable.Client, error) {
+	base := []provable.Option{
+		provable.WithBaseURL(s.URL),
+		provable.WithHTTPClient(s.server.Client()),
+		provable.WithRetryPolicy(provable.NoRetry),
+	}
+	return provable.NewClient(append(base, opts...)...)
+}
+
+// Requests returns how many requests the server received for path, or in
+// total when path is empty
+func (s *Server) Requests(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if path == "" {
+		total := 0
+		for _, n := range s.requests {
+			total += n
+		}
+		return total
+	}
+	return s.requests[path]
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests[r.URL.Path]++
+	fault := s.takeFault(r.URL.Path)
+	s.mu.Unlock()
+
+	if fault != nil && fault.apply(w, r) {
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) handleProve(w http.ResponseWriter, r *http.Request) {
+	var req provable.SingleHashRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	if err := provable.ValidateDataType(req.DataType); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !isHash32(req.DataItem) {
+		writeError(w, http.StatusBadRequest, "data_item must be exactly 64 hex characters (32 bytes)")
+		return
+	}
+
+	record, err := s.Append(strings.ToLower(req.DataType), strings.ToLower(req.DataItem))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeData(w, provable.SingleHashResponse{
+		Success:         true,
+		Message:         "hash recorded",
+		DataType:        record.DataType,
+		DataItem:        record.DataItemHex,
+		ComputedHashHex: record.HashItemHex,
+		TimeuuidHex:     record.UUIDHex,
+		DataTypeHex:     record.DataType,
+		DataItemHex:     record.DataItemHex,
+	})
+}
+
+func (s *Server) handleRecordByHash(w http.ResponseWriter, r *http.Request) {
+	record, ok := s.Record(strings.ToLower(r.URL.Query().Get("hash_item")))
+	if !ok {
+		writeError(w, http.StatusNotFound, "record not found")
+		return
+	}
+	writeData(w, record)
+}
+
+func (s *Server) handleRecord(w http.ResponseWriter, r *http.Request) {
+	s.chain.mu.Lock()
+	i, ok := s.byUUID[strings.ToLower(r.URL.Query().Get("uuid"))]
+	var record provable.DatabaseRecord
+	if ok {
+		record = s.records[i]
+	}
+	s.chain.mu.Unlock()
+
+	if !ok {
+		writeError(w, http.StatusNotFound, "record not found")
+		return
+	}
+	if r.URL.Path != "/api/database/record-with-prev" {
+		record.PrevHashHex = ""
+	}
+	writeData(w, record)
+}
+
+func (s *Server) handleQuery(w http.ResponseWriter, r *http.Request) {
+	var query provable.DatabaseQuery
+	if !decodeRequest(w, r, &query) {
+		return
+	}
+
+	s.chain.mu.Lock()
+	var matched []provable.HashRecord
+	for _, record := range s.records {
+		if !matches(query.DataType, record.DataType) || !matches(query.HashType, record.HashType) {
+			continue
+		}
+		if query.MinTimestamp != nil && record.Timestamp < *query.MinTimestamp {
+			continue
+		}
+		if query.MaxTimestamp != nil && record.Timestamp > *query.MaxTimestamp {
+			continue
+		}
+		matched = append(matched, hashRecord(record))
+	}
+	s.chain.mu.Unlock()
+
+	if query.OrderBy == provable.OrderTimestampDesc {
+		slices.Reverse(matched)
+	}
+	writeData(w, page(matched, query.Offset, query.Limit))
+}
+
+func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
+	s.chain.mu.Lock()
+	defer s.chain.mu.Unlock()
+	stats := provable.DatabaseStats{
+		TotalHashes: int64(len(s.records)),
+		CountByType: make(map[string]int64),
+	}
+	for _, record := range s.records {
+		stats.CountByType[record.DataType]++
+	}
+	if n := len(s.records); n > 0 {
+		stats.MinTimestamp = s.records[0].Timestamp
+		stats.MaxTimestamp = s.records[n-1].Timestamp
+		stats.TimestampRange = fmt.Sprintf("%s to %s", stats.MinTimestamp, stats.MaxTimestamp)
+	}
+	writeData(w, stats)
+}
+
+func (s *Server) handleLatest(w http.ResponseWriter, r *http.Request) {
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "limit must be an integer")
+		return
+	}
+
+	s.chain.mu.Lock()
+	latest := make([]provable.HashRecord, 0, len(s.records))
+	for i := len(s.records) - 1; i >= 0; i-- {
+		latest = append(latest, hashRecord(s.records[i]))
+	}
+	s.chain.mu.Unlock()
+	writeData(w, page(latest, 0, limit))
+}
+
+func (s *Server) handleTables(w http.ResponseWriter, r *http.Request) {
+	writeData(w, []string{TableName})
+}
+
+// tableColumns are the columns of TableName, named as in DatabaseRecord's JSON
+var tableColumns = []string{"data_type", "data_item_hex", "uuid_hex", "hash_item_hex", "prev_hash_hex", "hash_type", "timestamp"}
+
+func (s *Server) handleSchema(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("table") != TableName {
+		writeError(w, http.StatusNotFound, "table not found")
+		return
+	}
+	columns := make([]provable.ColumnInfo, len(tableColumns))
+	for i, name := range tableColumns {
+		columns[i] = provable.ColumnInfo{Name: name, Type: "text"}
+	}
+	writeData(w, columns)
+}
+
+func (s *Server) handleBrowse(w http.ResponseWriter, r *http.Request) {
+	var req provable.TableBrowseRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	if req.TableName != TableName {
+		writeError(w, http.StatusNotFound, "table not found")
+		return
+	}
+	if req.SearchTerm != "" && !slices.Contains(tableColumns, req.SearchColumn) {
+		writeError(w, http.StatusBadRequest, "unknown search column")
+		return
+	}
+
+	s.chain.mu.Lock()
+	var rows []map[string]interface{}
+	for _, record := range s.records {
+		row := recordRow(record)
+		if req.SearchTerm != "" && !strings.Contains(row[req.SearchColumn].(string), req.SearchTerm) {
+			continue
+		}
+		rows = append(rows, row)
+	}
+	s.chain.mu.Unlock()
+
+	if req.OrderBy == provable.OrderTimestampDesc {
+		slices.Reverse(rows)
+	}
+	writeData(w, page(rows, req.Offset, req.Limit))
+}
+
+func (s *Server) handleVerifyHash(w http.ResponseWriter, r *http.Request) {
+	var req provable.HashVerifyRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	result, err := provable.VerifyHashLocal(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeData(w, result)
+}
+
+func (s *Server) handleComputeHash(w http.ResponseWriter, r *http.Request) {
+	var req provable.ComputeHashRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	result, err := provable.ComputeHashFromHexLocal(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeData(w, result)
+}
+
+func (s *Server) handleGenerateProof(w http.ResponseWriter, r *http.Request) {
+	var req provable.GenerateMerkleProofRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	s.chain.mu.Lock()
+	defer s.chain.mu.Unlock()
+	i, ok := s.byHash[strings.ToLower(req.HashItem)]
+	if !ok || (req.DataType != "" && !strings.EqualFold(req.DataType, s.records[i].DataType)) {
+		writeError(w, http.StatusNotFound, "record not found")
+		return
+	}
+	record := s.records[i]
+	tree, position, err := s.merkleTree(record.DataType, record.HashItemHex)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	proof, err := tree.proof(position)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	proof.DataType = record.DataType
+	proof.Timestamp = record.Timestamp
+	proof.GeneratedAt = s.now().Format(TimestampLayout)
+	proof.LightnetVersion = "provabletest"
+	proof.ProofFormat = "sparse-256"
+	writeData(w, proof)
+}
+
+func (s *Server) handleVerifyProof(w http.ResponseWriter, r *http.Request) {
+	var req provable.VerifyMerkleProofRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	result, err := s.verifyProof(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeData(w, result)
+}
+
+func recordRow(record provable.DatabaseRecord) map[string]interface{} {
+	return map[string]interface{}{
+		"data_type":     record.DataType,
+		"data_item_hex": record.DataItemHex,
+		"uuid_hex":      record.UUIDHex,
+		"hash_item_hex": record.HashItemHex,
+		"prev_hash_hex": record.PrevHashHex,
+		"hash_type":     record.HashType,
+		"timestamp":     record.Timestamp,
+	}
+}
+
+// matches reports whether value passes an optional equality filter
+func matches(filter *string, value string) bool {
+	return filter == nil || strings.EqualFold(*filter, value)
+}
+
+// page returns items[offset:offset+limit], clamped, never nil
+func page[T any](items []T, offset, limit int) []T {
+	offset = max(0, min(offset, len(items)))
+	end := len(items)
+	if limit > 0 {
+		end = min(end, offset+limit)
+	}
+	return append([]T{}, items[offset:end]...)
+}
+
+func isHash32(s string) bool {
+	b, err := hex.DecodeString(s)
+	return err == nil && len(b) == 32
+}
+
+func decodeRequest(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body: "+err.Error())
+		return false
+	}
+	return true
+}
+
+func writeData(w http.ResponseWriter, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(provable.APIResponse{Success: true, Data: data})
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(provable.APIResponse{Success: false, Error: message})
+}
@@ -0,0 +1,240 @@
+package provabletest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	provable "github.com/provable/provable-sdk-go"
+)
+
+func newTestServer(t *testing.T, opts ...Option) (*Server, *provable.Client) {
+	t.Helper()
+	s := NewServer(opts...)
+	t.Cleanup(s.Close)
+	c, err := s.Client()
+	if err != nil {
+		t.Fatalf("Client() error = %v", err)
+	}
+	return s, c
+}
+
+// proveAll seals each value and returns the envelopes
+func proveAll(t *testing.T, c *provable.Client, values ...string) []*provable.KayrosEnvelope {
+	t.Helper()
+	envelopes := make([]*provable.KayrosEnvelope, len(values))
+	for i, v := range values {
+		envelope, err := c.Seal(v)
+		if err != nil {
+			t.Fatalf("Seal(%q) error = %v", v, err)
+		}
+		envelopes[i] = envelope
+	}
+	return envelopes
+}
+
+func TestServer(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("prove and verify envelopes", func(t *testing.T) {
+		_, c := newTestServer(t)
+		envelope := proveAll(t, c, "hello")[0]
+		if result := c.Verify(envelope); !result.Valid {
+			t.Errorf("Verify() = %+v, want valid", result)
+		}
+		if err := c.EnrichEnvelope(envelope); err != nil {
+			t.Fatalf("EnrichEnvelope() error = %v", err)
+		}
+		offline := provable.VerifyOptions{Offline: true, TrustedRoots: []string{envelope.Kayros.Evidence.MerkleProof.RootHashHex}}
+		if result := c.VerifyWithOptions(ctx, envelope, offline); !result.Valid || !result.Details.MerkleMatch {
+			t.Errorf("VerifyWithOptions() = %+v, want valid with Merkle match", result)
+		}
+	})
+
+	t.Run("give the same chain on every run", func(t *testing.T) {
+		a, ca := newTestServer(t)
+		b, cb := newTestServer(t)
+		proveAll(t, ca, "x", "y")
+		proveAll(t, cb, "x", "y")
+		ra, rb := a.Records(), b.Records()
+		if len(ra) != 2 || ra[1] != rb[1] {
+			t.Errorf("Records() = %+v and %+v, want identical", ra, rb)
+		}
+		if ra[0].Timestamp != "2024-01-01T00:00:00.000000Z" || ra[1].PrevHashHex != ra[0].HashItemHex {
+			t.Errorf("Records() = %+v, want linked records from DefaultStartTime", ra)
+		}
+	})
+
+	t.Run("timestamp records with a clock", func(t *testing.T) {
+		now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+		s, c := newTestServer(t, WithClock(func() time.Time { return now }))
+		proveAll(t, c, "x")
+		now = now.Add(-time.Minute)
+		proveAll(t, c, "y")
+		records := s.Records()
+		if records[0].Timestamp != "2026-03-01T12:00:00.000000Z" || records[1].Timestamp != records[0].Timestamp {
+			t.Errorf("Records() = %+v, want the clock's time, held when it steps back", records)
+		}
+		if records[0].UUIDHex == records[1].UUIDHex {
+			t.Errorf("UUIDHex = %s twice, want distinct timeuuids", records[0].UUIDHex)
+		}
+	})
+
+	t.Run("pass a chain audit", func(t *testing.T) {
+		s, c := newTestServer(t, WithHashType(provable.RecordHashXXH3))
+		proveAll(t, c, "a", "b", "c")
+		var uuids []string
+		for _, r := range s.Records() {
+			uuids = append(uuids, r.UUIDHex)
+		}
+		report, err := c.NewChainAuditor(provable.WithAuditRecomputeHashes()).AuditUUIDs(ctx, uuids)
+		if err != nil || !report.Valid || report.HashesChecked != 3 {
+			t.Errorf("AuditUUIDs() = %+v, %v, want 3 valid hashes", report, err)
+		}
+	})
+
+	t.Run("page through queries", func(t *testing.T) {
+		_, c := newTestServer(t)
+		proveAll(t, c, "1", "2", "3", "4", "5")
+		var got []string
+		for record, err := range c.AllHashes(ctx, provable.DatabaseQuery{Limit: 2, OrderBy: provable.OrderTimestampDesc}) {
+			if err != nil {
+				t.Fatalf("AllHashes() error = %v", err)
+			}
+			got = append(got, record.Timestamp)
+		}
+		if len(got) != 5 || got[0] < got[4] {
+			t.Errorf("AllHashes() = %v, want 5 records, newest first", got)
+		}
+	})
+
+	t.Run("serve the database API", func(t *testing.T) {
+		s, c := newTestServer(t)
+		proveAll(t, c, "1", "2", "3")
+		if stats, err := c.GetDatabaseStats(); err != nil || stats.TotalHashes != 3 || stats.CountByType[provable.DataType] != 3 {
+			t.Errorf("GetDatabaseStats() = %+v, %v, want 3 hashes", stats, err)
+		}
+		if latest, err := c.GetLatestHashes(2); err != nil || len(latest) != 2 || latest[0].HashItem != s.Records()[2].HashItemHex {
+			t.Errorf("GetLatestHashes() = %+v, %v, want the 2 newest", latest, err)
+		}
+		uuid := s.Records()[1].UUIDHex
+		rows, err := c.BrowseTable(provable.TableBrowseRequest{TableName: TableName, Limit: 10, SearchColumn: "uuid_hex", SearchTerm: uuid})
+		if err != nil || len(rows) != 1 {
+			t.Errorf("BrowseTable() = %v, %v, want 1 row", rows, err)
+		}
+		if record, err := c.GetRecord(uuid); err != nil || record.PrevHashHex != "" || record.HashItemHex != s.Records()[1].HashItemHex {
+			t.Errorf("GetRecord() = %+v, %v, want the record without prev hash", record, err)
+		}
+		if _, err := c.GetTableSchema("other"); !errors.Is(err, provable.ErrNotFound) {
+			t.Errorf("GetTableSchema() error = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("hash records like the SDK", func(t *testing.T) {
+		_, c := newTestServer(t)
+		// The digests of empty input published with BLAKE3 and XXH3
+		published := map[string]string{
+			provable.RecordHashBLAKE3: "af1349b9f5f9a1a6a0404dea36dcc9499bcb25c9adc112b7cc9a93cae41f3262",
+			provable.RecordHashXXH3:   "2d06800538d394c2",
+		}
+		request := provable.HashVerifyRequest{
+			PrevHash: strings.Repeat("01", 32),
+			DataType: strings.Repeat("02", 32),
+			DataItem: strings.Repeat("03", 32),
+			UUID:     strings.Repeat("04", 16),
+		}
+		for hashType, want := range published {
+			if result, err := c.ComputeHashFromHex(provable.ComputeHashRequest{HashType: hashType}); err != nil || result.ComputedHash != want {
+				t.Errorf("ComputeHashFromHex(%s) = %+v, %v, want %s", hashType, result, err, want)
+			}
+			request.HashType = hashType
+			remote, err := c.VerifyHash(request)
+			if err != nil {
+				t.Fatalf("VerifyHash(%s) error = %v", hashType, err)
+			}
+			local, err := provable.VerifyHashLocal(request)
+			if err != nil {
+				t.Fatalf("VerifyHashLocal(%s) error = %v", hashType, err)
+			}
+			if *remote != *local {
+				t.Errorf("VerifyHash(%s) = %+v, want %+v", hashType, remote, local)
+			}
+		}
+		if _, err := c.VerifyHash(provable.HashVerifyRequest{DataType: "02", DataItem: request.DataItem, UUID: request.UUID}); err == nil {
+			t.Error("VerifyHash() with a short data type error = nil, want error")
+		}
+	})
+
+	t.Run("reject malformed hashes", func(t *testing.T) {
+		_, c := newTestServer(t)
+		if _, err := c.ProveSingleHash("abc"); err == nil {
+			t.Error("ProveSingleHash() error = nil, want error")
+		}
+	})
+}
+
+func TestFaults(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("fail a number of requests", func(t *testing.T) {
+		s, c := newTestServer(t)
+		s.Inject(Fault{Path: provable.ProveSingleHashRoute, Times: 1, Status: http.StatusServiceUnavailable})
+		if _, err := c.ProveDataStr("x"); err == nil {
+			t.Fatal("ProveDataStr() error = nil, want error")
+		}
+		if _, err := c.ProveDataStr("x"); err != nil {
+			t.Errorf("ProveDataStr() error = %v after the fault was used up", err)
+		}
+		if n := s.Requests(provable.ProveSingleHashRoute); n != 2 {
+			t.Errorf("Requests() = %d, want 2", n)
+		}
+	})
+
+	t.Run("serve malformed responses", func(t *testing.T) {
+		s, c := newTestServer(t)
+		envelope := proveAll(t, c, "x")[0]
+		s.Inject(Fault{Path: provable.GetRecordByHashRoute, Body: `{"data": [`})
+		if result := c.Verify(envelope); result.Code != provable.VerifyErrorMalformedRemoteRecord {
+			t.Errorf("Verify() = %+v, want malformed remote record", result)
+		}
+		s.ClearFaults()
+		if result := c.Verify(envelope); !result.Valid {
+			t.Errorf("Verify() = %+v, want valid after ClearFaults", result)
+		}
+	})
+
+	t.Run("delay responses", func(t *testing.T) {
+		s, c := newTestServer(t)
+		s.Inject(Fault{Delay: time.Second})
+		ctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		defer cancel()
+		if _, err := c.GetDatabaseStatsContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("GetDatabaseStatsContext() error = %v, want context.DeadlineExceeded", err)
+		}
+	})
+
+	t.Run("send headers", func(t *testing.T) {
+		s, c := newTestServer(t)
+		s.Inject(Fault{Status: http.StatusTooManyRequests, Header: http.Header{"Retry-After": {"0"}}, Times: 1})
+		c, _ = s.Client(provable.WithRetryPolicy(provable.RetryPolicy{MaxAttempts: 2, RetryableStatusCodes: []int{http.StatusTooManyRequests}}))
+		if _, err := c.GetTables(); err != nil {
+			t.Errorf("GetTables() error = %v, want success on retry", err)
+		}
+	})
+}
+
+func TestTimeUUID(t *testing.T) {
+	u := TimeUUID(DefaultStartTime, 1)
+	if len(u) != 32 || u[12] != '1' || !strings.ContainsAny(u[16:17], "89ab") {
+		t.Errorf("TimeUUID() = %s, want a version 1 RFC 4122 UUID", u)
+	}
+	if again := TimeUUID(DefaultStartTime, 1); again != u {
+		t.Errorf("TimeUUID() = %s then %s, want the same", u, again)
+	}
+	if other := TimeUUID(DefaultStartTime.Add(time.Millisecond), 1); other == u {
+		t.Errorf("TimeUUID() = %s for different times", u)
+	}
+}
@@ -1,15 +1,49 @@
 package provable
 
+import "context"
+
+// ProveData proves data by computing its hash and calling Kayros API
+// dataType is optional and defaults to the client's data type
+func (c *Client) ProveData(data []byte, dataType ...string) (*ProveSingleHashResponse, error) {
+	return c.ProveDataContext(context.Background(), data, dataType...)
+}
+
+// ProveDataContext is like ProveData but honors ctx cancellation and deadlines
+func (c *Client) ProveDataContext(ctx context.Context, data []byte, dataType ...string) (*ProveSingleHashResponse, error) {
+	dataHash := Keccak256(data)
+	return c.ProveSingleHashContext(ctx, dataHash, dataType...)
+}
+
+// ProveDataStr proves string data by computing its hash and calling Kayros API
+// dataType is optional and defaults to the client's data type
+func (c *Client) ProveDataStr(s string, dataType ...string) (*ProveSingleHashResponse, error) {
+	return c.ProveDataStrContext(context.Background(), s, dataType...)
+}
+
+// ProveDataStrContext is like ProveDataStr but honors ctx cancellation and deadlines
+func (c *Client) ProveDataStrContext(ctx context.Context, s string, dataType ...string) (*ProveSingleHashResponse, error) {
+	dataHash := Keccak256Str(s)
+	return c.ProveSingleHashContext(ctx, dataHash, dataType...)
+}
+
 // ProveData proves data by computing its hash and calling Kayros API
 // dataType is optional and defaults to "provable_sdk" padded to 32 bytes
 func ProveData(data []byte, dataType ...string) (*ProveSingleHashResponse, error) {
-	dataHash := Keccak256(data)
-	return ProveSingleHash(dataHash, dataType...)
+	return DefaultClient.ProveData(data, dataType...)
+}
+
+// ProveDataContext is like ProveData but honors ctx cancellation and deadlines
+func ProveDataContext(ctx context.Context, data []byte, dataType ...string) (*ProveSingleHashResponse, error) {
+	return DefaultClient.ProveDataContext(ctx, data, dataType...)
 }
 
 // ProveDataStr proves string data by computing its hash and calling Kayros API
 // dataType is optional and defaults to "provable_sdk" padded to 32 bytes
 func ProveDataStr(s string, dataType ...string) (*ProveSingleHashResponse, error) {
-	dataHash := Keccak256Str(s)
-	return ProveSingleHash(dataHash, dataType...)
+	return DefaultClient.ProveDataStr(s, dataType...)
+}
+
+// ProveDataStrContext is like ProveDataStr but honors ctx cancellation and deadlines
+func ProveDataStrContext(ctx context.Context, s string, dataType ...string) (*ProveSingleHashResponse, error) {
+	return DefaultClient.ProveDataStrContext(ctx, s, dataType...)
 }
@@ -0,0 +1,383 @@
+package provable
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Receipt is what Kayros returned when a hash was proved, kept so the proof
+// can be found and checked later without asking Kayros again
+type Receipt struct {
+	// DataHash is the hash that was proved
+	DataHash string `json:"dataHash,omitempty"`
+	// DataType is the Kayros data type it was proved under
+	DataType string `json:"dataType,omitempty"`
+	// ComputedHash is the hash of the Kayros record, which identifies the
+	// receipt
+	ComputedHash string `json:"computedHash"`
+	// TimeUUID is the timeuuid of the Kayros record
+	TimeUUID string `json:"timeuuid,omitempty"`
+	// Timestamp is when Kayros recorded the hash, as Kayros formats it
+	Timestamp string `json:"timestamp,omitempty"`
+	// StoredAt is when the receipt was first stored
+	StoredAt time.Time `json:"storedAt"`
+
+	Proof       *ProveSingleHashResponse `json:"proof,omitempty"`
+	Record      *GetRecordResponse       `json:"record,omitempty"`
+	ChainRecord *DatabaseRecord          `json:"chainRecord,omitempty"`
+	MerkleProof *MerkleProof             `json:"merkleProof,omitempty"`
+}
+
+// ReceiptFromEnvelope returns the receipt for a timestamped envelope,
+// including its evidence if it was enriched
+func ReceiptFromEnvelope(envelope *KayrosEnvelope) (Receipt, error) {
+	if envelope.Kayros.Timestamp == nil {
+		return Receipt{}, errors.New("envelope has no timestamp to take a receipt from")
+	}
+	var proof ProveSingleHashResponse
+	if err := convertJSON(envelope.Kayros.Timestamp.Response, &proof); err != nil {
+		return Receipt{}, fmt.Errorf("invalid timestamp response: %w", err)
+	}
+
+	r := Receipt{
+		DataHash: envelope.Kayros.Hash,
+		DataType: envelope.Kayros.DataType,
+		Proof:    &proof,
+	}
+	if evidence := envelope.Kayros.Evidence; evidence != nil {
+		r.Record = evidence.Record
+		r.ChainRecord = evidence.ChainRecord
+		r.MerkleProof = evidence.MerkleProof
+	}
+	r.fill()
+	return r, nil
+}
+
+// Evidence returns the receipt's records and Merkle proof as envelope
+// evidence, for VerifyOptions{Offline: true}, or nil without a record
+func (r *Receipt) Evidence() *KayrosEvidence {
+	if r.Record == nil && r.ChainRecord == nil {
+		return nil
+	}
+	return &KayrosEvidence{Record: r.Record, ChainRecord: r.ChainRecord, MerkleProof: r.MerkleProof}
+}
+
+// fill sets the fields that can be taken from Proof, the records and
+// MerkleProof
+func (r *Receipt) fill() {
+	if r.Proof != nil {
+		r.ComputedHash = firstNonEmpty(r.ComputedHash, r.Proof.Data.ComputedHashHex)
+		r.TimeUUID = firstNonEmpty(r.TimeUUID, r.Proof.Data.TimeuuidHex)
+	}
+	if r.Record != nil {
+		r.DataHash = firstNonEmpty(r.DataHash, r.Record.Data.DataItemHex)
+		r.DataType = firstNonEmpty(r.DataType, r.Record.Data.DataType)
+		r.TimeUUID = firstNonEmpty(r.TimeUUID, r.Record.Data.UUIDHex)
+		r.Timestamp = firstNonEmpty(r.Timestamp, r.Record.Data.Timestamp)
+	}
+	if r.ChainRecord != nil {
+		r.DataHash = firstNonEmpty(r.DataHash, r.ChainRecord.DataItemHex)
+		r.DataType = firstNonEmpty(r.DataType, r.ChainRecord.DataType)
+		r.TimeUUID = firstNonEmpty(r.TimeUUID, r.ChainRecord.UUIDHex)
+		r.Timestamp = firstNonEmpty(r.Timestamp, r.ChainRecord.Timestamp)
+	}
+	if r.MerkleProof != nil {
+		r.ComputedHash = firstNonEmpty(r.ComputedHash, r.MerkleProof.TargetHashHex)
+		r.DataType = firstNonEmpty(r.DataType, r.MerkleProof.DataType)
+		r.Timestamp = firstNonEmpty(r.Timestamp, r.MerkleProof.Timestamp)
+	}
+	r.DataHash = strings.ToLower(r.DataHash)
+	r.ComputedHash = strings.ToLower(r.ComputedHash)
+	r.TimeUUID = strings.ToLower(strings.ReplaceAll(r.TimeUUID, "-", ""))
+}
+
+// merge returns r with the fields set in newer replacing its own
+func (r Receipt) merge(newer Receipt) Receipt {
+	r.DataHash = firstNonEmpty(newer.DataHash, r.DataHash)
+	r.DataType = firstNonEmpty(newer.DataType, r.DataType)
+	r.TimeUUID = firstNonEmpty(newer.TimeUUID, r.TimeUUID)
+	r.Timestamp = firstNonEmpty(newer.Timestamp, r.Timestamp)
+	if newer.Proof != nil {
+		r.Proof = newer.Proof
+	}
+	if newer.Record != nil {
+		r.Record = newer.Record
+	}
+	if newer.ChainRecord != nil {
+		r.ChainRecord = newer.ChainRecord
+	}
+	if newer.MerkleProof != nil {
+		r.MerkleProof = newer.MerkleProof
+	}
+	return r
+}
+
+// firstNonEmpty returns the first of a and b that isn't empty
+func firstNonEmpty(a, b string) string {
+	if a != "" {
+		return a
+	}
+	return b
+}
+
+// ReceiptStore keeps receipts and finds them by data hash, computed hash or
+// timeuuid
+// Lookups that find nothing return ErrReceiptNotFound; hashes and timeuuids
+// are matched case-insensitively. Every method returns ctx.Err() once ctx is
+// done.
+type ReceiptStore interface {
+	// Put stores r, merging it into the receipt with the same ComputedHash if
+	// there is one. ComputedHash, TimeUUID and the other summary fields are
+	// taken from Proof, Record and MerkleProof when empty. A merge that
+	// changes TimeUUID drops the old one from the index.
+	Put(ctx context.Context, r Receipt) error
+
+	// ByComputedHash returns the receipt for a Kayros record hash
+	ByComputedHash(ctx context.Context, computedHash string) (*Receipt, error)
+
+	// ByTimeUUID returns the receipt for a Kayros record timeuuid
+	ByTimeUUID(ctx context.Context, timeUUID string) (*Receipt, error)
+
+	// ByDataHash returns every receipt for a proved hash, oldest first, since
+	// the same hash can be proved more than once
+	ByDataHash(ctx context.Context, dataHash string) ([]Receipt, error)
+}
+
+// receiptLine locates the latest version of a receipt in a FileReceiptStore
+type receiptLine struct {
+	offset   int64
+	length   int
+	timeUUID string // indexed in byUUID, dropped when a merge replaces it
+}
+
+// FileReceiptStore is a ReceiptStore kept in an append-only JSON Lines file
+// Each Put appends the merged receipt as one line and syncs the file; the
+// latest line for a computed hash wins. Only the indexes are held in memory.
+// A file must not be opened by more than one FileReceiptStore at a time.
+type FileReceiptStore struct {
+	mu     sync.RWMutex
+	file   *os.File
+	size   int64
+	lines  map[string]receiptLine // by computed hash
+	byData map[string][]string    // data hash to computed hashes, oldest first
+	byUUID map[string]string      // timeuuid to computed hash
+}
+
+var _ ReceiptStore = (*FileReceiptStore)(nil)
+
+// OpenReceiptStore opens the receipt file at path, creating it if needed, and
+// indexes the receipts already in it
+// A final line left incomplete by a crash is discarded.
+func OpenReceiptStore(path string) (*FileReceiptStore, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("open receipt store: %w", err)
+	}
+
+	s := &FileReceiptStore{
+		file:   file,
+		lines:  make(map[string]receiptLine),
+		byData: make(map[string][]string),
+		byUUID: make(map[string]string),
+	}
+	if err := s.load(); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("open receipt store %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// load indexes every complete line and truncates anything after the last one
+func (s *FileReceiptStore) load() error {
+	reader := bufio.NewReader(s.file)
+	var offset int64
+	for lineNo := 1; ; lineNo++ {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		var r Receipt
+		if err := json.Unmarshal(line, &r); err != nil {
+			return fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		s.index(r, receiptLine{offset: offset, length: len(line), timeUUID: r.TimeUUID})
+		offset += int64(len(line))
+	}
+
+	if err := s.file.Truncate(offset); err != nil {
+		return err
+	}
+	s.size = offset
+	return nil
+}
+
+// index records that the latest version of r is at line
+func (s *FileReceiptStore) index(r Receipt, line receiptLine) {
+	if r.DataHash != "" && !slices.Contains(s.byData[r.DataHash], r.ComputedHash) {
+		s.byData[r.DataHash] = append(s.byData[r.DataHash], r.ComputedHash)
+	}
+	if old, ok := s.lines[r.ComputedHash]; ok && old.timeUUID != r.TimeUUID && s.byUUID[old.timeUUID] == r.ComputedHash {
+		delete(s.byUUID, old.timeUUID)
+	}
+	s.lines[r.ComputedHash] = line
+	if r.TimeUUID != "" {
+		s.byUUID[r.TimeUUID] = r.ComputedHash
+	}
+}
+
+// Put implements ReceiptStore
+func (s *FileReceiptStore) Put(ctx context.Context, r Receipt) error {
+	r.fill()
+	if _, err := hex.DecodeString(r.ComputedHash); err != nil || r.ComputedHash == "" {
+		return fmt.Errorf("%w: receipt computed hash must be hex, got %q", ErrInvalidHash, r.ComputedHash)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return os.ErrClosed
+	}
+
+	if line, ok := s.lines[r.ComputedHash]; ok {
+		existing, err := s.read(line)
+		if err != nil {
+			return err
+		}
+		if existing.DataHash != "" && r.DataHash != "" && existing.DataHash != r.DataHash {
+			return fmt.Errorf("receipt for computed hash %s has data hash %s, not %s", r.ComputedHash, existing.DataHash, r.DataHash)
+		}
+		r = existing.merge(r)
+	} else if r.StoredAt.IsZero() {
+		r.StoredAt = time.Now().UTC()
+	}
+
+	raw, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("encode receipt: %w", err)
+	}
+	raw = append(raw, '\n')
+	if _, err := s.file.WriteAt(raw, s.size); err != nil {
+		return fmt.Errorf("write receipt: %w", err)
+	}
+	if err := s.file.Sync(); err != nil {
+		return fmt.Errorf("write receipt: %w", err)
+	}
+
+	s.index(r, receiptLine{offset: s.size, length: len(raw), timeUUID: r.TimeUUID})
+	s.size += int64(len(raw))
+	return nil
+}
+
+// ByComputedHash implements ReceiptStore
+func (s *FileReceiptStore) ByComputedHash(ctx context.Context, computedHash string) (*Receipt, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.get(strings.ToLower(computedHash))
+}
+
+// ByTimeUUID implements ReceiptStore
+// The timeuuid may be given with or without dashes.
+func (s *FileReceiptStore) ByTimeUUID(ctx context.Context, timeUUID string) (*Receipt, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	computedHash, ok := s.byUUID[strings.ToLower(strings.ReplaceAll(timeUUID, "-", ""))]
+	if !ok {
+		return nil, ErrReceiptNotFound
+	}
+	return s.get(computedHash)
+}
+
+// ByDataHash implements ReceiptStore
+func (s *FileReceiptStore) ByDataHash(ctx context.Context, dataHash string) ([]Receipt, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	computedHashes := s.byData[strings.ToLower(dataHash)]
+	if len(computedHashes) == 0 {
+		return nil, ErrReceiptNotFound
+	}
+
+	receipts := make([]Receipt, 0, len(computedHashes))
+	for _, computedHash := range computedHashes {
+		r, err := s.get(computedHash)
+		if err != nil {
+			return nil, err
+		}
+		receipts = append(receipts, *r)
+	}
+	return receipts, nil
+}
+
+// Len returns the number of receipts
+func (s *FileReceiptStore) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.lines)
+}
+
+// Close closes the file
+func (s *FileReceiptStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+// get returns the receipt for a lowercase computed hash
+func (s *FileReceiptStore) get(computedHash string) (*Receipt, error) {
+	if s.file == nil {
+		return nil, os.ErrClosed
+	}
+	line, ok := s.lines[computedHash]
+	if !ok {
+		return nil, ErrReceiptNotFound
+	}
+	r, err := s.read(line)
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// read decodes the receipt at line
+func (s *FileReceiptStore) read(line receiptLine) (Receipt, error) {
+	raw := make([]byte, line.length)
+	if _, err := s.file.ReadAt(raw, line.offset); err != nil {
+		return Receipt{}, fmt.Errorf("read receipt: %w", err)
+	}
+	var r Receipt
+	if err := json.Unmarshal(bytes.TrimSpace(raw), &r); err != nil {
+		return Receipt{}, fmt.Errorf("read receipt: %w", err)
+	}
+	return r, nil
+}
@@ -0,0 +1,187 @@
+package provable
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func openTestReceiptStore(t *testing.T, path string) *FileReceiptStore {
+	t.Helper()
+	s, err := OpenReceiptStore(path)
+	if err != nil {
+		t.Fatalf("OpenReceiptStore() error = %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestFileReceiptStore(t *testing.T) {
+	ctx := context.Background()
+	dataHash := Keccak256Str("data")
+	computed := Keccak256Str("computed")
+	uuid := "0123456789ab11ef80000370726f7665"
+	proof := &ProveSingleHashResponse{Data: ProveSingleHashResponseData{ComputedHashHex: computed, TimeuuidHex: uuid}}
+
+	t.Run("find receipts by every index", func(t *testing.T) {
+		s := openTestReceiptStore(t, filepath.Join(t.TempDir(), "receipts.jsonl"))
+		if err := s.Put(ctx, Receipt{DataHash: strings.ToUpper(dataHash), Proof: proof}); err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+
+		if r, err := s.ByComputedHash(ctx, strings.ToUpper(computed)); err != nil || r.DataHash != dataHash || r.StoredAt.IsZero() {
+			t.Errorf("ByComputedHash() = %+v, %v, want the receipt", r, err)
+		}
+		if r, err := s.ByTimeUUID(ctx, "01234567-89ab-11ef-8000-0370726f7665"); err != nil || r.ComputedHash != computed {
+			t.Errorf("ByTimeUUID() = %+v, %v, want the receipt", r, err)
+		}
+		if rs, err := s.ByDataHash(ctx, dataHash); err != nil || len(rs) != 1 || rs[0].Proof.Data.ComputedHashHex != computed {
+			t.Errorf("ByDataHash() = %+v, %v, want the receipt", rs, err)
+		}
+		if _, err := s.ByDataHash(ctx, computed); !errors.Is(err, ErrReceiptNotFound) {
+			t.Errorf("ByDataHash() error = %v, want ErrReceiptNotFound", err)
+		}
+	})
+
+	t.Run("merge receipts for the same record", func(t *testing.T) {
+		s := openTestReceiptStore(t, filepath.Join(t.TempDir(), "receipts.jsonl"))
+		s.Put(ctx, Receipt{Proof: proof})
+		first, _ := s.ByComputedHash(ctx, computed)
+		record := &GetRecordResponse{Data: GetRecordResponseData{DataItemHex: dataHash, Timestamp: "2024-01-01T00:00:00Z"}}
+		if err := s.Put(ctx, Receipt{ComputedHash: computed, Record: record}); err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+
+		r, err := s.ByComputedHash(ctx, computed)
+		if err != nil || r.Proof == nil || r.Record == nil || r.Timestamp != "2024-01-01T00:00:00Z" {
+			t.Errorf("ByComputedHash() = %+v, %v, want proof and record merged", r, err)
+		}
+		if !r.StoredAt.Equal(first.StoredAt) || s.Len() != 1 {
+			t.Errorf("StoredAt = %v, Len() = %d, want %v and 1", r.StoredAt, s.Len(), first.StoredAt)
+		}
+		if rs, _ := s.ByDataHash(ctx, dataHash); len(rs) != 1 {
+			t.Errorf("ByDataHash() = %d receipts, want 1 once the data hash is known", len(rs))
+		}
+		if err := s.Put(ctx, Receipt{ComputedHash: computed, DataHash: Keccak256Str("other")}); err == nil {
+			t.Error("Put() error = nil, want error for a different data hash")
+		}
+	})
+
+	t.Run("drop the old timeuuid when a merge replaces it", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "receipts.jsonl")
+		s := openTestReceiptStore(t, path)
+		s.Put(ctx, Receipt{Proof: proof})
+		newer := "fedcba9876543210fedcba9876543210"
+		if err := s.Put(ctx, Receipt{ComputedHash: computed, TimeUUID: newer}); err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+
+		check := func(s *FileReceiptStore) {
+			t.Helper()
+			if _, err := s.ByTimeUUID(ctx, uuid); !errors.Is(err, ErrReceiptNotFound) {
+				t.Errorf("ByTimeUUID(old) error = %v, want ErrReceiptNotFound", err)
+			}
+			if r, err := s.ByTimeUUID(ctx, newer); err != nil || r.ComputedHash != computed {
+				t.Errorf("ByTimeUUID(new) = %+v, %v, want the receipt", r, err)
+			}
+		}
+		check(s)
+		s.Close()
+		check(openTestReceiptStore(t, path))
+	})
+
+	t.Run("stop when the context is done", func(t *testing.T) {
+		s := openTestReceiptStore(t, filepath.Join(t.TempDir(), "receipts.jsonl"))
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		if err := s.Put(cancelled, Receipt{Proof: proof}); !errors.Is(err, context.Canceled) {
+			t.Errorf("Put() error = %v, want context.Canceled", err)
+		}
+		if s.Len() != 0 {
+			t.Errorf("Len() = %d, want nothing stored", s.Len())
+		}
+		s.Put(ctx, Receipt{DataHash: dataHash, Proof: proof})
+		if _, err := s.ByComputedHash(cancelled, computed); !errors.Is(err, context.Canceled) {
+			t.Errorf("ByComputedHash() error = %v, want context.Canceled", err)
+		}
+		if _, err := s.ByTimeUUID(cancelled, uuid); !errors.Is(err, context.Canceled) {
+			t.Errorf("ByTimeUUID() error = %v, want context.Canceled", err)
+		}
+		if _, err := s.ByDataHash(cancelled, dataHash); !errors.Is(err, context.Canceled) {
+			t.Errorf("ByDataHash() error = %v, want context.Canceled", err)
+		}
+	})
+
+	t.Run("reject receipts without a computed hash", func(t *testing.T) {
+		s := openTestReceiptStore(t, filepath.Join(t.TempDir(), "receipts.jsonl"))
+		if err := s.Put(ctx, Receipt{DataHash: dataHash}); !errors.Is(err, ErrInvalidHash) {
+			t.Errorf("Put() error = %v, want ErrInvalidHash", err)
+		}
+	})
+
+	t.Run("survive reopening", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "receipts.jsonl")
+		s := openTestReceiptStore(t, path)
+		second := Keccak256Str("computed again")
+		s.Put(ctx, Receipt{DataHash: dataHash, Proof: proof})
+		s.Put(ctx, Receipt{DataHash: dataHash, ComputedHash: second})
+		s.Close()
+
+		// Simulate a crash in the middle of a write
+		f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+		f.WriteString(`{"computedHash":"`)
+		f.Close()
+
+		s = openTestReceiptStore(t, path)
+		rs, err := s.ByDataHash(ctx, dataHash)
+		if err != nil || len(rs) != 2 || rs[0].ComputedHash != computed || rs[1].ComputedHash != second {
+			t.Fatalf("ByDataHash() = %+v, %v, want both receipts, oldest first", rs, err)
+		}
+		if err := s.Put(ctx, Receipt{ComputedHash: Keccak256Str("third")}); err != nil {
+			t.Fatalf("Put() error = %v after recovering", err)
+		}
+		s.Close()
+		if s = openTestReceiptStore(t, path); s.Len() != 3 {
+			t.Errorf("Len() = %d, want 3", s.Len())
+		}
+	})
+
+	t.Run("reject corrupt files", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "receipts.jsonl")
+		os.WriteFile(path, []byte("not json\n"), 0o600)
+		if _, err := OpenReceiptStore(path); err == nil {
+			t.Error("OpenReceiptStore() error = nil, want error")
+		}
+	})
+}
+
+func TestReceiptFromEnvelope(t *testing.T) {
+	ctx := context.Background()
+	c, _ := newEvidenceTestClient(t)
+	envelope := enrichedTestEnvelope(t, c, map[string]string{"order": "42"})
+
+	r, err := ReceiptFromEnvelope(envelope)
+	if err != nil {
+		t.Fatalf("ReceiptFromEnvelope() error = %v", err)
+	}
+	if r.DataHash != envelope.Kayros.Hash || r.ComputedHash != envelope.Kayros.Evidence.MerkleProof.TargetHashHex {
+		t.Errorf("ReceiptFromEnvelope() = %+v, want the envelope's hashes", r)
+	}
+
+	s := openTestReceiptStore(t, filepath.Join(t.TempDir(), "receipts.jsonl"))
+	if err := s.Put(ctx, r); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	stored, _ := s.ByDataHash(ctx, envelope.Kayros.Hash)
+	envelope.Kayros.Evidence = stored[0].Evidence()
+	if result := c.VerifyWithOptions(ctx, envelope, anchoredOffline(envelope)); !result.Valid {
+		t.Errorf("VerifyWithOptions() = %+v, want valid from the stored evidence", result)
+	}
+
+	if _, err := ReceiptFromEnvelope(&KayrosEnvelope{}); err == nil {
+		t.Error("ReceiptFromEnvelope() error = nil, want error without a timestamp")
+	}
+}
@@ -0,0 +1,117 @@
+package provable
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/zeebo/blake3"
+	"github.com/zeebo/xxh3"
+)
+
+// Hash types Kayros uses for record hashes
+const (
+	RecordHashBLAKE3 = "blake3"
+	RecordHashXXH3   = "xxh3"
+)
+
+// VerifyHashLocal computes a record hash like VerifyHash, without asking
+// Kayros: the hash input is prev_hash || data_type || data_item || uuid,
+// each decoded from hex, hashed with request.HashType (default blake3)
+//
+// data_type and data_item must be 32 bytes and uuid 16. prev_hash is empty
+// for the first record of a chain, else the previous record's hash: 32
+// bytes, or 8 for xxh3. Lightnet doesn't publish this layout:
+// TestRecordHashFixtures checks it against VerifyHash and ComputeHashFromHex
+// responses captured in testdata/lightnet/record_hash, and fails until they
+// are captured.
+func VerifyHashLocal(request HashVerifyRequest) (*HashVerifyResult, error) {
+	var input []byte
+	for _, field := range []struct {
+		name    string
+		value   string
+		lengths []int
+		err     error
+	}{
+		{"prev_hash", request.PrevHash, []int{0, 8, 32}, ErrInvalidHash},
+		{"data_type", request.DataType, []int{32}, ErrInvalidDataType},
+		{"data_item", request.DataItem, []int{32}, ErrInvalidHash},
+		{"uuid", request.UUID, []int{16}, ErrInvalidHash},
+	} {
+		b, err := hex.DecodeString(field.value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s must be hex: %v", field.err, field.name, err)
+		}
+		if !slices.Contains(field.lengths, len(b)) {
+			return nil, fmt.Errorf("%w: %s must be %s bytes, got %d", field.err, field.name, joinLengths(field.lengths), len(b))
+		}
+		input = append(input, b...)
+	}
+	return hashRecordInput(input, request.HashType)
+}
+
+// joinLengths formats allowed byte lengths for an error message, e.g. "0, 8
+// or 32"
+func joinLengths(lengths []int) string {
+	s := fmt.Sprint(lengths[len(lengths)-1])
+	if len(lengths) == 1 {
+		return s
+	}
+	parts := make([]string, len(lengths)-1)
+	for i, n := range lengths[:len(lengths)-1] {
+		parts[i] = fmt.Sprint(n)
+	}
+	return strings.Join(parts, ", ") + " or " + s
+}
+
+// ComputeHashFromHexLocal hashes a hash input like ComputeHashFromHex,
+// without asking Kayros
+func ComputeHashFromHexLocal(request ComputeHashRequest) (*HashVerifyResult, error) {
+	input, err := hex.DecodeString(request.HashInputHex)
+	if err != nil {
+		return nil, fmt.Errorf("hash_input_hex must be hex: %w", err)
+	}
+	return hashRecordInput(input, request.HashType)
+}
+
+// VerifyRecordLocal recomputes record's hash from its prev hash, data type,
+// data item and UUID, and reports whether it matches HashItemHex
+func VerifyRecordLocal(record DatabaseRecord) (bool, *HashVerifyResult, error) {
+	result, err := VerifyHashLocal(RecordHashRequest(record))
+	if err != nil {
+		return false, nil, err
+	}
+	return strings.EqualFold(result.ComputedHash, record.HashItemHex), result, nil
+}
+
+// RecordHashRequest builds the HashVerifyRequest that recomputes record's hash
+func RecordHashRequest(record DatabaseRecord) HashVerifyRequest {
+	return HashVerifyRequest{
+		PrevHash: record.PrevHashHex,
+		DataType: record.DataType,
+		DataItem: record.DataItemHex,
+		UUID:     record.UUIDHex,
+		HashType: record.HashType,
+	}
+}
+
+// hashRecordInput hashes input with BLAKE3 (32 bytes) or XXH3 (64-bit,
+// big-endian)
+func hashRecordInput(input []byte, hashType string) (*HashVerifyResult, error) {
+	var sum []byte
+	switch strings.ToLower(hashType) {
+	case "", RecordHashBLAKE3:
+		h := blake3.Sum256(input)
+		sum = h[:]
+	case RecordHashXXH3:
+		sum = binary.BigEndian.AppendUint64(nil, xxh3.Hash(input))
+	default:
+		return nil, fmt.Errorf("%w: %q (want %s or %s)", ErrUnsupportedHashAlgorithm, hashType, RecordHashBLAKE3, RecordHashXXH3)
+	}
+	return &HashVerifyResult{
+		ComputedHash: hex.EncodeToString(sum),
+		HashInputHex: hex.EncodeToString(input),
+	}, nil
+}
@@ -0,0 +1,187 @@
+package provable
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestComputeHashFromHexLocal(t *testing.T) {
+	// The digests of empty input published with BLAKE3 and XXH3
+	tests := []struct {
+		hashType string
+		want     string
+	}{
+		{RecordHashBLAKE3, "af1349b9f5f9a1a6a0404dea36dcc9499bcb25c9adc112b7cc9a93cae41f3262"},
+		{"", "af1349b9f5f9a1a6a0404dea36dcc9499bcb25c9adc112b7cc9a93cae41f3262"},
+		{RecordHashXXH3, "2d06800538d394c2"},
+	}
+	for _, tt := range tests {
+		t.Run("hash empty input with "+tt.hashType, func(t *testing.T) {
+			result, err := ComputeHashFromHexLocal(ComputeHashRequest{HashType: tt.hashType})
+			if err != nil {
+				t.Fatalf("ComputeHashFromHexLocal() error = %v", err)
+			}
+			if result.ComputedHash != tt.want {
+				t.Errorf("ComputedHash = %v, want %v", result.ComputedHash, tt.want)
+			}
+		})
+	}
+
+	t.Run("reject unknown hash type", func(t *testing.T) {
+		if _, err := ComputeHashFromHexLocal(ComputeHashRequest{HashType: "md5"}); !errors.Is(err, ErrUnsupportedHashAlgorithm) {
+			t.Errorf("ComputeHashFromHexLocal() error = %v, want ErrUnsupportedHashAlgorithm", err)
+		}
+	})
+
+	t.Run("reject invalid hex", func(t *testing.T) {
+		if _, err := ComputeHashFromHexLocal(ComputeHashRequest{HashInputHex: "zz"}); err == nil {
+			t.Error("ComputeHashFromHexLocal() error = nil, want error")
+		}
+	})
+}
+
+func TestVerifyHashLocal(t *testing.T) {
+	request := HashVerifyRequest{
+		PrevHash: strings.Repeat("01", 32),
+		DataType: strings.Repeat("02", 32),
+		DataItem: strings.Repeat("03", 32),
+		UUID:     strings.Repeat("04", 16),
+		HashType: RecordHashBLAKE3,
+	}
+
+	t.Run("concatenate fields in order", func(t *testing.T) {
+		result, err := VerifyHashLocal(request)
+		if err != nil {
+			t.Fatalf("VerifyHashLocal() error = %v", err)
+		}
+		wantInput := request.PrevHash + request.DataType + request.DataItem + request.UUID
+		if result.HashInputHex != wantInput {
+			t.Errorf("HashInputHex = %v, want %v", result.HashInputHex, wantInput)
+		}
+		want, _ := ComputeHashFromHexLocal(ComputeHashRequest{HashInputHex: wantInput, HashType: RecordHashBLAKE3})
+		if result.ComputedHash != want.ComputedHash {
+			t.Errorf("ComputedHash = %v, want %v", result.ComputedHash, want.ComputedHash)
+		}
+	})
+
+	t.Run("name the invalid field", func(t *testing.T) {
+		bad := request
+		bad.UUID = "not hex"
+		if _, err := VerifyHashLocal(bad); err == nil || !strings.Contains(err.Error(), "uuid") {
+			t.Errorf("VerifyHashLocal() error = %v, want uuid error", err)
+		}
+	})
+
+	t.Run("check field lengths", func(t *testing.T) {
+		for _, prev := range []string{"", strings.Repeat("01", 8)} {
+			ok := request
+			ok.PrevHash = prev
+			if _, err := VerifyHashLocal(ok); err != nil {
+				t.Errorf("VerifyHashLocal() with prev_hash %q error = %v", prev, err)
+			}
+		}
+
+		tests := []struct {
+			name   string
+			modify func(*HashVerifyRequest)
+			want   error
+		}{
+			{"short data item", func(r *HashVerifyRequest) { r.DataItem = r.DataItem[2:] }, ErrInvalidHash},
+			{"odd-length data item", func(r *HashVerifyRequest) { r.DataItem = r.DataItem[1:] }, ErrInvalidHash},
+			{"short data type", func(r *HashVerifyRequest) { r.DataType = r.DataType[2:] }, ErrInvalidDataType},
+			{"long uuid", func(r *HashVerifyRequest) { r.UUID += "00" }, ErrInvalidHash},
+			{"truncated prev hash", func(r *HashVerifyRequest) { r.PrevHash = r.PrevHash[:32] }, ErrInvalidHash},
+		}
+		for _, tt := range tests {
+			bad := request
+			tt.modify(&bad)
+			if _, err := VerifyHashLocal(bad); !errors.Is(err, tt.want) {
+				t.Errorf("VerifyHashLocal() with %s error = %v, want %v", tt.name, err, tt.want)
+			}
+		}
+	})
+}
+
+func TestVerifyRecordLocal(t *testing.T) {
+	record := testChain(t, 1)[0]
+	if valid, _, err := VerifyRecordLocal(record); err != nil || !valid {
+		t.Errorf("VerifyRecordLocal() = %v, %v, want valid", valid, err)
+	}
+
+	record.DataItemHex = Keccak256Str("tampered")
+	if valid, result, err := VerifyRecordLocal(record); err != nil || valid || result.ComputedHash == record.HashItemHex {
+		t.Errorf("VerifyRecordLocal() of tampered record = %v, %v, want invalid", valid, err)
+	}
+}
+
+func TestChainAuditorRecomputeHashes(t *testing.T) {
+	a := (&Client{}).NewChainAuditor(WithAuditRecomputeHashes())
+
+	t.Run("accept intact records", func(t *testing.T) {
+		report := a.AuditRecords(testChain(t, 4))
+		if !report.Valid || report.HashesChecked != 4 {
+			t.Errorf("AuditRecords() = %+v, want valid with 4 hashes", report)
+		}
+	})
+
+	t.Run("report a tampered record", func(t *testing.T) {
+		records := testChain(t, 4)
+		records[2].DataItemHex = Keccak256Str("tampered")
+		report := a.AuditRecords(records)
+		if report.FirstBreak == nil || report.FirstBreak.Kind != AuditHashMismatch || report.FirstBreak.Index != 2 {
+			t.Errorf("FirstBreak = %+v, want hash mismatch at 2", report.FirstBreak)
+		}
+
+		// Its links are intact, so checking links alone misses it
+		if report := (&Client{}).NewChainAuditor().AuditRecords(records); !report.Valid || report.HashesChecked != 0 {
+			t.Errorf("AuditRecords() without WithAuditRecomputeHashes = %+v, want valid without hashes", report)
+		}
+	})
+}
+
+// recordHashFixture is a Kayros record-with-prev response with the
+// verify-hash and compute-hash-from-hex responses for it, by hash type, as
+// captured by TestRecordHashConformanceIntegration
+type recordHashFixture struct {
+	Record      DatabaseRecord              `json:"record_with_prev"`
+	VerifyHash  map[string]HashVerifyResult `json:"verify_hash"`
+	ComputeHash map[string]HashVerifyResult `json:"compute_hash"`
+}
+
+// TestRecordHashFixtures checks the local record hash layout against records
+// captured from Kayros in testdata/lightnet/record_hash
+func TestRecordHashFixtures(t *testing.T) {
+	paths := requireFixtures(t, "record_hash")
+	for _, path := range paths {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			var fixture recordHashFixture
+			readFixture(t, path, &fixture)
+			if valid, result, err := VerifyRecordLocal(fixture.Record); err != nil || !valid {
+				t.Errorf("VerifyRecordLocal() = %v, %+v, %v, want the record to rehash to %s", valid, result, err, fixture.Record.HashItemHex)
+			}
+
+			for hashType, want := range fixture.VerifyHash {
+				request := RecordHashRequest(fixture.Record)
+				request.HashType = hashType
+				got, err := VerifyHashLocal(request)
+				if err != nil {
+					t.Fatalf("VerifyHashLocal(%s) error = %v", hashType, err)
+				}
+				if !strings.EqualFold(got.ComputedHash, want.ComputedHash) || !strings.EqualFold(got.HashInputHex, want.HashInputHex) {
+					t.Errorf("VerifyHashLocal(%s) = %+v, want %+v", hashType, got, want)
+				}
+			}
+			for hashType, want := range fixture.ComputeHash {
+				got, err := ComputeHashFromHexLocal(ComputeHashRequest{HashInputHex: fixture.VerifyHash[hashType].HashInputHex, HashType: hashType})
+				if err != nil {
+					t.Fatalf("ComputeHashFromHexLocal(%s) error = %v", hashType, err)
+				}
+				if !strings.EqualFold(got.ComputedHash, want.ComputedHash) {
+					t.Errorf("ComputeHashFromHexLocal(%s) = %s, want %s", hashType, got.ComputedHash, want.ComputedHash)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package provable
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how failed Kayros requests are retried
+//
+// Requests that only read (every GET, and POSTs such as QueryHashes or
+// GenerateMerkleProof) are retried on transport errors and on
+// RetryableStatusCodes. Requests that create a record (ProveSingleHash) are
+// only retried when the server rejected them with 429, unless
+// RetryNonIdempotent is set, since a lost response may hide a record that was
+// already written.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first
+	// Values below 2 disable retries
+	MaxAttempts int
+
+	// InitialBackoff is the delay before the first retry
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps the delay between attempts. A Retry-After header asking
+	// for a longer wait stops the retries instead
+	MaxBackoff time.Duration
+
+	// Multiplier grows the delay after each attempt (values below 1 are treated as 1)
+	Multiplier float64
+
+	// Jitter randomizes each delay by up to this fraction, e.g. 0.2 for ±20%
+	Jitter float64
+
+	// RetryableStatusCodes lists the HTTP statuses worth retrying
+	RetryableStatusCodes []int
+
+	// RetryNonIdempotent allows retrying requests that create records
+	RetryNonIdempotent bool
+}
+
+// DefaultRetryPolicy is used by DefaultClient and by clients built without WithRetryPolicy
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: 500 * time.Millisecond,
+	MaxBackoff:     10 * time.Second,
+	Multiplier:     2,
+	Jitter:         0.2,
+	RetryableStatusCodes: []int{
+		http.StatusTooManyRequests,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout,
+	},
+}
+
+// NoRetry is a RetryPolicy that makes a single attempt
+var NoRetry = RetryPolicy{MaxAttempts: 1}
+
+// WithRetryPolicy sets the retry policy applied to every request
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retryPolicy = policy
+	}
+}
+
+// withStatus returns a copy of the policy that also retries the given statuses
+func (p RetryPolicy) withStatus(codes ...int) RetryPolicy {
+	p.RetryableStatusCodes = append(slices.Clone(p.RetryableStatusCodes), codes...)
+	return p
+}
+
+// shouldRetryStatus reports whether a response with this status may be retried
+func (p RetryPolicy) shouldRetryStatus(statusCode int, idempotent bool) bool {
+	if !slices.Contains(p.RetryableStatusCodes, statusCode) {
+		return false
+	}
+	return idempotent || p.RetryNonIdempotent || statusCode == http.StatusTooManyRequests
+}
+
+// shouldRetryError reports whether a transport error may be retried
+func (p RetryPolicy) shouldRetryError(idempotent bool) bool {
+	return idempotent || p.RetryNonIdempotent
+}
+
+// backoff returns the delay before the given retry (1 for the first retry)
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	multiplier := math.Max(p.Multiplier, 1)
+	delay := float64(p.InitialBackoff) * math.Pow(multiplier, float64(retry-1))
+	if p.MaxBackoff > 0 {
+		delay = math.Min(delay, float64(p.MaxBackoff))
+	}
+	if p.Jitter > 0 {
+		delay *= 1 + p.Jitter*(2*rand.Float64()-1)
+	}
+	return time.Duration(delay)
+}
+
+// retryAfter parses a Retry-After header given either in seconds or as an HTTP date
+func retryAfter(header http.Header, now time.Time) (time.Duration, bool) {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// sleepContext waits for d or until ctx is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,259 @@
+package provable
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fastRetryPolicy retries quickly so tests don't wait on real backoff
+var fastRetryPolicy = RetryPolicy{
+	MaxAttempts:          3,
+	InitialBackoff:       time.Millisecond,
+	MaxBackoff:           10 * time.Millisecond,
+	Multiplier:           2,
+	RetryableStatusCodes: DefaultRetryPolicy.RetryableStatusCodes,
+}
+
+// newRetryTestClient returns a client whose server answers with the given
+// statuses in order, then 200, and a counter of the requests it received
+func newRetryTestClient(t *testing.T, policy RetryPolicy, statuses ...int) (*Client, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(calls.Add(1))
+		if n <= len(statuses) {
+			w.WriteHeader(statuses[n-1])
+			return
+		}
+		w.Write([]byte(`{"success":true,"data":{"computed_hash_hex":"feed","data_item_hex":"feed"}}`))
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := NewClient(WithBaseURL(server.URL), WithRetryPolicy(policy))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	return c, &calls
+}
+
+func TestRetryPolicy(t *testing.T) {
+	t.Run("retry retryable statuses until success", func(t *testing.T) {
+		c, calls := newRetryTestClient(t, fastRetryPolicy, http.StatusBadGateway, http.StatusServiceUnavailable)
+		if _, err := c.GetRecordByHash("feed"); err != nil {
+			t.Fatalf("GetRecordByHash() error = %v", err)
+		}
+		if calls.Load() != 3 {
+			t.Errorf("calls = %d, want 3", calls.Load())
+		}
+	})
+
+	t.Run("stop after max attempts", func(t *testing.T) {
+		c, calls := newRetryTestClient(t, fastRetryPolicy, 503, 503, 503, 503)
+		_, err := c.GetDatabaseStats()
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+			t.Fatalf("GetDatabaseStats() error = %v, want 503 *APIError", err)
+		}
+		if calls.Load() != 3 {
+			t.Errorf("calls = %d, want 3", calls.Load())
+		}
+	})
+
+	t.Run("don't retry other statuses", func(t *testing.T) {
+		c, calls := newRetryTestClient(t, fastRetryPolicy, http.StatusInternalServerError)
+		if _, err := c.GetTables(); err == nil {
+			t.Fatal("GetTables() error = nil, want error")
+		}
+		if calls.Load() != 1 {
+			t.Errorf("calls = %d, want 1", calls.Load())
+		}
+	})
+
+	t.Run("retry read-only POST requests", func(t *testing.T) {
+		c, calls := newRetryTestClient(t, fastRetryPolicy, http.StatusGatewayTimeout)
+		if _, err := c.VerifyHash(HashVerifyRequest{}); err != nil {
+			t.Fatalf("VerifyHash() error = %v", err)
+		}
+		if calls.Load() != 2 {
+			t.Errorf("calls = %d, want 2", calls.Load())
+		}
+	})
+
+	t.Run("don't retry non-idempotent requests on server errors", func(t *testing.T) {
+		c, calls := newRetryTestClient(t, fastRetryPolicy, http.StatusBadGateway)
+		if _, err := c.ProveSingleHash("feed"); err == nil {
+			t.Fatal("ProveSingleHash() error = nil, want error")
+		}
+		if calls.Load() != 1 {
+			t.Errorf("calls = %d, want 1", calls.Load())
+		}
+	})
+
+	t.Run("retry non-idempotent requests when rate limited", func(t *testing.T) {
+		c, calls := newRetryTestClient(t, fastRetryPolicy, http.StatusTooManyRequests)
+		if _, err := c.ProveSingleHash("feed"); err != nil {
+			t.Fatalf("ProveSingleHash() error = %v", err)
+		}
+		if calls.Load() != 2 {
+			t.Errorf("calls = %d, want 2", calls.Load())
+		}
+	})
+
+	t.Run("retry non-idempotent requests when allowed", func(t *testing.T) {
+		policy := fastRetryPolicy
+		policy.RetryNonIdempotent = true
+		c, calls := newRetryTestClient(t, policy, http.StatusBadGateway)
+		if _, err := c.ProveSingleHash("feed"); err != nil {
+			t.Fatalf("ProveSingleHash() error = %v", err)
+		}
+		if calls.Load() != 2 {
+			t.Errorf("calls = %d, want 2", calls.Load())
+		}
+	})
+
+	t.Run("make a single attempt with NoRetry", func(t *testing.T) {
+		c, calls := newRetryTestClient(t, NoRetry, http.StatusServiceUnavailable)
+		if _, err := c.GetTables(); err == nil {
+			t.Fatal("GetTables() error = nil, want error")
+		}
+		if calls.Load() != 1 {
+			t.Errorf("calls = %d, want 1", calls.Load())
+		}
+	})
+
+	t.Run("retry missing record during verify", func(t *testing.T) {
+		c, calls := newRetryTestClient(t, fastRetryPolicy, http.StatusNotFound)
+		result := c.Verify(&KayrosEnvelope{
+			Data: "x",
+			Kayros: KayrosMetadata{
+				Hash: Keccak256Str("x"),
+				Timestamp: &KayrosTimestamp{
+					Response: &ProveSingleHashResponse{Data: ProveSingleHashResponseData{ComputedHashHex: "feed"}},
+				},
+			},
+		})
+		if result.Err != nil {
+			t.Fatalf("Verify() Err = %v, want remote record to be fetched", result.Err)
+		}
+		if calls.Load() != 2 {
+			t.Errorf("calls = %d, want 2", calls.Load())
+		}
+	})
+}
+
+func TestRetryAfter(t *testing.T) {
+	t.Run("honor Retry-After seconds", func(t *testing.T) {
+		var calls atomic.Int32
+		var firstAt, secondAt time.Time
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if calls.Add(1) == 1 {
+				firstAt = time.Now()
+				w.Header().Set("Retry-After", "1")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			secondAt = time.Now()
+			w.Write([]byte(`{"success":true}`))
+		}))
+		defer server.Close()
+
+		policy := fastRetryPolicy
+		policy.MaxBackoff = 5 * time.Second
+		c, err := NewClient(WithBaseURL(server.URL), WithRetryPolicy(policy))
+		if err != nil {
+			t.Fatalf("NewClient() error = %v", err)
+		}
+		if _, err := c.GetTables(); err != nil {
+			t.Fatalf("GetTables() error = %v", err)
+		}
+		if wait := secondAt.Sub(firstAt); wait < 900*time.Millisecond {
+			t.Errorf("retried after %v, want about 1s", wait)
+		}
+	})
+
+	t.Run("give up when Retry-After exceeds max backoff", func(t *testing.T) {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			w.Header().Set("Retry-After", "3600")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+		defer server.Close()
+
+		c, err := NewClient(WithBaseURL(server.URL), WithRetryPolicy(fastRetryPolicy))
+		if err != nil {
+			t.Fatalf("NewClient() error = %v", err)
+		}
+		if _, err := c.GetTables(); !errors.Is(err, ErrRateLimited) {
+			t.Fatalf("GetTables() error = %v, want ErrRateLimited", err)
+		}
+		if calls.Load() != 1 {
+			t.Errorf("calls = %d, want 1", calls.Load())
+		}
+	})
+
+	t.Run("parse HTTP date", func(t *testing.T) {
+		now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		header := http.Header{}
+		header.Set("Retry-After", now.Add(30*time.Second).Format(http.TimeFormat))
+		wait, ok := retryAfter(header, now)
+		if !ok || wait != 30*time.Second {
+			t.Errorf("retryAfter() = %v, %v, want 30s, true", wait, ok)
+		}
+	})
+
+	t.Run("ignore invalid values", func(t *testing.T) {
+		header := http.Header{}
+		header.Set("Retry-After", "soon")
+		if _, ok := retryAfter(header, time.Now()); ok {
+			t.Error("retryAfter() ok = true, want false")
+		}
+	})
+}
+
+func TestRetryBackoff(t *testing.T) {
+	t.Run("grow exponentially up to max backoff", func(t *testing.T) {
+		policy := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second, Multiplier: 2}
+		want := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second}
+		for i, w := range want {
+			if got := policy.backoff(i + 1); got != w {
+				t.Errorf("backoff(%d) = %v, want %v", i+1, got, w)
+			}
+		}
+	})
+
+	t.Run("stay within jitter bounds", func(t *testing.T) {
+		policy := RetryPolicy{InitialBackoff: 100 * time.Millisecond, Multiplier: 2, Jitter: 0.5}
+		for i := 0; i < 100; i++ {
+			got := policy.backoff(1)
+			if got < 50*time.Millisecond || got > 150*time.Millisecond {
+				t.Fatalf("backoff(1) = %v, want within 50ms..150ms", got)
+			}
+		}
+	})
+
+	t.Run("stop waiting when context is cancelled", func(t *testing.T) {
+		policy := RetryPolicy{
+			MaxAttempts:          3,
+			InitialBackoff:       time.Hour,
+			RetryableStatusCodes: []int{http.StatusServiceUnavailable},
+		}
+		c, _ := newRetryTestClient(t, policy, http.StatusServiceUnavailable)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		_, err := c.GetTablesContext(ctx)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("GetTablesContext() error = %v, want context.DeadlineExceeded", err)
+		}
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) {
+			t.Errorf("GetTablesContext() error = %v, want last *APIError to be kept", err)
+		}
+	})
+}
@@ -0,0 +1,207 @@
+package provable
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// streamChunkSize is how much ProveReader hashes between progress reports
+const streamChunkSize = 1 << 20
+
+// ProgressFunc reports how many bytes have been hashed so far out of total,
+// which is -1 when the size is not known in advance
+type ProgressFunc func(hashed, total int64)
+
+// WithProgress sets a function ProveReader and ProveFile call as data is
+// hashed
+func WithProgress(fn ProgressFunc) EnvelopeOption {
+	return func(o *envelopeOptions) {
+		o.progress = fn
+	}
+}
+
+// WithDetachedName sets the content name recorded in a detached envelope
+// (ProveFile defaults to the file's base name)
+func WithDetachedName(name string) EnvelopeOption {
+	return func(o *envelopeOptions) {
+		o.name = name
+	}
+}
+
+// ProveReader hashes r incrementally, proves the hash via Kayros and returns
+// the proof with a detached envelope referencing the content
+// The hash covers r's raw bytes, using the algorithm set by WithHashAlgorithm.
+// Check the content against the envelope with VerifyReader.
+func (c *Client) ProveReader(ctx context.Context, r io.Reader, opts ...EnvelopeOption) (*ProveSingleHashResponse, *KayrosEnvelope, error) {
+	return c.proveReader(ctx, r, -1, applyEnvelopeOptions(opts))
+}
+
+// ProveFile is like ProveReader for the file at path
+func (c *Client) ProveFile(path string, opts ...EnvelopeOption) (*ProveSingleHashResponse, *KayrosEnvelope, error) {
+	return c.ProveFileContext(context.Background(), path, opts...)
+}
+
+// ProveFileContext is like ProveFile but honors ctx cancellation and deadlines
+func (c *Client) ProveFileContext(ctx context.Context, path string, opts ...EnvelopeOption) (*ProveSingleHashResponse, *KayrosEnvelope, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, nil, err
+	}
+	opts = append([]EnvelopeOption{WithDetachedName(filepath.Base(path))}, opts...)
+	return c.proveReader(ctx, f, info.Size(), applyEnvelopeOptions(opts))
+}
+
+func (c *Client) proveReader(ctx context.Context, r io.Reader, total int64, o envelopeOptions) (*ProveSingleHashResponse, *KayrosEnvelope, error) {
+	hash, size, err := hashReader(ctx, r, o.hashAlgorithm, total, o.progress)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	envelope := &KayrosEnvelope{
+		Kayros: KayrosMetadata{
+			Hash:          hash,
+			HashAlgorithm: o.hashAlgorithm,
+			Detached:      &DetachedContent{Name: o.name, Size: size},
+		},
+	}
+	proof, err := c.timestampEnvelope(ctx, envelope, o)
+	if err != nil {
+		return nil, nil, err
+	}
+	return proof, envelope, nil
+}
+
+// VerifyReader verifies a detached envelope against its content read from r
+// Content whose length differs from Detached.Size fails with
+// VerifyErrorHashMismatch, even if it hashes to the envelope hash.
+func (c *Client) VerifyReader(ctx context.Context, envelope *KayrosEnvelope, r io.Reader, opts VerifyOptions) *VerifyResult {
+	if envelope.Kayros.Hash == "" {
+		return &VerifyResult{
+			Valid: false,
+			Code:  VerifyErrorMissingHash,
+			Error: "Missing field: envelope.kayros.hash",
+		}
+	}
+	if envelope.Kayros.Detached == nil {
+		return &VerifyResult{
+			Valid: false,
+			Code:  VerifyErrorMalformedEnvelope,
+			Error: "Missing field: envelope.kayros.detached",
+		}
+	}
+
+	computedHash, size, err := hashReader(ctx, r, envelope.Kayros.HashAlgorithm, envelope.Kayros.Detached.Size, nil)
+	if errors.Is(err, ErrUnsupportedHashAlgorithm) {
+		return &VerifyResult{
+			Valid: false,
+			Code:  VerifyErrorUnsupportedHashAlgorithm,
+			Error: fmt.Sprintf("Unsupported hash algorithm: %q", envelope.Kayros.HashAlgorithm),
+			Err:   err,
+		}
+	}
+	if err != nil {
+		return &VerifyResult{
+			Valid: false,
+			Code:  VerifyErrorSerializationFailed,
+			Error: fmt.Sprintf("Failed to read content: %v", err),
+			Err:   err,
+		}
+	}
+	if size != envelope.Kayros.Detached.Size {
+		return &VerifyResult{
+			Valid: false,
+			Code:  VerifyErrorHashMismatch,
+			Error: fmt.Sprintf("Size mismatch: content is %d bytes, envelope says %d", size, envelope.Kayros.Detached.Size),
+			Details: &VerifyResultDetails{
+				HashMatch:    computedHash == envelope.Kayros.Hash,
+				ComputedHash: computedHash,
+				EnvelopeHash: envelope.Kayros.Hash,
+			},
+		}
+	}
+	return c.verifyComputedHash(ctx, envelope, computedHash, opts)
+}
+
+// VerifyFile is like VerifyReader for the file at path
+func (c *Client) VerifyFile(ctx context.Context, envelope *KayrosEnvelope, path string, opts VerifyOptions) *VerifyResult {
+	f, err := os.Open(path)
+	if err != nil {
+		return &VerifyResult{
+			Valid: false,
+			Code:  VerifyErrorSerializationFailed,
+			Error: fmt.Sprintf("Failed to read content: %v", err),
+			Err:   err,
+		}
+	}
+	defer f.Close()
+	return c.VerifyReader(ctx, envelope, f, opts)
+}
+
+// hashReader hashes r with algorithm, checking ctx and reporting progress
+// after each chunk, and returns the digest as hex with the number of bytes read
+func hashReader(ctx context.Context, r io.Reader, algorithm string, total int64, progress ProgressFunc) (string, int64, error) {
+	newHash, err := LookupStreamingHashAlgorithm(algorithm)
+	if err != nil {
+		return "", 0, err
+	}
+
+	h := newHash()
+	buf := make([]byte, streamChunkSize)
+	var hashed int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return "", hashed, err
+		}
+		n, err := io.ReadFull(r, buf)
+		h.Write(buf[:n])
+		hashed += int64(n)
+		if n > 0 && progress != nil {
+			progress(hashed, total)
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return "", hashed, err
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), hashed, nil
+}
+
+// ProveReader hashes r incrementally and proves the hash using DefaultClient
+func ProveReader(ctx context.Context, r io.Reader, opts ...EnvelopeOption) (*ProveSingleHashResponse, *KayrosEnvelope, error) {
+	return DefaultClient.ProveReader(ctx, r, opts...)
+}
+
+// ProveFile hashes the file at path incrementally and proves the hash using
+// DefaultClient
+func ProveFile(path string, opts ...EnvelopeOption) (*ProveSingleHashResponse, *KayrosEnvelope, error) {
+	return DefaultClient.ProveFile(path, opts...)
+}
+
+// ProveFileContext is like ProveFile but honors ctx cancellation and deadlines
+func ProveFileContext(ctx context.Context, path string, opts ...EnvelopeOption) (*ProveSingleHashResponse, *KayrosEnvelope, error) {
+	return DefaultClient.ProveFileContext(ctx, path, opts...)
+}
+
+// VerifyReader verifies a detached envelope against its content read from r
+// using DefaultClient
+func VerifyReader(ctx context.Context, envelope *KayrosEnvelope, r io.Reader, opts VerifyOptions) *VerifyResult {
+	return DefaultClient.VerifyReader(ctx, envelope, r, opts)
+}
+
+// VerifyFile is like VerifyReader for the file at path
+func VerifyFile(ctx context.Context, envelope *KayrosEnvelope, path string, opts VerifyOptions) *VerifyResult {
+	return DefaultClient.VerifyFile(ctx, envelope, path, opts)
+}
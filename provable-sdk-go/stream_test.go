@@ -0,0 +1,133 @@
+package provable
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// streamTestContent spans several hashing chunks
+var streamTestContent = bytes.Repeat([]byte("provable "), streamChunkSize/3)
+
+func writeStreamTestFile(t *testing.T, content []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "backup.tar")
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		t.Fatalf("os.WriteFile() error = %v", err)
+	}
+	return path
+}
+
+func TestProveFile(t *testing.T) {
+	ctx := context.Background()
+	c, _ := newEvidenceTestClient(t)
+	path := writeStreamTestFile(t, streamTestContent)
+	size := int64(len(streamTestContent))
+
+	var reports [][2]int64
+	proof, envelope, err := c.ProveFile(path, WithProgress(func(hashed, total int64) {
+		reports = append(reports, [2]int64{hashed, total})
+	}))
+	if err != nil {
+		t.Fatalf("ProveFile() error = %v", err)
+	}
+
+	t.Run("reference the file by name, size and hash", func(t *testing.T) {
+		if got, want := envelope.Kayros.Hash, Keccak256(streamTestContent); got != want {
+			t.Errorf("Hash = %v, want %v", got, want)
+		}
+		if got, want := *envelope.Kayros.Detached, (DetachedContent{Name: "backup.tar", Size: size}); got != want {
+			t.Errorf("Detached = %+v, want %+v", got, want)
+		}
+		if envelope.Data != nil || envelope.Kayros.Timestamp.Response != proof || proof.Data.ComputedHashHex == "" {
+			t.Errorf("envelope = %+v, want timestamped with %+v and no data", envelope, proof)
+		}
+	})
+
+	t.Run("report progress per chunk", func(t *testing.T) {
+		if len(reports) != 3 {
+			t.Fatalf("progress reports = %d, want 3", len(reports))
+		}
+		if last := reports[len(reports)-1]; last != [2]int64{size, size} {
+			t.Errorf("last progress = %v, want [%d %d]", last, size, size)
+		}
+	})
+
+	t.Run("verify the file against the envelope", func(t *testing.T) {
+		if result := c.VerifyFile(ctx, envelope, path, VerifyOptions{}); !result.Valid || !result.Details.RemoteMatch {
+			t.Errorf("VerifyFile() = %+v, want valid", result)
+		}
+
+		tampered := writeStreamTestFile(t, append(bytes.Clone(streamTestContent), '!'))
+		if result := c.VerifyFile(ctx, envelope, tampered, VerifyOptions{}); result.Valid || result.Code != VerifyErrorHashMismatch {
+			t.Errorf("VerifyFile() = %+v, want hash mismatch", result)
+		}
+
+		resized := *envelope
+		resized.Kayros.Detached = &DetachedContent{Name: "backup.tar", Size: size + 1}
+		if result := c.VerifyFile(ctx, &resized, path, VerifyOptions{}); result.Valid || result.Code != VerifyErrorHashMismatch || !result.Details.HashMatch || !strings.Contains(result.Error, "Size mismatch") {
+			t.Errorf("VerifyFile() = %+v, want size mismatch", result)
+		}
+
+		if result := c.VerifyFile(ctx, envelope, filepath.Join(t.TempDir(), "missing"), VerifyOptions{}); result.Valid || !errors.Is(result.Err, os.ErrNotExist) {
+			t.Errorf("VerifyFile() = %+v, want os.ErrNotExist", result)
+		}
+	})
+
+	t.Run("refuse to verify detached envelopes without content", func(t *testing.T) {
+		if result := c.Verify(envelope); result.Valid || result.Code != VerifyErrorMalformedEnvelope {
+			t.Errorf("Verify() = %+v, want malformed envelope", result)
+		}
+		attached, _ := NewEnvelope("attached")
+		if result := c.VerifyReader(ctx, attached, strings.NewReader("attached"), VerifyOptions{}); result.Valid || result.Code != VerifyErrorMalformedEnvelope {
+			t.Errorf("VerifyReader() = %+v, want malformed envelope", result)
+		}
+	})
+}
+
+func TestProveReader(t *testing.T) {
+	ctx := context.Background()
+	c, requests := newEvidenceTestClient(t)
+
+	t.Run("hash with the chosen algorithm", func(t *testing.T) {
+		var lastTotal int64
+		_, envelope, err := c.ProveReader(ctx, bytes.NewReader(streamTestContent),
+			WithHashAlgorithm(HashAlgorithmSHA256),
+			WithDetachedName("stdin"),
+			WithProgress(func(hashed, total int64) { lastTotal = total }))
+		if err != nil {
+			t.Fatalf("ProveReader() error = %v", err)
+		}
+		if got, want := envelope.Kayros.Hash, SHA256(streamTestContent); got != want || envelope.Kayros.HashAlgorithm != HashAlgorithmSHA256 {
+			t.Errorf("Hash = %v (%s), want %v", got, envelope.Kayros.HashAlgorithm, want)
+		}
+		if envelope.Kayros.Detached.Name != "stdin" || lastTotal != -1 {
+			t.Errorf("Detached = %+v, total = %d, want stdin with unknown total", envelope.Kayros.Detached, lastTotal)
+		}
+		if result := c.VerifyReader(ctx, envelope, bytes.NewReader(streamTestContent), VerifyOptions{}); !result.Valid {
+			t.Errorf("VerifyReader() = %+v, want valid", result)
+		}
+	})
+
+	t.Run("stop when ctx is done", func(t *testing.T) {
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		before := requests.Load()
+		if _, _, err := c.ProveReader(cancelled, bytes.NewReader(streamTestContent)); !errors.Is(err, context.Canceled) {
+			t.Errorf("ProveReader() error = %v, want context.Canceled", err)
+		}
+		if n := requests.Load() - before; n != 0 {
+			t.Errorf("requests = %d, want 0", n)
+		}
+	})
+
+	t.Run("reject algorithms that cannot stream", func(t *testing.T) {
+		if _, _, err := c.ProveReader(ctx, strings.NewReader("x"), WithHashAlgorithm("md5")); !errors.Is(err, ErrUnsupportedHashAlgorithm) {
+			t.Errorf("ProveReader() error = %v, want ErrUnsupportedHashAlgorithm", err)
+		}
+	})
+}
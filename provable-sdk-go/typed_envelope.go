@@ -0,0 +1,108 @@
+package provable
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Envelope is a KayrosEnvelope whose data is decoded into T
+//
+// It has the same JSON form as KayrosEnvelope, so archived envelopes can be
+// unmarshalled into either. Unlike KayrosEnvelope, the timestamp response is
+// always a ProveSingleHashResponse after unmarshalling.
+type Envelope[T any] struct {
+	Data   T                `json:"data"`
+	Kayros EnvelopeMetadata `json:"kayros"`
+}
+
+// EnvelopeMetadata is KayrosMetadata with a typed timestamp
+type EnvelopeMetadata struct {
+	Hash             string             `json:"hash,omitempty"`
+	HashAlgorithm    string             `json:"hashAlgorithm,omitempty"`
+	Canonicalization string             `json:"canonicalization,omitempty"`
+	DataType         string             `json:"dataType,omitempty"`
+	Timestamp        *EnvelopeTimestamp `json:"timestamp,omitempty"`
+	Evidence         *KayrosEvidence    `json:"evidence,omitempty"`
+	Detached         *DetachedContent   `json:"detached,omitempty"`
+}
+
+// EnvelopeTimestamp is KayrosTimestamp with a typed response
+type EnvelopeTimestamp struct {
+	Service  string                  `json:"service"`
+	Response ProveSingleHashResponse `json:"response"`
+}
+
+// ToEnvelope converts a legacy envelope into an Envelope[T]
+// Data that is not already a T is decoded into one through JSON. T must
+// round-trip the data unchanged for the typed envelope to verify.
+func ToEnvelope[T any](envelope *KayrosEnvelope) (*Envelope[T], error) {
+	if envelope == nil {
+		return nil, errors.New("nil envelope")
+	}
+
+	typed := &Envelope[T]{
+		Kayros: EnvelopeMetadata{
+			Hash:             envelope.Kayros.Hash,
+			HashAlgorithm:    envelope.Kayros.HashAlgorithm,
+			Canonicalization: envelope.Kayros.Canonicalization,
+			DataType:         envelope.Kayros.DataType,
+			Evidence:         envelope.Kayros.Evidence,
+			Detached:         envelope.Kayros.Detached,
+		},
+	}
+	if err := convertJSON(envelope.Data, &typed.Data); err != nil {
+		return nil, fmt.Errorf("decode envelope data: %w", err)
+	}
+
+	if timestamp := envelope.Kayros.Timestamp; timestamp != nil {
+		typed.Kayros.Timestamp = &EnvelopeTimestamp{Service: timestamp.Service}
+		if err := convertJSON(timestamp.Response, &typed.Kayros.Timestamp.Response); err != nil {
+			return nil, fmt.Errorf("decode timestamp response: %w", err)
+		}
+	}
+	return typed, nil
+}
+
+// Untyped converts the envelope into a legacy KayrosEnvelope, e.g. to pass
+// it to Verify
+func (e *Envelope[T]) Untyped() *KayrosEnvelope {
+	envelope := &KayrosEnvelope{
+		Data: e.Data,
+		Kayros: KayrosMetadata{
+			Hash:             e.Kayros.Hash,
+			HashAlgorithm:    e.Kayros.HashAlgorithm,
+			Canonicalization: e.Kayros.Canonicalization,
+			DataType:         e.Kayros.DataType,
+			Evidence:         e.Kayros.Evidence,
+			Detached:         e.Kayros.Detached,
+		},
+	}
+	if e.Kayros.Timestamp != nil {
+		envelope.Kayros.Timestamp = &KayrosTimestamp{
+			Service:  e.Kayros.Timestamp.Service,
+			Response: e.Kayros.Timestamp.Response,
+		}
+	}
+	return envelope
+}
+
+// convertJSON stores src in dst, decoding it through JSON unless it already
+// has dst's type
+func convertJSON[T any](src interface{}, dst *T) error {
+	switch v := src.(type) {
+	case T:
+		*dst = v
+		return nil
+	case *T:
+		if v != nil {
+			*dst = *v
+			return nil
+		}
+	}
+	raw, err := json.Marshal(src)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, dst)
+}
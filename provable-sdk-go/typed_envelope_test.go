@@ -0,0 +1,84 @@
+package provable
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+type typedEnvelopeOrder struct {
+	ID    string `json:"id"`
+	Total int    `json:"total"`
+}
+
+func TestEnvelope(t *testing.T) {
+	c, _ := newEvidenceTestClient(t)
+	order := typedEnvelopeOrder{ID: "o-1", Total: 42}
+
+	t.Run("unmarshal archived envelopes with typed fields", func(t *testing.T) {
+		sealed, err := c.Seal(order)
+		if err != nil {
+			t.Fatalf("Seal() error = %v", err)
+		}
+		raw, _ := json.Marshal(sealed)
+
+		var typed Envelope[typedEnvelopeOrder]
+		if err := json.Unmarshal(raw, &typed); err != nil {
+			t.Fatalf("json.Unmarshal() error = %v", err)
+		}
+		if typed.Data != order {
+			t.Errorf("Data = %+v, want %+v", typed.Data, order)
+		}
+		if typed.Kayros.Timestamp == nil || typed.Kayros.Timestamp.Response.Data.ComputedHashHex == "" {
+			t.Errorf("Timestamp = %+v, want typed response", typed.Kayros.Timestamp)
+		}
+		if result := c.Verify(typed.Untyped()); !result.Valid {
+			t.Errorf("Verify(Untyped()) = %+v, want valid", result)
+		}
+	})
+
+	t.Run("convert legacy envelopes", func(t *testing.T) {
+		sealed, _ := c.Seal(order)
+		raw, _ := json.Marshal(sealed)
+		var legacy KayrosEnvelope
+		json.Unmarshal(raw, &legacy)
+
+		typed, err := ToEnvelope[typedEnvelopeOrder](&legacy)
+		if err != nil {
+			t.Fatalf("ToEnvelope() error = %v", err)
+		}
+		if typed.Data != order || typed.Kayros.Hash != sealed.Kayros.Hash || typed.Kayros.DataType != sealed.Kayros.DataType {
+			t.Errorf("ToEnvelope() = %+v, want fields of %+v", typed, sealed)
+		}
+		if got, want := typed.Kayros.Timestamp.Response.Data.ComputedHashHex, sealed.Kayros.Timestamp.Response.(*ProveSingleHashResponse).Data.ComputedHashHex; got != want {
+			t.Errorf("ComputedHashHex = %q, want %q", got, want)
+		}
+		if result := c.Verify(typed.Untyped()); !result.Valid {
+			t.Errorf("Verify(Untyped()) = %+v, want valid", result)
+		}
+	})
+
+	t.Run("keep data already of the right type", func(t *testing.T) {
+		envelope, _ := NewEnvelope(&order)
+		typed, err := ToEnvelope[typedEnvelopeOrder](envelope)
+		if err != nil || typed.Data != order || typed.Kayros.Timestamp != nil {
+			t.Errorf("ToEnvelope() = %+v, %v, want %+v without timestamp", typed, err, order)
+		}
+	})
+
+	t.Run("reject data and responses of the wrong shape", func(t *testing.T) {
+		envelope, _ := NewEnvelope("not an order")
+		if _, err := ToEnvelope[typedEnvelopeOrder](envelope); err == nil {
+			t.Error("ToEnvelope() error = nil, want data error")
+		}
+
+		envelope, _ = NewEnvelope(order)
+		envelope.Kayros.Timestamp = &KayrosTimestamp{Response: "oops"}
+		if _, err := ToEnvelope[typedEnvelopeOrder](envelope); err == nil {
+			t.Error("ToEnvelope() error = nil, want response error")
+		}
+
+		if _, err := ToEnvelope[string](nil); err == nil {
+			t.Error("ToEnvelope(nil) error = nil, want error")
+		}
+	})
+}
@@ -8,9 +8,36 @@ type KayrosTimestamp struct {
 
 // KayrosMetadata represents metadata attached to Kayros envelopes
 type KayrosMetadata struct {
-	Hash          string           `json:"hash,omitempty"`
-	HashAlgorithm string           `json:"hashAlgorithm,omitempty"`
-	Timestamp     *KayrosTimestamp `json:"timestamp,omitempty"`
+	Hash          string `json:"hash,omitempty"`
+	HashAlgorithm string `json:"hashAlgorithm,omitempty"`
+	// Canonicalization is how non-string data was serialized before hashing:
+	// CanonicalizationJCS, or empty for encoding/json output
+	Canonicalization string `json:"canonicalization,omitempty"`
+	// DataType is the Kayros data type the hash was proved under
+	DataType  string           `json:"dataType,omitempty"`
+	Timestamp *KayrosTimestamp `json:"timestamp,omitempty"`
+	// Evidence lets the envelope be verified offline (see EnrichEnvelope)
+	Evidence *KayrosEvidence `json:"evidence,omitempty"`
+	// Detached describes content kept outside the envelope, whose raw bytes
+	// Hash covers (see ProveReader)
+	Detached *DetachedContent `json:"detached,omitempty"`
+}
+
+// DetachedContent references the content of a detached envelope
+type DetachedContent struct {
+	Name string `json:"name,omitempty"`
+	Size int64  `json:"size"`
+}
+
+// KayrosEvidence holds the Kayros responses needed to verify an envelope
+// without network access
+type KayrosEvidence struct {
+	Record *GetRecordResponse `json:"record,omitempty"`
+	// ChainRecord is the full database record, with its prev hash, UUID and
+	// hash type. Offline verification recomputes the record hash from it, so
+	// the data item is tied to computed_hash_hex.
+	ChainRecord *DatabaseRecord `json:"chainRecord,omitempty"`
+	MerkleProof *MerkleProof    `json:"merkleProof,omitempty"`
 }
 
 // KayrosEnvelope wraps data with Kayros metadata
@@ -22,6 +49,7 @@ type KayrosEnvelope struct {
 // ProveSingleHashResponseData contains the computed hash from Kayros
 type ProveSingleHashResponseData struct {
 	ComputedHashHex string                 `json:"computed_hash_hex"`
+	TimeuuidHex     string                 `json:"timeuuid_hex,omitempty"`
 	Extra           map[string]interface{} `json:"-"`
 }
 
@@ -33,7 +61,9 @@ type ProveSingleHashResponse struct {
 // GetRecordResponseData contains the record data from Kayros
 type GetRecordResponseData struct {
 	DataItemHex string                 `json:"data_item_hex"`
+	DataType    string                 `json:"data_type,omitempty"`
 	Timestamp   string                 `json:"timestamp,omitempty"`
+	UUIDHex     string                 `json:"uuid_hex,omitempty"`
 	Extra       map[string]interface{} `json:"-"`
 }
 
@@ -49,11 +79,46 @@ type VerifyResultDetails struct {
 	ComputedHash string `json:"computedHash,omitempty"`
 	EnvelopeHash string `json:"envelopeHash,omitempty"`
 	RemoteHash   string `json:"remoteHash,omitempty"`
+	// MerkleMatch is set when the embedded Merkle proof leads to MerkleRoot
+	// and MerkleRoot is anchored (see VerifyOptions.TrustedRoots)
+	MerkleMatch bool   `json:"merkleMatch,omitempty"`
+	MerkleRoot  string `json:"merkleRoot,omitempty"`
+	// MerkleNote says why MerkleMatch is false for an embedded proof that
+	// didn't fail verification, e.g. because its root is not anchored
+	MerkleNote string `json:"merkleNote,omitempty"`
+	// PolicyViolations lists every VerifyPolicy rule the envelope broke
+	PolicyViolations []PolicyViolation `json:"policyViolations,omitempty"`
 }
 
+// VerifyErrorCode identifies why verification failed
+// The values are identical across the Go, JS and Python SDKs.
+type VerifyErrorCode string
+
+const (
+	VerifyErrorMissingHash              VerifyErrorCode = "missing_hash"
+	VerifyErrorMalformedEnvelope        VerifyErrorCode = "malformed_envelope"
+	VerifyErrorSerializationFailed      VerifyErrorCode = "serialization_failed"
+	VerifyErrorUnsupportedHashAlgorithm VerifyErrorCode = "unsupported_hash_algorithm"
+	VerifyErrorHashMismatch             VerifyErrorCode = "hash_mismatch"
+	VerifyErrorMalformedTimestamp       VerifyErrorCode = "malformed_timestamp"
+	VerifyErrorRemoteUnavailable        VerifyErrorCode = "remote_unavailable"
+	VerifyErrorMalformedRemoteRecord    VerifyErrorCode = "malformed_remote_record"
+	VerifyErrorRemoteMismatch           VerifyErrorCode = "remote_mismatch"
+	VerifyErrorMissingEvidence          VerifyErrorCode = "missing_evidence"
+	VerifyErrorMerkleMismatch           VerifyErrorCode = "merkle_mismatch"
+	VerifyErrorUnanchoredProof          VerifyErrorCode = "unanchored_proof"
+	VerifyErrorPolicyViolation          VerifyErrorCode = "policy_violation"
+)
+
 // VerifyResult represents the result of a verification operation
 type VerifyResult struct {
-	Valid   bool                 `json:"valid"`
+	Valid bool `json:"valid"`
+	// Code identifies the failure when Valid is false; Error describes it
+	Code    VerifyErrorCode      `json:"code,omitempty"`
 	Error   string               `json:"error,omitempty"`
 	Details *VerifyResultDetails `json:"details,omitempty"`
+
+	// Err is the underlying error when verification failed because of one
+	// (e.g. an *APIError from the remote lookup), for use with errors.Is/As
+	Err error `json:"-"`
 }
@@ -1,37 +1,108 @@
 package provable
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
-	"time"
+	"net/http"
+	"slices"
+	"strings"
 )
 
-// Verify verifies data against a Kayros proof
+// VerifyOptions configures VerifyWithOptions
+type VerifyOptions struct {
+	// Offline verifies the timestamp against Kayros.Evidence instead of
+	// fetching the record, so no network access is needed
+	Offline bool
+
+	// TrustedRoots are Merkle roots obtained out of band. When set, the
+	// envelope must carry a Merkle proof leading to one of them. Without them
+	// an embedded proof only counts as a Merkle match online, when Kayros
+	// serves its root for the record. Offline verification requires them,
+	// since evidence that only vouches for itself proves nothing.
+	TrustedRoots []string
+
+	// Policy adds rules the envelope must satisfy, if set
+	Policy *VerifyPolicy
+
+	// fetchRecord replaces the remote record lookup, e.g. to share lookups
+	// across VerifyBatch
+	fetchRecord func(ctx context.Context, recordHash string) (*GetRecordResponse, error)
+}
+
+// Verify verifies data against a Kayros proof using DefaultClient
 func Verify(envelope *KayrosEnvelope) *VerifyResult {
+	return DefaultClient.Verify(envelope)
+}
+
+// VerifyContext is like Verify but honors ctx cancellation and deadlines
+// during the remote record lookup
+func VerifyContext(ctx context.Context, envelope *KayrosEnvelope) *VerifyResult {
+	return DefaultClient.VerifyContext(ctx, envelope)
+}
+
+// VerifyWithOptions is like VerifyContext, configured by opts, using
+// DefaultClient
+func VerifyWithOptions(ctx context.Context, envelope *KayrosEnvelope, opts VerifyOptions) *VerifyResult {
+	return DefaultClient.VerifyWithOptions(ctx, envelope, opts)
+}
+
+// Verify verifies data against a Kayros proof
+func (c *Client) Verify(envelope *KayrosEnvelope) *VerifyResult {
+	return c.VerifyContext(context.Background(), envelope)
+}
+
+// VerifyContext is like Verify but honors ctx cancellation and deadlines
+// during the remote record lookup
+func (c *Client) VerifyContext(ctx context.Context, envelope *KayrosEnvelope) *VerifyResult {
+	return c.VerifyWithOptions(ctx, envelope, VerifyOptions{})
+}
+
+// VerifyWithOptions is like VerifyContext, configured by opts
+func (c *Client) VerifyWithOptions(ctx context.Context, envelope *KayrosEnvelope, opts VerifyOptions) *VerifyResult {
 	// Validate envelope structure
 	if envelope.Kayros.Hash == "" {
 		return &VerifyResult{
 			Valid: false,
+			Code:  VerifyErrorMissingHash,
 			Error: "Missing field: envelope.kayros.hash",
 		}
 	}
+	if envelope.Kayros.Detached != nil {
+		return &VerifyResult{
+			Valid: false,
+			Code:  VerifyErrorMalformedEnvelope,
+			Error: "Detached envelope: verify its content with VerifyReader or VerifyFile",
+		}
+	}
 
-	// Compute hash of the data (stringify as JSON for struct/map data)
-	var dataString string
-	if str, ok := envelope.Data.(string); ok {
-		dataString = str
-	} else {
-		jsonData, err := json.Marshal(envelope.Data)
-		if err != nil {
-			return &VerifyResult{
-				Valid: false,
-				Error: fmt.Sprintf("Failed to marshal data: %v", err),
-			}
+	// Compute hash of the data (serialized as JSON for struct/map data, using
+	// the canonicalization recorded in the envelope)
+	dataString, err := envelopeDataString(envelope.Data, envelope.Kayros.Canonicalization)
+	if err != nil {
+		return &VerifyResult{
+			Valid: false,
+			Code:  VerifyErrorSerializationFailed,
+			Error: fmt.Sprintf("Failed to marshal data: %v", err),
+			Err:   err,
+		}
+	}
+
+	hash, err := LookupHashAlgorithm(envelope.Kayros.HashAlgorithm)
+	if err != nil {
+		return &VerifyResult{
+			Valid: false,
+			Code:  VerifyErrorUnsupportedHashAlgorithm,
+			Error: fmt.Sprintf("Unsupported hash algorithm: %q", envelope.Kayros.HashAlgorithm),
+			Err:   err,
 		}
-		dataString = string(jsonData)
 	}
 
-	computedHash := Keccak256Str(dataString)
+	return c.verifyComputedHash(ctx, envelope, hash([]byte(dataString)), opts)
+}
+
+// verifyComputedHash verifies envelope given the hash computed from its content
+func (c *Client) verifyComputedHash(ctx context.Context, envelope *KayrosEnvelope, computedHash string, opts VerifyOptions) *VerifyResult {
 	envelopeHash := envelope.Kayros.Hash
 
 	// Check if hashes match
@@ -40,6 +111,7 @@ func Verify(envelope *KayrosEnvelope) *VerifyResult {
 	if !hashMatch {
 		return &VerifyResult{
 			Valid: false,
+			Code:  VerifyErrorHashMismatch,
 			Error: "Hash mismatch: computed hash does not match envelope hash",
 			Details: &VerifyResultDetails{
 				HashMatch:    false,
@@ -51,33 +123,27 @@ func Verify(envelope *KayrosEnvelope) *VerifyResult {
 
 	// If there's a timestamp, verify against remote record
 	if envelope.Kayros.Timestamp != nil {
-		var remoteHash string
-
-		// Try to use typed response first
-		if typedResponse, ok := envelope.Kayros.Timestamp.Response.(*ProveSingleHashResponse); ok {
-			remoteHash = typedResponse.Data.ComputedHashHex
-		} else if typedResponse, ok := envelope.Kayros.Timestamp.Response.(ProveSingleHashResponse); ok {
-			remoteHash = typedResponse.Data.ComputedHashHex
-		} else {
-			// Fallback to map[string]interface{} for backward compatibility
-			timestampResponse, ok := envelope.Kayros.Timestamp.Response.(map[string]interface{})
-			if !ok {
-				return &VerifyResult{
-					Valid: false,
-					Error: "Invalid timestamp response structure",
-					Details: &VerifyResultDetails{
-						HashMatch:    true,
-						ComputedHash: computedHash,
-						EnvelopeHash: envelopeHash,
-					},
-				}
+		remoteHash, errMsg := timestampRecordHash(envelope.Kayros.Timestamp)
+		if errMsg != "" {
+			return &VerifyResult{
+				Valid: false,
+				Code:  VerifyErrorMalformedTimestamp,
+				Error: errMsg,
+				Details: &VerifyResultDetails{
+					HashMatch:    true,
+					ComputedHash: computedHash,
+					EnvelopeHash: envelopeHash,
+				},
 			}
+		}
 
-			data, ok := timestampResponse["data"].(map[string]interface{})
-			if !ok {
+		var remoteRecord *GetRecordResponse
+		if opts.Offline {
+			if envelope.Kayros.Evidence == nil || envelope.Kayros.Evidence.ChainRecord == nil {
 				return &VerifyResult{
 					Valid: false,
-					Error: "Invalid timestamp response structure: missing data",
+					Code:  VerifyErrorMissingEvidence,
+					Error: "Offline verification failed: missing evidence record",
 					Details: &VerifyResultDetails{
 						HashMatch:    true,
 						ComputedHash: computedHash,
@@ -85,12 +151,13 @@ func Verify(envelope *KayrosEnvelope) *VerifyResult {
 					},
 				}
 			}
-
-			remoteHash, ok = data["computed_hash_hex"].(string)
-			if !ok {
+			chainRecord := envelope.Kayros.Evidence.ChainRecord
+			if code, errMsg, err := verifyChainRecord(chainRecord, remoteHash); errMsg != "" {
 				return &VerifyResult{
 					Valid: false,
-					Error: "Invalid timestamp response structure: missing computed_hash_hex",
+					Code:  code,
+					Error: errMsg,
+					Err:   err,
 					Details: &VerifyResultDetails{
 						HashMatch:    true,
 						ComputedHash: computedHash,
@@ -98,21 +165,29 @@ func Verify(envelope *KayrosEnvelope) *VerifyResult {
 					},
 				}
 			}
-		}
-
-		// Fetch remote record with retry logic
-		var remoteRecord *GetRecordResponse
-		var err error
-
-		remoteRecord, err = GetRecordByHash(remoteHash)
-		if err != nil {
-			// Retry once after 2 seconds
-			time.Sleep(2 * time.Second)
-			remoteRecord, err = GetRecordByHash(remoteHash)
+			remoteRecord = &GetRecordResponse{Data: GetRecordResponseData{
+				DataItemHex: chainRecord.DataItemHex,
+				DataType:    chainRecord.DataType,
+				Timestamp:   chainRecord.Timestamp,
+				UUIDHex:     chainRecord.UUIDHex,
+			}}
+		} else {
+			fetch := opts.fetchRecord
+			if fetch == nil {
+				fetch = c.fetchVerifyRecord
+			}
+			var err error
+			remoteRecord, err = fetch(ctx, remoteHash)
 			if err != nil {
+				code := VerifyErrorRemoteUnavailable
+				if errors.Is(err, ErrDecodeResponse) {
+					code = VerifyErrorMalformedRemoteRecord
+				}
 				return &VerifyResult{
 					Valid: false,
+					Code:  code,
 					Error: fmt.Sprintf("Failed to fetch remote record: %v", err),
+					Err:   err,
 					Details: &VerifyResultDetails{
 						HashMatch:    true,
 						ComputedHash: computedHash,
@@ -123,11 +198,24 @@ func Verify(envelope *KayrosEnvelope) *VerifyResult {
 		}
 
 		remoteDataItemHex := remoteRecord.Data.DataItemHex
+		if remoteDataItemHex == "" {
+			return &VerifyResult{
+				Valid: false,
+				Code:  VerifyErrorMalformedRemoteRecord,
+				Error: "Invalid remote record structure",
+				Details: &VerifyResultDetails{
+					HashMatch:    true,
+					ComputedHash: computedHash,
+					EnvelopeHash: envelopeHash,
+				},
+			}
+		}
 		remoteMatch := computedHash == remoteDataItemHex
 
 		if !remoteMatch {
 			return &VerifyResult{
 				Valid: false,
+				Code:  VerifyErrorRemoteMismatch,
 				Error: "Remote verification failed: hash does not match remote record",
 				Details: &VerifyResultDetails{
 					HashMatch:    true,
@@ -139,20 +227,31 @@ func Verify(envelope *KayrosEnvelope) *VerifyResult {
 			}
 		}
 
-		return &VerifyResult{
-			Valid: true,
-			Details: &VerifyResultDetails{
-				HashMatch:    true,
-				RemoteMatch:  true,
-				ComputedHash: computedHash,
-				EnvelopeHash: envelopeHash,
-				RemoteHash:   remoteDataItemHex,
-			},
+		details := &VerifyResultDetails{
+			HashMatch:    true,
+			RemoteMatch:  true,
+			ComputedHash: computedHash,
+			EnvelopeHash: envelopeHash,
+			RemoteHash:   remoteDataItemHex,
+		}
+		if code, errMsg, err := c.verifyEvidenceProof(ctx, envelope.Kayros.Evidence, remoteHash, opts, details); errMsg != "" {
+			return &VerifyResult{Valid: false, Code: code, Error: errMsg, Err: err, Details: details}
+		}
+		if opts.Offline && len(opts.TrustedRoots) == 0 {
+			return &VerifyResult{
+				Valid:   false,
+				Code:    VerifyErrorUnanchoredProof,
+				Error:   "Offline verification failed: no trusted Merkle roots to anchor the evidence",
+				Details: details,
+			}
 		}
+		result := &VerifyResult{Valid: true, Details: details}
+		applyVerifyPolicy(opts.Policy, envelope, remoteRecord, result)
+		return result
 	}
 
 	// No timestamp, just verify local hash match
-	return &VerifyResult{
+	result := &VerifyResult{
 		Valid: true,
 		Details: &VerifyResultDetails{
 			HashMatch:    true,
@@ -160,4 +259,146 @@ func Verify(envelope *KayrosEnvelope) *VerifyResult {
 			EnvelopeHash: envelopeHash,
 		},
 	}
+	applyVerifyPolicy(opts.Policy, envelope, nil, result)
+	return result
+}
+
+// verifyChainRecord recomputes the hash of an evidence record and checks it
+// is recordHash, the computed_hash_hex of the timestamp
+// It returns an error code and message when it isn't.
+func verifyChainRecord(record *DatabaseRecord, recordHash string) (VerifyErrorCode, string, error) {
+	result, err := VerifyHashLocal(RecordHashRequest(*record))
+	if err != nil {
+		return VerifyErrorMalformedRemoteRecord, fmt.Sprintf("Invalid evidence record: %v", err), err
+	}
+	if !strings.EqualFold(result.ComputedHash, recordHash) {
+		return VerifyErrorRemoteMismatch, "Offline verification failed: evidence record does not hash to computed_hash_hex", nil
+	}
+	return "", "", nil
+}
+
+// verifyEvidenceProof checks the Merkle proof in evidence, if any, for the
+// record hash recordHash, filling in the Merkle fields of details
+// Online, Kayros checks the proof. Offline it is recomputed locally and must
+// reach a trusted root. It returns an error code and message when the proof
+// fails, or is missing while opts.TrustedRoots is set.
+//
+// A proof only vouches for the root it names, so MerkleMatch is set only
+// when that root is anchored: it is in opts.TrustedRoots, or online, Kayros
+// serves it as the record's root. Offline, a proof without TrustedRoots is
+// refused.
+func (c *Client) verifyEvidenceProof(ctx context.Context, evidence *KayrosEvidence, recordHash string, opts VerifyOptions, details *VerifyResultDetails) (VerifyErrorCode, string, error) {
+	if evidence == nil || evidence.MerkleProof == nil {
+		if len(opts.TrustedRoots) > 0 {
+			return VerifyErrorMissingEvidence, "Merkle verification failed: missing evidence proof", nil
+		}
+		return "", "", nil
+	}
+
+	proof := *evidence.MerkleProof
+	if proof.TargetHashHex == "" {
+		proof.TargetHashHex = recordHash
+	}
+	if !strings.EqualFold(proof.TargetHashHex, recordHash) {
+		return VerifyErrorMerkleMismatch, "Merkle verification failed: proof is for a different record", nil
+	}
+	root := merkleProofRoot(proof)
+	details.MerkleRoot = root
+	trusted := len(opts.TrustedRoots) > 0
+	if trusted && !slices.ContainsFunc(opts.TrustedRoots, func(trusted string) bool {
+		return strings.EqualFold(trusted, root)
+	}) {
+		return VerifyErrorMerkleMismatch, "Merkle verification failed: root is not trusted", nil
+	}
+
+	if opts.Offline {
+		if !trusted {
+			return VerifyErrorUnanchoredProof, "Offline verification failed: no trusted Merkle roots to anchor the evidence proof", nil
+		}
+		// The root was checked against TrustedRoots above, so a proof that
+		// recomputes to it reaches a trusted root
+		result, err := VerifyMerkleProofLocal(proof)
+		if err != nil {
+			return VerifyErrorMerkleMismatch, fmt.Sprintf("Merkle verification failed: %v", err), err
+		}
+		if !result.Valid {
+			return VerifyErrorMerkleMismatch, "Merkle verification failed: " + result.Message, nil
+		}
+		details.MerkleMatch = true
+		return "", "", nil
+	}
+
+	result, err := c.VerifyMerkleProofContext(ctx, VerifyMerkleProofRequest{
+		TargetHashHex:  proof.TargetHashHex,
+		ProofHashesHex: proof.ProofHashesHex,
+		Levels:         proof.Levels,
+		Position:       proof.Position,
+		RootHashHex:    root,
+	})
+	if err != nil {
+		return VerifyErrorRemoteUnavailable, fmt.Sprintf("Merkle verification failed: %v", err), err
+	}
+	if !result.Valid {
+		return VerifyErrorMerkleMismatch, "Merkle verification failed: Kayros rejected the proof: " + result.Message, nil
+	}
+	if trusted {
+		details.MerkleMatch = true
+		return "", "", nil
+	}
+
+	served, err := c.GenerateMerkleProofContext(ctx, GenerateMerkleProofRequest{
+		HashItem:  recordHash,
+		DataType:  proof.DataType,
+		Timestamp: proof.Timestamp,
+	})
+	switch {
+	case err != nil:
+		details.MerkleNote = fmt.Sprintf("root is not anchored: no trusted roots, and Kayros didn't serve the record's root: %v", err)
+	case !strings.EqualFold(merkleProofRoot(*served), root):
+		details.MerkleNote = fmt.Sprintf("root is not anchored: no trusted roots, and Kayros serves root %s for the record", merkleProofRoot(*served))
+	default:
+		details.MerkleMatch = true
+	}
+	return "", "", nil
+}
+
+// merkleProofRoot returns the root proof claims to lead to
+func merkleProofRoot(proof MerkleProof) string {
+	if proof.RootHashHex != "" {
+		return proof.RootHashHex
+	}
+	return proof.StoredRootHex
+}
+
+// timestampRecordHash returns the computed_hash_hex of a timestamp response,
+// or an error message when the response has an unexpected structure
+func timestampRecordHash(timestamp *KayrosTimestamp) (string, string) {
+	// Try to use typed response first
+	if typedResponse, ok := timestamp.Response.(*ProveSingleHashResponse); ok {
+		return typedResponse.Data.ComputedHashHex, ""
+	} else if typedResponse, ok := timestamp.Response.(ProveSingleHashResponse); ok {
+		return typedResponse.Data.ComputedHashHex, ""
+	}
+
+	// Fallback to map[string]interface{} for backward compatibility
+	timestampResponse, ok := timestamp.Response.(map[string]interface{})
+	if !ok {
+		return "", "Invalid timestamp response structure"
+	}
+	data, ok := timestampResponse["data"].(map[string]interface{})
+	if !ok {
+		return "", "Invalid timestamp response structure: missing data"
+	}
+	remoteHash, ok := data["computed_hash_hex"].(string)
+	if !ok {
+		return "", "Invalid timestamp response structure: missing computed_hash_hex"
+	}
+	return remoteHash, ""
+}
+
+// fetchVerifyRecord fetches the record Verify checks an envelope against
+func (c *Client) fetchVerifyRecord(ctx context.Context, recordHash string) (*GetRecordResponse, error) {
+	// A freshly proven record may not be readable yet, so 404 is retried
+	// here on top of the client's retry policy
+	return c.getRecordByHash(ctx, recordHash, c.retryPolicy.withStatus(http.StatusNotFound))
 }
@@ -0,0 +1,212 @@
+package provable
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultVerifyConcurrency is the default number of envelopes VerifyBatch
+// verifies at once
+const DefaultVerifyConcurrency = 8
+
+// BatchVerifyOptions configures VerifyBatch
+type BatchVerifyOptions struct {
+	// Concurrency bounds how many envelopes are verified at once. Zero means
+	// DefaultVerifyConcurrency.
+	Concurrency int
+
+	// Verify configures each verification
+	Verify VerifyOptions
+}
+
+// BatchVerifyResult is the outcome of one envelope passed to VerifyBatch
+type BatchVerifyResult struct {
+	// Index is the envelope's position in the slice passed to VerifyBatch
+	Index    int
+	Envelope *KayrosEnvelope
+	Result   *VerifyResult
+}
+
+// BatchVerifySummary aggregates the results of a VerifyBatch
+type BatchVerifySummary struct {
+	Total   int `json:"total"`
+	Valid   int `json:"valid"`
+	Invalid int `json:"invalid"`
+	// Skipped counts envelopes left unverified because ctx was done
+	Skipped int `json:"skipped"`
+	// ByCode counts invalid envelopes by failure code
+	ByCode map[VerifyErrorCode]int `json:"byCode"`
+	// RemoteLookups counts the distinct records fetched from Kayros
+	RemoteLookups int           `json:"remoteLookups"`
+	Duration      time.Duration `json:"duration"`
+	// Err is ctx.Err() if the batch was cut short
+	Err error `json:"-"`
+}
+
+// BatchVerification is a running VerifyBatch
+type BatchVerification struct {
+	results chan BatchVerifyResult
+	done    chan struct{}
+	summary BatchVerifySummary
+}
+
+// VerifyBatch verifies envelopes concurrently using DefaultClient
+func VerifyBatch(ctx context.Context, envelopes []*KayrosEnvelope, opts BatchVerifyOptions) *BatchVerification {
+	return DefaultClient.VerifyBatch(ctx, envelopes, opts)
+}
+
+// VerifyBatch verifies envelopes concurrently
+//
+// Results are delivered on Results as they complete, in no particular order.
+// Envelopes timestamped with the same computed hash share a single remote
+// record lookup. Once ctx is done no further envelopes are started, and those
+// left over are counted as skipped in the summary.
+func (c *Client) VerifyBatch(ctx context.Context, envelopes []*KayrosEnvelope, opts BatchVerifyOptions) *BatchVerification {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultVerifyConcurrency
+	}
+
+	b := &BatchVerification{
+		// Buffered for every envelope so Summary never waits on a reader
+		results: make(chan BatchVerifyResult, len(envelopes)),
+		done:    make(chan struct{}),
+		summary: BatchVerifySummary{
+			Total:  len(envelopes),
+			ByCode: make(map[VerifyErrorCode]int),
+		},
+	}
+
+	lookups := &recordLookups{fetch: c.fetchVerifyRecord}
+	if opts.Verify.fetchRecord != nil {
+		lookups.fetch = opts.Verify.fetchRecord
+	}
+	verifyOpts := opts.Verify
+	verifyOpts.fetchRecord = lookups.get
+
+	go func() {
+		start := time.Now()
+		indexes := make(chan int)
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		for range min(concurrency, len(envelopes)) {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range indexes {
+					result := c.verifyBatchEnvelope(ctx, envelopes[i], verifyOpts)
+					mu.Lock()
+					b.summary.add(result)
+					mu.Unlock()
+					b.results <- BatchVerifyResult{Index: i, Envelope: envelopes[i], Result: result}
+				}
+			}()
+		}
+
+	feed:
+		for i := range envelopes {
+			if ctx.Err() != nil {
+				break
+			}
+			select {
+			case indexes <- i:
+			case <-ctx.Done():
+				break feed
+			}
+		}
+		close(indexes)
+		wg.Wait()
+
+		b.summary.Skipped = b.summary.Total - b.summary.Valid - b.summary.Invalid
+		if b.summary.Skipped > 0 {
+			b.summary.Err = ctx.Err()
+		}
+		b.summary.RemoteLookups = lookups.count()
+		b.summary.Duration = time.Since(start)
+		close(b.results)
+		close(b.done)
+	}()
+	return b
+}
+
+// Results returns the channel results are delivered on
+// It is closed once every envelope has been verified or skipped.
+func (b *BatchVerification) Results() <-chan BatchVerifyResult {
+	return b.results
+}
+
+// Summary waits for the batch to finish and returns its aggregate counts
+// Results need not be drained first.
+func (b *BatchVerification) Summary() *BatchVerifySummary {
+	<-b.done
+	return &b.summary
+}
+
+func (s *BatchVerifySummary) add(result *VerifyResult) {
+	if result.Valid {
+		s.Valid++
+		return
+	}
+	s.Invalid++
+	s.ByCode[result.Code]++
+}
+
+// verifyBatchEnvelope verifies one envelope, reporting nil envelopes as
+// malformed rather than panicking the batch
+func (c *Client) verifyBatchEnvelope(ctx context.Context, envelope *KayrosEnvelope, opts VerifyOptions) *VerifyResult {
+	if envelope == nil {
+		return &VerifyResult{
+			Valid: false,
+			Code:  VerifyErrorMalformedEnvelope,
+			Error: "Missing envelope",
+		}
+	}
+	return c.VerifyWithOptions(ctx, envelope, opts)
+}
+
+// recordLookups fetches each record once, sharing the outcome with every
+// concurrent and later caller asking for the same hash
+type recordLookups struct {
+	fetch func(ctx context.Context, recordHash string) (*GetRecordResponse, error)
+
+	mu    sync.Mutex
+	calls map[string]*recordLookup
+}
+
+type recordLookup struct {
+	done   chan struct{}
+	record *GetRecordResponse
+	err    error
+}
+
+func (l *recordLookups) get(ctx context.Context, recordHash string) (*GetRecordResponse, error) {
+	l.mu.Lock()
+	if l.calls == nil {
+		l.calls = make(map[string]*recordLookup)
+	}
+	call, ok := l.calls[recordHash]
+	if !ok {
+		call = &recordLookup{done: make(chan struct{})}
+		l.calls[recordHash] = call
+	}
+	l.mu.Unlock()
+
+	if !ok {
+		call.record, call.err = l.fetch(ctx, recordHash)
+		close(call.done)
+		return call.record, call.err
+	}
+	select {
+	case <-call.done:
+		return call.record, call.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (l *recordLookups) count() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.calls)
+}
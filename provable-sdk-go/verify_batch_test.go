@@ -0,0 +1,108 @@
+package provable
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// sealedTestEnvelopes seals copies envelopes for each of n distinct values
+func sealedTestEnvelopes(t *testing.T, c *Client, n, copies int) []*KayrosEnvelope {
+	t.Helper()
+	var envelopes []*KayrosEnvelope
+	for i := range n {
+		for range copies {
+			envelope, err := c.Seal(fmt.Sprintf("batch %d", i))
+			if err != nil {
+				t.Fatalf("Seal() error = %v", err)
+			}
+			envelopes = append(envelopes, envelope)
+		}
+	}
+	return envelopes
+}
+
+func TestVerifyBatch(t *testing.T) {
+	ctx := context.Background()
+	c, requests := newEvidenceTestClient(t)
+
+	t.Run("share lookups for identical hashes", func(t *testing.T) {
+		envelopes := sealedTestEnvelopes(t, c, 3, 10)
+		envelopes[4].Data = "tampered"
+		before := requests.Load()
+
+		batch := c.VerifyBatch(ctx, envelopes, BatchVerifyOptions{Concurrency: 4})
+		seen := make(map[int]bool)
+		for r := range batch.Results() {
+			if r.Envelope != envelopes[r.Index] {
+				t.Errorf("result %d has the wrong envelope", r.Index)
+			}
+			if want := r.Index != 4; r.Result.Valid != want {
+				t.Errorf("result %d Valid = %v, want %v", r.Index, r.Result.Valid, want)
+			}
+			seen[r.Index] = true
+		}
+		if len(seen) != len(envelopes) {
+			t.Errorf("results = %d, want %d", len(seen), len(envelopes))
+		}
+
+		summary := batch.Summary()
+		if summary.Total != 30 || summary.Valid != 29 || summary.Invalid != 1 || summary.ByCode[VerifyErrorHashMismatch] != 1 {
+			t.Errorf("Summary() = %+v, want 29 valid and 1 hash mismatch", summary)
+		}
+		if summary.RemoteLookups != 3 {
+			t.Errorf("RemoteLookups = %d, want 3", summary.RemoteLookups)
+		}
+		if n := requests.Load() - before; n != 3 {
+			t.Errorf("requests = %d, want 3", n)
+		}
+	})
+
+	t.Run("bound concurrency", func(t *testing.T) {
+		envelopes := sealedTestEnvelopes(t, c, 20, 1)
+		var inFlight, peak atomic.Int32
+		opts := BatchVerifyOptions{Concurrency: 3}
+		opts.Verify.fetchRecord = func(ctx context.Context, recordHash string) (*GetRecordResponse, error) {
+			n := inFlight.Add(1)
+			defer inFlight.Add(-1)
+			for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+			}
+			time.Sleep(5 * time.Millisecond)
+			return c.fetchVerifyRecord(ctx, recordHash)
+		}
+
+		if summary := c.VerifyBatch(ctx, envelopes, opts).Summary(); summary.Valid != 20 {
+			t.Errorf("Summary() = %+v, want 20 valid", summary)
+		}
+		if p := peak.Load(); p > 3 {
+			t.Errorf("peak concurrency = %d, want at most 3", p)
+		}
+	})
+
+	t.Run("skip envelopes once ctx is done", func(t *testing.T) {
+		envelopes := sealedTestEnvelopes(t, c, 5, 1)
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+
+		summary := c.VerifyBatch(cancelled, envelopes, BatchVerifyOptions{}).Summary()
+		if summary.Skipped != 5 || !errors.Is(summary.Err, context.Canceled) {
+			t.Errorf("Summary() = %+v, want 5 skipped with context.Canceled", summary)
+		}
+	})
+
+	t.Run("report nil envelopes as malformed", func(t *testing.T) {
+		summary := c.VerifyBatch(ctx, []*KayrosEnvelope{nil}, BatchVerifyOptions{}).Summary()
+		if summary.ByCode[VerifyErrorMalformedEnvelope] != 1 || summary.Err != nil {
+			t.Errorf("Summary() = %+v, want 1 malformed envelope", summary)
+		}
+	})
+
+	t.Run("finish an empty batch", func(t *testing.T) {
+		if summary := c.VerifyBatch(ctx, nil, BatchVerifyOptions{}).Summary(); summary.Total != 0 {
+			t.Errorf("Summary() = %+v, want empty", summary)
+		}
+	})
+}
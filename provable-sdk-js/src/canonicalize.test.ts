@@ -0,0 +1,66 @@
+/**
+ * Tests for canonicalize module
+ */
+
+import { describe, it, expect } from 'vitest';
+import { canonicalize, envelope_data_string, CANONICALIZATION_JCS } from './canonicalize';
+import { keccak256_str } from './hash';
+
+// Shared with the Go and Python SDK tests
+const CROSS_SDK_INPUT = '{"b":[1,2.5,"x",1e21,-0],"a":{"\\u00e9":true,"z":null,"10":"<&>"}}';
+const CROSS_SDK_OUTPUT = '{"a":{"10":"<&>","z":null,"é":true},"b":[1,2.5,"x",1e+21,0]}';
+const CROSS_SDK_HASH = '8df12ae4acff018b3cbf25e2a5bccce7394cc41739c4108486971bccb8dc68b3';
+
+describe('canonicalize', () => {
+  it('should match the RFC 8785 example', () => {
+    const input = '{"numbers":[333333333.33333329,1E30,4.50,2e-3,0.000000000000000000000000001],"string":"\\u20ac$\\u000F\\u000aA\'\\u0042\\u0022\\u005c\\\\\\"\\/","literals":[null,true,false]}';
+    const expected = '{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"€$\\u000f\\nA\'B\\"\\\\\\\\\\"/"}';
+    expect(canonicalize(JSON.parse(input))).toBe(expected);
+  });
+
+  it('should sort keys by UTF-16 code units', () => {
+    const input = '{"\\u20ac":0,"\\r":0,"\\ufb33":0,"1":0,"\\ud83d\\ude00":0,"\\u0080":0,"\\u00f6":0}';
+    const expected = '{"\\r":0,"1":0,"\u0080":0,"ö":0,"€":0,"😀":0,"\ufb33":0}';
+    expect(canonicalize(JSON.parse(input))).toBe(expected);
+  });
+
+  it('should format numbers as ECMAScript', () => {
+    expect(canonicalize(-0)).toBe('0');
+    expect(canonicalize(1e20)).toBe('100000000000000000000');
+    expect(canonicalize(1e21)).toBe('1e+21');
+    expect(canonicalize(1e-7)).toBe('1e-7');
+  });
+
+  it('should ignore insertion order', () => {
+    expect(canonicalize({ b: 1, a: 2 })).toBe(canonicalize({ a: 2, b: 1 }));
+  });
+
+  it('should match the cross-SDK vector', () => {
+    const result = canonicalize(JSON.parse(CROSS_SDK_INPUT));
+    expect(result).toBe(CROSS_SDK_OUTPUT);
+    expect(keccak256_str(result)).toBe(CROSS_SDK_HASH);
+  });
+
+  it('should reject non-finite numbers', () => {
+    expect(() => canonicalize(Infinity)).toThrow();
+    expect(() => canonicalize({ a: NaN })).toThrow();
+  });
+});
+
+describe('envelope_data_string', () => {
+  it('should hash strings as-is', () => {
+    expect(envelope_data_string('hello', CANONICALIZATION_JCS)).toBe('hello');
+  });
+
+  it('should use JSON.stringify without canonicalization', () => {
+    expect(envelope_data_string({ b: 1, a: 2 })).toBe('{"b":1,"a":2}');
+  });
+
+  it('should use JCS when requested', () => {
+    expect(envelope_data_string({ b: 1, a: 2 }, CANONICALIZATION_JCS)).toBe('{"a":2,"b":1}');
+  });
+
+  it('should reject unknown canonicalization', () => {
+    expect(() => envelope_data_string({}, 'c14n')).toThrow('Unsupported canonicalization');
+  });
+});
@@ -0,0 +1,90 @@
+/**
+ * RFC 8785 JSON Canonicalization Scheme (JCS)
+ */
+
+/**
+ * Canonicalization modes recorded in KayrosMetadata.canonicalization
+ * Envelopes without the field hash JSON.stringify output.
+ */
+export const CANONICALIZATION_JCS = 'jcs';
+
+/**
+ * Serialize a value as RFC 8785 canonical JSON
+ * Object keys are sorted by UTF-16 code units, numbers use ECMAScript
+ * formatting and strings use the minimal JSON escaping, so the output is
+ * identical to the Go and Python SDKs.
+ * @param value - JSON-compatible value
+ * @returns Canonical JSON string
+ */
+export function canonicalize(value: unknown): string {
+  const out = serialize(value);
+  if (out === undefined) {
+    throw new TypeError('Cannot canonicalize undefined');
+  }
+  return out;
+}
+
+/**
+ * Get the string that is hashed for envelope data: strings are hashed as-is,
+ * anything else is serialized as JSON according to the canonicalization mode
+ * @param data - Envelope data
+ * @param canonicalization - Canonicalization mode from KayrosMetadata
+ * @returns String to hash
+ */
+export function envelope_data_string(data: unknown, canonicalization?: string): string {
+  if (typeof data === 'string') {
+    return data;
+  }
+  if (!canonicalization) {
+    return JSON.stringify(data);
+  }
+  if (canonicalization === CANONICALIZATION_JCS) {
+    return canonicalize(data);
+  }
+  throw new Error(`Unsupported canonicalization: ${canonicalization}`);
+}
+
+function serialize(value: unknown): string | undefined {
+  if (value !== null && typeof value === 'object' && typeof (value as any).toJSON === 'function') {
+    value = (value as any).toJSON();
+  }
+
+  if (value === null) {
+    return 'null';
+  }
+
+  switch (typeof value) {
+    case 'boolean':
+      return value ? 'true' : 'false';
+    case 'number':
+      if (!Number.isFinite(value)) {
+        throw new TypeError(`Cannot canonicalize non-finite number ${value}`);
+      }
+      // Number -> string conversion is the ECMAScript formatting JCS requires
+      return JSON.stringify(value);
+    case 'string':
+      // JSON.stringify escapes exactly the characters JCS requires
+      return JSON.stringify(value);
+    case 'bigint':
+      throw new TypeError('Cannot canonicalize bigint');
+    case 'undefined':
+    case 'function':
+    case 'symbol':
+      return undefined;
+  }
+
+  if (Array.isArray(value)) {
+    return '[' + value.map(elem => serialize(elem) ?? 'null').join(',') + ']';
+  }
+
+  const obj = value as Record<string, unknown>;
+  const members: string[] = [];
+  // Default sort compares UTF-16 code units
+  for (const key of Object.keys(obj).sort()) {
+    const member = serialize(obj[key]);
+    if (member !== undefined) {
+      members.push(JSON.stringify(key) + ':' + member);
+    }
+  }
+  return '{' + members.join(',') + '}';
+}
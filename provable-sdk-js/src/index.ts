@@ -13,6 +13,10 @@ export { prove_data, prove_data_str } from './prove';
 
 // Export verify function
 export { verify } from './verify';
+export { VerifyErrorCode } from './types';
+
+// Export canonicalization
+export { canonicalize, envelope_data_string, CANONICALIZATION_JCS } from './canonicalize';
 
 // Export Lightnet API functions
 export {
@@ -5,6 +5,7 @@
 export interface KayrosMetadata {
   hash?: string;
   hashAlgorithm?: 'keccak256';
+  canonicalization?: 'jcs'; // how non-string data was serialized; absent means JSON.stringify
   timestamp?: {
     service: string;
     response: unknown;
@@ -31,8 +32,31 @@ export interface GetRecordResponse {
   };
 }
 
+/**
+ * Why verification failed; the values are identical across the Go, JS and
+ * Python SDKs
+ */
+export const VerifyErrorCode = {
+  MissingHash: 'missing_hash',
+  MalformedEnvelope: 'malformed_envelope',
+  SerializationFailed: 'serialization_failed',
+  UnsupportedHashAlgorithm: 'unsupported_hash_algorithm',
+  HashMismatch: 'hash_mismatch',
+  MalformedTimestamp: 'malformed_timestamp',
+  RemoteUnavailable: 'remote_unavailable',
+  MalformedRemoteRecord: 'malformed_remote_record',
+  RemoteMismatch: 'remote_mismatch',
+  MissingEvidence: 'missing_evidence',
+  MerkleMismatch: 'merkle_mismatch',
+  UnanchoredProof: 'unanchored_proof',
+  PolicyViolation: 'policy_violation',
+} as const;
+
+export type VerifyErrorCode = (typeof VerifyErrorCode)[keyof typeof VerifyErrorCode];
+
 export interface VerifyResult {
   valid: boolean;
+  code?: VerifyErrorCode; // set when valid is false; error describes it
   error?: string;
   details?: {
     hashMatch?: boolean;
@@ -0,0 +1,90 @@
+/**
+ * Tests for verify module
+ */
+
+import { describe, it, expect, beforeEach, afterEach, vi } from 'vitest';
+import { verify } from './verify';
+import { keccak256_str } from './hash';
+import { VerifyErrorCode } from './types';
+
+// Mock fetch globally
+global.fetch = vi.fn();
+
+const data = 'hello';
+const hash = keccak256_str(data);
+
+function timestamped(response: unknown) {
+  return { data, kayros: { hash, timestamp: { service: 'test', response } } };
+}
+
+function mockRecord(record: unknown) {
+  (global.fetch as any).mockResolvedValue({ ok: true, json: async () => record });
+}
+
+describe('verify', () => {
+  beforeEach(() => {
+    vi.resetAllMocks();
+  });
+
+  afterEach(() => {
+    vi.useRealTimers();
+  });
+
+  it('should use the same error codes as the Go and Python SDKs', () => {
+    expect(Object.values(VerifyErrorCode)).toEqual([
+      'missing_hash',
+      'malformed_envelope',
+      'serialization_failed',
+      'unsupported_hash_algorithm',
+      'hash_mismatch',
+      'malformed_timestamp',
+      'remote_unavailable',
+      'malformed_remote_record',
+      'remote_mismatch',
+      'missing_evidence',
+      'merkle_mismatch',
+      'unanchored_proof',
+      'policy_violation',
+    ]);
+  });
+
+  it('should report malformed envelopes', async () => {
+    expect((await verify({ data } as any)).code).toBe(VerifyErrorCode.MalformedEnvelope);
+    expect((await verify({ data, kayros: {} })).code).toBe(VerifyErrorCode.MissingHash);
+  });
+
+  it('should report hash mismatches', async () => {
+    const result = await verify({ data: 'changed', kayros: { hash } });
+    expect(result.valid).toBe(false);
+    expect(result.code).toBe(VerifyErrorCode.HashMismatch);
+  });
+
+  it('should report malformed timestamps', async () => {
+    const result = await verify(timestamped({ data: {} }));
+    expect(result.code).toBe(VerifyErrorCode.MalformedTimestamp);
+  });
+
+  it('should report remote record problems', async () => {
+    const envelope = timestamped({ data: { computed_hash_hex: 'abc' } });
+
+    mockRecord({ data: {} });
+    expect((await verify(envelope)).code).toBe(VerifyErrorCode.MalformedRemoteRecord);
+
+    mockRecord({ data: { data_item_hex: keccak256_str('other') } });
+    expect((await verify(envelope)).code).toBe(VerifyErrorCode.RemoteMismatch);
+
+    mockRecord({ data: { data_item_hex: hash } });
+    const result = await verify(envelope);
+    expect(result.valid).toBe(true);
+    expect(result.code).toBeUndefined();
+  });
+
+  it('should report an unavailable remote', async () => {
+    vi.useFakeTimers();
+    (global.fetch as any).mockResolvedValue({ ok: false, status: 503, statusText: 'Service Unavailable' });
+
+    const pending = verify(timestamped({ data: { computed_hash_hex: 'abc' } }));
+    await vi.advanceTimersByTimeAsync(2000);
+    expect((await pending).code).toBe(VerifyErrorCode.RemoteUnavailable);
+  });
+});
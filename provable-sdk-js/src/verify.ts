@@ -4,6 +4,8 @@
 
 import { keccak256_str } from './hash';
 import { get_record_by_hash } from './api';
+import { envelope_data_string } from './canonicalize';
+import { VerifyErrorCode } from './types';
 import type { KayrosEnvelope, VerifyResult } from './types';
 
 /**
@@ -17,6 +19,7 @@ export async function verify<T = unknown>(envelope: KayrosEnvelope<T>): Promise<
     if (!envelope.kayros) {
       return {
         valid: false,
+        code: VerifyErrorCode.MalformedEnvelope,
         error: 'Missing field: envelope.kayros',
       };
     }
@@ -24,14 +27,14 @@ export async function verify<T = unknown>(envelope: KayrosEnvelope<T>): Promise<
     if (!envelope.kayros.hash) {
       return {
         valid: false,
+        code: VerifyErrorCode.MissingHash,
         error: 'Missing field: envelope.kayros.hash',
       };
     }
 
-    // Compute hash of the data (stringify as JSON for object data)
-    const dataString = typeof envelope.data === 'string'
-      ? envelope.data
-      : JSON.stringify(envelope.data);
+    // Compute hash of the data (serialized as JSON for object data, using
+    // the canonicalization recorded in the envelope)
+    const dataString = envelope_data_string(envelope.data, envelope.kayros.canonicalization);
     const computedHash = keccak256_str(dataString);
     const envelopeHash = envelope.kayros.hash;
 
@@ -41,6 +44,7 @@ export async function verify<T = unknown>(envelope: KayrosEnvelope<T>): Promise<
     if (!hashMatch) {
       return {
         valid: false,
+        code: VerifyErrorCode.HashMismatch,
         error: 'Hash mismatch: computed hash does not match envelope hash',
         details: {
           hashMatch: false,
@@ -57,6 +61,7 @@ export async function verify<T = unknown>(envelope: KayrosEnvelope<T>): Promise<
       if (!timestampResponse.data || !timestampResponse.data.computed_hash_hex) {
         return {
           valid: false,
+          code: VerifyErrorCode.MalformedTimestamp,
           error: 'Invalid timestamp response structure',
           details: {
             hashMatch: true,
@@ -82,6 +87,7 @@ export async function verify<T = unknown>(envelope: KayrosEnvelope<T>): Promise<
         if (!remoteRecord.data || !remoteRecord.data.data_item_hex) {
           return {
             valid: false,
+            code: VerifyErrorCode.MalformedRemoteRecord,
             error: 'Invalid remote record structure',
             details: {
               hashMatch: true,
@@ -97,6 +103,7 @@ export async function verify<T = unknown>(envelope: KayrosEnvelope<T>): Promise<
         if (!remoteMatch) {
           return {
             valid: false,
+            code: VerifyErrorCode.RemoteMismatch,
             error: 'Remote verification failed: hash does not match remote record',
             details: {
               hashMatch: true,
@@ -121,6 +128,7 @@ export async function verify<T = unknown>(envelope: KayrosEnvelope<T>): Promise<
       } catch (error) {
         return {
           valid: false,
+          code: VerifyErrorCode.RemoteUnavailable,
           error: `Failed to fetch remote record: ${error instanceof Error ? error.message : String(error)}`,
           details: {
             hashMatch: true,
@@ -143,6 +151,7 @@ export async function verify<T = unknown>(envelope: KayrosEnvelope<T>): Promise<
   } catch (error) {
     return {
       valid: false,
+      code: VerifyErrorCode.SerializationFailed,
       error: `Verification error: ${error instanceof Error ? error.message : String(error)}`,
     };
   }
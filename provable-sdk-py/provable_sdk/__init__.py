@@ -6,6 +6,7 @@ from .hash import hash, keccak256, hash_str, keccak256_str, sha256, sha256_str
 from .api import prove_single_hash, get_record_by_hash
 from .prove import prove_data, prove_data_str
 from .verify import verify
+from .canonicalize import canonicalize, envelope_data_string, CANONICALIZATION_JCS
 from .lightnet import (
     query_hashes,
     get_database_stats,
@@ -27,6 +28,7 @@ from .types import (
     ProveSingleHashResponse,
     GetRecordResponse,
     VerifyResult,
+    VerifyErrorCode,
     # Database types
     DatabaseQuery,
     HashRecord,
@@ -65,6 +67,9 @@ __all__ = [
     "prove_data",
     "prove_data_str",
     "verify",
+    "canonicalize",
+    "envelope_data_string",
+    "CANONICALIZATION_JCS",
     # Lightnet functions
     "query_hashes",
     "get_database_stats",
@@ -85,6 +90,7 @@ __all__ = [
     "ProveSingleHashResponse",
     "GetRecordResponse",
     "VerifyResult",
+    "VerifyErrorCode",
     "DatabaseQuery",
     "HashRecord",
     "DatabaseStats",
@@ -0,0 +1,142 @@
+"""
+RFC 8785 JSON Canonicalization Scheme (JCS)
+"""
+
+import json
+import math
+from typing import Any, Optional
+
+# Canonicalization mode recorded in KayrosMetadata["canonicalization"]
+# Envelopes without the field hash compact json.dumps output.
+CANONICALIZATION_JCS = "jcs"
+
+_ESCAPES = {
+    '"': '\\"',
+    "\\": "\\\\",
+    "\b": "\\b",
+    "\f": "\\f",
+    "\n": "\\n",
+    "\r": "\\r",
+    "\t": "\\t",
+}
+
+
+def canonicalize(value: Any) -> str:
+    """
+    Serialize a value as RFC 8785 canonical JSON
+
+    Object keys are sorted by UTF-16 code units, numbers use ECMAScript
+    formatting and strings use the minimal JSON escaping, so the output is
+    identical to the Go and JS SDKs.
+
+    Args:
+        value: JSON-compatible value (dict, list, str, int, float, bool, None)
+
+    Returns:
+        Canonical JSON string
+    """
+    parts = []
+    _serialize(value, parts)
+    return "".join(parts)
+
+
+def envelope_data_string(data: Any, canonicalization: Optional[str] = None) -> str:
+    """
+    Get the string that is hashed for envelope data
+
+    Strings are hashed as-is, anything else is serialized as JSON according to
+    the canonicalization mode.
+
+    Args:
+        data: Envelope data
+        canonicalization: Canonicalization mode from KayrosMetadata
+
+    Returns:
+        String to hash
+    """
+    if isinstance(data, str):
+        return data
+    if not canonicalization:
+        return json.dumps(data, separators=(',', ':'))
+    if canonicalization == CANONICALIZATION_JCS:
+        return canonicalize(data)
+    raise ValueError(f"Unsupported canonicalization: {canonicalization}")
+
+
+def _serialize(value: Any, parts: list) -> None:
+    if value is None:
+        parts.append("null")
+    elif value is True:
+        parts.append("true")
+    elif value is False:
+        parts.append("false")
+    elif isinstance(value, (int, float)):
+        parts.append(_format_number(float(value)))
+    elif isinstance(value, str):
+        parts.append(_format_string(value))
+    elif isinstance(value, (list, tuple)):
+        parts.append("[")
+        for i, elem in enumerate(value):
+            if i > 0:
+                parts.append(",")
+            _serialize(elem, parts)
+        parts.append("]")
+    elif isinstance(value, dict):
+        for key in value:
+            if not isinstance(key, str):
+                raise TypeError(f"Object keys must be strings, got {type(key).__name__}")
+        parts.append("{")
+        for i, key in enumerate(sorted(value, key=lambda k: k.encode("utf-16-be", "surrogatepass"))):
+            if i > 0:
+                parts.append(",")
+            parts.append(_format_string(key))
+            parts.append(":")
+            _serialize(value[key], parts)
+        parts.append("}")
+    else:
+        raise TypeError(f"Cannot canonicalize value of type {type(value).__name__}")
+
+
+def _format_number(f: float) -> str:
+    """Format a float as ECMAScript's Number.prototype.toString would"""
+    if math.isnan(f) or math.isinf(f):
+        raise ValueError(f"Cannot canonicalize non-finite number {f}")
+    if f == 0:
+        return "0"
+
+    sign = "-" if f < 0 else ""
+    # repr gives the shortest round-tripping digits; split them into the
+    # digit string and decimal exponent n (value = 0.digits * 10**n)
+    mantissa, _, exp = repr(abs(f)).partition("e")
+    int_part, _, frac_part = mantissa.partition(".")
+    digits = (int_part + frac_part).lstrip("0")
+    n = len(int_part) + (int(exp) if exp else 0)
+    if int_part == "0":
+        n -= len(frac_part) - len(frac_part.lstrip("0")) + 1
+    digits = digits.rstrip("0")
+    k = len(digits)
+
+    if k <= n <= 21:
+        return sign + digits + "0" * (n - k)
+    if 0 < n <= 21:
+        return sign + digits[:n] + "." + digits[n:]
+    if -6 < n <= 0:
+        return sign + "0." + "0" * -n + digits
+    e = n - 1
+    exponent = ("+" if e >= 0 else "-") + str(abs(e))
+    if k == 1:
+        return sign + digits + "e" + exponent
+    return sign + digits[0] + "." + digits[1:] + "e" + exponent
+
+
+def _format_string(s: str) -> str:
+    out = ['"']
+    for ch in s:
+        if ch in _ESCAPES:
+            out.append(_ESCAPES[ch])
+        elif ch < " ":
+            out.append(f"\\u{ord(ch):04x}")
+        else:
+            out.append(ch)
+    out.append('"')
+    return "".join(out)
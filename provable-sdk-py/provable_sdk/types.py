@@ -2,6 +2,7 @@
 Provable SDK Types
 """
 
+from enum import Enum
 from typing import TypedDict, Optional, Any, Dict
 
 
@@ -13,6 +14,7 @@ class KayrosTimestamp(TypedDict, total=False):
 class KayrosMetadata(TypedDict, total=False):
     hash: str
     hashAlgorithm: str
+    canonicalization: str  # "jcs"; absent means compact json.dumps
     timestamp: KayrosTimestamp
 
 
@@ -46,8 +48,27 @@ class VerifyResultDetails(TypedDict, total=False):
     remoteHash: str
 
 
+class VerifyErrorCode(str, Enum):
+    """Why verification failed; the values are identical across the Go, JS and Python SDKs"""
+
+    MISSING_HASH = "missing_hash"
+    MALFORMED_ENVELOPE = "malformed_envelope"
+    SERIALIZATION_FAILED = "serialization_failed"
+    UNSUPPORTED_HASH_ALGORITHM = "unsupported_hash_algorithm"
+    HASH_MISMATCH = "hash_mismatch"
+    MALFORMED_TIMESTAMP = "malformed_timestamp"
+    REMOTE_UNAVAILABLE = "remote_unavailable"
+    MALFORMED_REMOTE_RECORD = "malformed_remote_record"
+    REMOTE_MISMATCH = "remote_mismatch"
+    MISSING_EVIDENCE = "missing_evidence"
+    MERKLE_MISMATCH = "merkle_mismatch"
+    UNANCHORED_PROOF = "unanchored_proof"
+    POLICY_VIOLATION = "policy_violation"
+
+
 class VerifyResult(TypedDict, total=False):
     valid: bool
+    code: VerifyErrorCode  # set when valid is False; error describes it
     error: Optional[str]
     details: VerifyResultDetails
 
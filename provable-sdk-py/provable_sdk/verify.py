@@ -2,13 +2,13 @@
 Verification utilities
 """
 
-import json
 import time
 from typing import Any, Dict
 
 from .hash import keccak256_str
 from .api import get_record_by_hash
-from .types import KayrosEnvelope, VerifyResult
+from .canonicalize import envelope_data_string
+from .types import KayrosEnvelope, VerifyErrorCode, VerifyResult
 
 
 def verify(envelope: KayrosEnvelope) -> VerifyResult:
@@ -26,6 +26,7 @@ def verify(envelope: KayrosEnvelope) -> VerifyResult:
         if "kayros" not in envelope:
             return {
                 "valid": False,
+                "code": VerifyErrorCode.MALFORMED_ENVELOPE,
                 "error": "Missing field: envelope.kayros",
             }
 
@@ -34,15 +35,13 @@ def verify(envelope: KayrosEnvelope) -> VerifyResult:
         if "hash" not in kayros:
             return {
                 "valid": False,
+                "code": VerifyErrorCode.MISSING_HASH,
                 "error": "Missing field: envelope.kayros.hash",
             }
 
-        # Compute hash of the data (stringify as JSON for dict data)
-        data = envelope["data"]
-        if isinstance(data, str):
-            data_string = data
-        else:
-            data_string = json.dumps(data, separators=(',', ':'))
+        # Compute hash of the data (serialized as JSON for dict data, using
+        # the canonicalization recorded in the envelope)
+        data_string = envelope_data_string(envelope["data"], kayros.get("canonicalization"))
 
         computed_hash = keccak256_str(data_string)
         envelope_hash = kayros["hash"]
@@ -53,6 +52,7 @@ def verify(envelope: KayrosEnvelope) -> VerifyResult:
         if not hash_match:
             return {
                 "valid": False,
+                "code": VerifyErrorCode.HASH_MISMATCH,
                 "error": "Hash mismatch: computed hash does not match envelope hash",
                 "details": {
                     "hashMatch": False,
@@ -68,6 +68,7 @@ def verify(envelope: KayrosEnvelope) -> VerifyResult:
             if "response" not in timestamp:
                 return {
                     "valid": False,
+                    "code": VerifyErrorCode.MALFORMED_TIMESTAMP,
                     "error": "Invalid timestamp response structure",
                     "details": {
                         "hashMatch": True,
@@ -83,6 +84,7 @@ def verify(envelope: KayrosEnvelope) -> VerifyResult:
                 "computed_hash_hex" not in timestamp_response["data"]):
                 return {
                     "valid": False,
+                    "code": VerifyErrorCode.MALFORMED_TIMESTAMP,
                     "error": "Invalid timestamp response structure",
                     "details": {
                         "hashMatch": True,
@@ -107,6 +109,7 @@ def verify(envelope: KayrosEnvelope) -> VerifyResult:
                     "data_item_hex" not in remote_record["data"]):
                     return {
                         "valid": False,
+                        "code": VerifyErrorCode.MALFORMED_REMOTE_RECORD,
                         "error": "Invalid remote record structure",
                         "details": {
                             "hashMatch": True,
@@ -121,6 +124,7 @@ def verify(envelope: KayrosEnvelope) -> VerifyResult:
                 if not remote_match:
                     return {
                         "valid": False,
+                        "code": VerifyErrorCode.REMOTE_MISMATCH,
                         "error": "Remote verification failed: hash does not match remote record",
                         "details": {
                             "hashMatch": True,
@@ -144,6 +148,7 @@ def verify(envelope: KayrosEnvelope) -> VerifyResult:
             except Exception as e:
                 return {
                     "valid": False,
+                    "code": VerifyErrorCode.REMOTE_UNAVAILABLE,
                     "error": f"Failed to fetch remote record: {str(e)}",
                     "details": {
                         "hashMatch": True,
@@ -164,5 +169,6 @@ def verify(envelope: KayrosEnvelope) -> VerifyResult:
     except Exception as e:
         return {
             "valid": False,
+            "code": VerifyErrorCode.SERIALIZATION_FAILED,
             "error": f"Verification error: {str(e)}",
         }
@@ -0,0 +1,74 @@
+"""
+Tests for canonicalize module
+"""
+
+import json
+import pytest
+from provable_sdk.canonicalize import (
+    canonicalize,
+    envelope_data_string,
+    CANONICALIZATION_JCS,
+)
+from provable_sdk.hash import keccak256_str
+
+# Shared with the Go and JS SDK tests
+CROSS_SDK_INPUT = r'{"b":[1,2.5,"x",1e21,-0],"a":{"\u00e9":true,"z":null,"10":"<&>"}}'
+CROSS_SDK_OUTPUT = '{"a":{"10":"<&>","z":null,"é":true},"b":[1,2.5,"x",1e+21,0]}'
+CROSS_SDK_HASH = "8df12ae4acff018b3cbf25e2a5bccce7394cc41739c4108486971bccb8dc68b3"
+
+
+class TestCanonicalize:
+    def test_match_rfc_8785_example(self):
+        data = r'''{"numbers":[333333333.33333329,1E30,4.50,2e-3,0.000000000000000000000000001],"string":"\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/","literals":[null,true,false]}'''
+        expected = r'''{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"€$\u000f\nA'B\"\\\\\"/"}'''
+        assert canonicalize(json.loads(data)) == expected
+
+    def test_sort_keys_by_utf16_code_units(self):
+        data = r'{"\u20ac":0,"\r":0,"\ufb33":0,"1":0,"\ud83d\ude00":0,"\u0080":0,"\u00f6":0}'
+        expected = '{"\\r":0,"1":0,"\u0080":0,"ö":0,"€":0,"😀":0,"\ufb33":0}'
+        assert canonicalize(json.loads(data)) == expected
+
+    @pytest.mark.parametrize("value,expected", [
+        (0, "0"),
+        (-0.0, "0"),
+        (1.0, "1"),
+        (-1.5, "-1.5"),
+        (1e20, "100000000000000000000"),
+        (1e21, "1e+21"),
+        (1e-6, "0.000001"),
+        (1e-7, "1e-7"),
+        (5e-324, "5e-324"),
+        (9007199254740993, "9007199254740992"),
+    ])
+    def test_format_numbers_as_ecmascript(self, value, expected):
+        assert canonicalize(value) == expected
+
+    def test_match_cross_sdk_vector(self):
+        result = canonicalize(json.loads(CROSS_SDK_INPUT))
+        assert result == CROSS_SDK_OUTPUT
+        assert keccak256_str(result) == CROSS_SDK_HASH
+
+    def test_reject_non_finite_numbers(self):
+        with pytest.raises(ValueError):
+            canonicalize(float("inf"))
+        with pytest.raises(ValueError):
+            canonicalize({"a": float("nan")})
+
+    def test_reject_non_string_keys(self):
+        with pytest.raises(TypeError):
+            canonicalize({1: "a"})
+
+
+class TestEnvelopeDataString:
+    def test_hash_strings_as_is(self):
+        assert envelope_data_string("hello", CANONICALIZATION_JCS) == "hello"
+
+    def test_use_compact_json_without_canonicalization(self):
+        assert envelope_data_string({"b": 1, "a": 2}) == '{"b":1,"a":2}'
+
+    def test_use_jcs_when_requested(self):
+        assert envelope_data_string({"b": 1, "a": 2}, CANONICALIZATION_JCS) == '{"a":2,"b":1}'
+
+    def test_reject_unknown_canonicalization(self):
+        with pytest.raises(ValueError, match="Unsupported canonicalization"):
+            envelope_data_string({}, "c14n")
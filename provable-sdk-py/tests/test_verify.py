@@ -0,0 +1,72 @@
+"""
+Tests for verify module
+"""
+
+import json
+from unittest.mock import patch
+from provable_sdk.verify import verify
+from provable_sdk.hash import keccak256_str
+from provable_sdk.types import VerifyErrorCode
+
+DATA = "hello"
+HASH = keccak256_str(DATA)
+
+
+def timestamped(response):
+    return {"data": DATA, "kayros": {"hash": HASH, "timestamp": {"service": "test", "response": response}}}
+
+
+class TestVerifyErrorCode:
+    def test_match_go_and_js_sdks(self):
+        assert [code.value for code in VerifyErrorCode] == [
+            "missing_hash",
+            "malformed_envelope",
+            "serialization_failed",
+            "unsupported_hash_algorithm",
+            "hash_mismatch",
+            "malformed_timestamp",
+            "remote_unavailable",
+            "malformed_remote_record",
+            "remote_mismatch",
+            "missing_evidence",
+            "merkle_mismatch",
+            "unanchored_proof",
+            "policy_violation",
+        ]
+
+    def test_serialize_as_plain_string(self):
+        assert json.dumps({"code": VerifyErrorCode.HASH_MISMATCH}) == '{"code": "hash_mismatch"}'
+
+    def test_report_malformed_envelopes(self):
+        assert verify({"data": DATA})["code"] == VerifyErrorCode.MALFORMED_ENVELOPE
+        assert verify({"data": DATA, "kayros": {}})["code"] == VerifyErrorCode.MISSING_HASH
+
+    def test_report_hash_mismatch(self):
+        result = verify({"data": "changed", "kayros": {"hash": HASH}})
+        assert result["valid"] is False
+        assert result["code"] == "hash_mismatch"
+
+    def test_report_malformed_timestamp(self):
+        assert verify(timestamped({"data": {}}))["code"] == VerifyErrorCode.MALFORMED_TIMESTAMP
+
+    @patch("provable_sdk.verify.get_record_by_hash")
+    def test_report_remote_record_problems(self, mock_get):
+        envelope = timestamped({"data": {"computed_hash_hex": "abc"}})
+
+        mock_get.return_value = {"data": {}}
+        assert verify(envelope)["code"] == VerifyErrorCode.MALFORMED_REMOTE_RECORD
+
+        mock_get.return_value = {"data": {"data_item_hex": keccak256_str("other")}}
+        assert verify(envelope)["code"] == VerifyErrorCode.REMOTE_MISMATCH
+
+        mock_get.return_value = {"data": {"data_item_hex": HASH}}
+        result = verify(envelope)
+        assert result["valid"] is True
+        assert "code" not in result
+
+    @patch("provable_sdk.verify.time.sleep")
+    @patch("provable_sdk.verify.get_record_by_hash")
+    def test_report_unavailable_remote(self, mock_get, mock_sleep):
+        mock_get.side_effect = ConnectionError("down")
+        result = verify(timestamped({"data": {"computed_hash_hex": "abc"}}))
+        assert result["code"] == VerifyErrorCode.REMOTE_UNAVAILABLE